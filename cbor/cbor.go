@@ -0,0 +1,393 @@
+// Package cbor provides deterministic CBOR codecs for Lamport keys,
+// signatures, and threshold shares, for MPC nodes written in languages with
+// native CBOR support.
+//
+// Encodings follow the core deterministic encoding rules of RFC 8949 §4.2.1:
+// definite lengths, shortest-form integer and length heads, and map keys in
+// ascending order. Every object is a map with small unsigned integer keys
+// (1, 2, ...) and every field is always present, so an object has exactly one
+// valid encoding. Decoders are strict: they reject non-canonical input as
+// well as malformed input, so decode followed by encode returns the same
+// bytes and signatures over serialized objects agree across implementations.
+//
+// Fixed-size arrays use the same flat layout as the primitives Bytes() methods.
+package cbor
+
+import (
+	"errors"
+	"math"
+	"unicode/utf8"
+
+	"github.com/luxfi/lamport/primitives"
+	"github.com/luxfi/lamport/threshold"
+)
+
+// CBOR major types used by this package
+const (
+	majorUint   = 0
+	majorNegInt = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorMap    = 5
+)
+
+var (
+	// ErrMalformed indicates input that is not well-formed CBOR
+	ErrMalformed = errors.New("cbor: malformed input")
+
+	// ErrNonCanonical indicates well-formed CBOR that is not the deterministic encoding
+	ErrNonCanonical = errors.New("cbor: non-canonical encoding")
+
+	// ErrUnexpectedType indicates a field encoded with the wrong major type
+	ErrUnexpectedType = errors.New("cbor: unexpected type")
+
+	// ErrInvalidLength indicates a byte string field has the wrong size for its type
+	ErrInvalidLength = errors.New("cbor: invalid field length")
+
+	// ErrIndexRange indicates an index outside the int32 range decoders accept
+	ErrIndexRange = errors.New("cbor: index out of int32 range")
+)
+
+// MarshalPublicKey encodes pk as {1: hashes}.
+func MarshalPublicKey(pk *primitives.PublicKey) []byte {
+	b := appendHead(nil, majorMap, 1)
+	b = appendHead(b, majorUint, 1)
+	return appendBytes(b, pk.Bytes())
+}
+
+// UnmarshalPublicKey decodes the output of MarshalPublicKey.
+func UnmarshalPublicKey(data []byte) (*primitives.PublicKey, error) {
+	d := &decoder{b: data}
+	if err := d.mapHeader(1); err != nil {
+		return nil, err
+	}
+	hashes, err := d.bytesField(1, primitives.PublicKeySize)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.end(); err != nil {
+		return nil, err
+	}
+	pk := &primitives.PublicKey{}
+	_ = pk.FromBytes(hashes)
+	return pk, nil
+}
+
+// MarshalSignature encodes sig as {1: preimages}.
+func MarshalSignature(sig *primitives.Signature) []byte {
+	b := appendHead(nil, majorMap, 1)
+	b = appendHead(b, majorUint, 1)
+	return appendBytes(b, sig.Bytes())
+}
+
+// UnmarshalSignature decodes the output of MarshalSignature.
+func UnmarshalSignature(data []byte) (*primitives.Signature, error) {
+	d := &decoder{b: data}
+	if err := d.mapHeader(1); err != nil {
+		return nil, err
+	}
+	preimages, err := d.bytesField(1, primitives.SignatureSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.end(); err != nil {
+		return nil, err
+	}
+	sig := &primitives.Signature{}
+	_ = sig.FromBytes(preimages)
+	return sig, nil
+}
+
+// MarshalShare encodes s as {1: party_id, 2: index, 3: preimage_shares}.
+// An index outside the int32 range returns ErrIndexRange, as decoding
+// would reject it.
+func MarshalShare(s *threshold.Share) ([]byte, error) {
+	if err := checkIndex(s.Index); err != nil {
+		return nil, err
+	}
+	b := appendHead(nil, majorMap, 3)
+	b = appendHead(b, majorUint, 1)
+	b = appendText(b, s.PartyID)
+	b = appendHead(b, majorUint, 2)
+	b = appendInt(b, int64(s.Index))
+	b = appendHead(b, majorUint, 3)
+	return appendBytes(b, s.Bytes()), nil
+}
+
+// UnmarshalShare decodes the output of MarshalShare.
+func UnmarshalShare(data []byte) (*threshold.Share, error) {
+	d := &decoder{b: data}
+	if err := d.mapHeader(3); err != nil {
+		return nil, err
+	}
+	partyID, err := d.textField(1)
+	if err != nil {
+		return nil, err
+	}
+	index, err := d.intField(2)
+	if err != nil {
+		return nil, err
+	}
+	preimages, err := d.bytesField(3, primitives.PrivateKeySize)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.end(); err != nil {
+		return nil, err
+	}
+	s := &threshold.Share{PartyID: partyID, Index: index}
+	_ = s.FromBytes(preimages)
+	return s, nil
+}
+
+// MarshalPartialSignature encodes p as
+// {1: party_id, 2: index, 3: preimage_partials, 4: bit_mask}.
+// An index outside the int32 range returns ErrIndexRange.
+func MarshalPartialSignature(p *threshold.PartialSignature) ([]byte, error) {
+	if err := checkIndex(p.Index); err != nil {
+		return nil, err
+	}
+	partials := make([]byte, primitives.SignatureSize)
+	for i := 0; i < primitives.KeyBits; i++ {
+		copy(partials[i*32:(i+1)*32], p.PreimagePartials[i][:])
+	}
+
+	b := appendHead(nil, majorMap, 4)
+	b = appendHead(b, majorUint, 1)
+	b = appendText(b, p.PartyID)
+	b = appendHead(b, majorUint, 2)
+	b = appendInt(b, int64(p.Index))
+	b = appendHead(b, majorUint, 3)
+	b = appendBytes(b, partials)
+	b = appendHead(b, majorUint, 4)
+	return appendBytes(b, p.BitMask[:]), nil
+}
+
+// UnmarshalPartialSignature decodes the output of MarshalPartialSignature.
+func UnmarshalPartialSignature(data []byte) (*threshold.PartialSignature, error) {
+	d := &decoder{b: data}
+	if err := d.mapHeader(4); err != nil {
+		return nil, err
+	}
+	partyID, err := d.textField(1)
+	if err != nil {
+		return nil, err
+	}
+	index, err := d.intField(2)
+	if err != nil {
+		return nil, err
+	}
+	partials, err := d.bytesField(3, primitives.SignatureSize)
+	if err != nil {
+		return nil, err
+	}
+	mask, err := d.bytesField(4, 32)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.end(); err != nil {
+		return nil, err
+	}
+
+	p := &threshold.PartialSignature{PartyID: partyID, Index: index}
+	for i := 0; i < primitives.KeyBits; i++ {
+		copy(p.PreimagePartials[i][:], partials[i*32:(i+1)*32])
+	}
+	copy(p.BitMask[:], mask)
+	return p, nil
+}
+
+// checkIndex returns ErrIndexRange unless index fits the int32 range
+// intField decodes.
+func checkIndex(index int) error {
+	if index < math.MinInt32 || index > math.MaxInt32 {
+		return ErrIndexRange
+	}
+	return nil
+}
+
+// appendHead appends a CBOR initial byte and argument in shortest form.
+func appendHead(b []byte, major byte, n uint64) []byte {
+	m := major << 5
+	switch {
+	case n < 24:
+		return append(b, m|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, m|24, byte(n))
+	case n <= math.MaxUint16:
+		return append(b, m|25, byte(n>>8), byte(n))
+	case n <= math.MaxUint32:
+		return append(b, m|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		return append(b, m|27, byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32),
+			byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+func appendBytes(b []byte, v []byte) []byte {
+	b = appendHead(b, majorBytes, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendText(b []byte, v string) []byte {
+	b = appendHead(b, majorText, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendInt(b []byte, v int64) []byte {
+	if v < 0 {
+		return appendHead(b, majorNegInt, uint64(-1-v))
+	}
+	return appendHead(b, majorUint, uint64(v))
+}
+
+// decoder reads the subset of canonical CBOR produced by this package.
+type decoder struct {
+	b []byte
+}
+
+// head reads an initial byte and its argument, rejecting indefinite lengths,
+// reserved additional info, and arguments not in shortest form.
+func (d *decoder) head() (byte, uint64, error) {
+	if len(d.b) == 0 {
+		return 0, 0, ErrMalformed
+	}
+	major, info := d.b[0]>>5, d.b[0]&0x1f
+	d.b = d.b[1:]
+
+	var size int
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, ErrMalformed // reserved or indefinite length
+	}
+	if len(d.b) < size {
+		return 0, 0, ErrMalformed
+	}
+	var n uint64
+	for _, c := range d.b[:size] {
+		n = n<<8 | uint64(c)
+	}
+	d.b = d.b[size:]
+
+	if len(appendHead(nil, major, n)) != 1+size {
+		return 0, 0, ErrNonCanonical
+	}
+	return major, n, nil
+}
+
+// mapHeader reads a map header that must hold exactly n entries.
+func (d *decoder) mapHeader(n uint64) error {
+	major, count, err := d.head()
+	if err != nil {
+		return err
+	}
+	if major != majorMap {
+		return ErrUnexpectedType
+	}
+	if count != n {
+		return ErrMalformed
+	}
+	return nil
+}
+
+// key reads a map key that must equal want; keys arrive in ascending order.
+func (d *decoder) key(want uint64) error {
+	major, k, err := d.head()
+	if err != nil {
+		return err
+	}
+	if major != majorUint {
+		return ErrUnexpectedType
+	}
+	if k != want {
+		return ErrNonCanonical
+	}
+	return nil
+}
+
+// string reads a byte or text string of the given major type.
+func (d *decoder) string(want byte) ([]byte, error) {
+	major, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	if major != want {
+		return nil, ErrUnexpectedType
+	}
+	if uint64(len(d.b)) < n {
+		return nil, ErrMalformed
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v, nil
+}
+
+func (d *decoder) bytesField(key uint64, size int) ([]byte, error) {
+	if err := d.key(key); err != nil {
+		return nil, err
+	}
+	v, err := d.string(majorBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(v) != size {
+		return nil, ErrInvalidLength
+	}
+	return v, nil
+}
+
+func (d *decoder) textField(key uint64) (string, error) {
+	if err := d.key(key); err != nil {
+		return "", err
+	}
+	v, err := d.string(majorText)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(v) {
+		return "", ErrMalformed
+	}
+	return string(v), nil
+}
+
+func (d *decoder) intField(key uint64) (int, error) {
+	if err := d.key(key); err != nil {
+		return 0, err
+	}
+	major, n, err := d.head()
+	if err != nil {
+		return 0, err
+	}
+	switch major {
+	case majorUint:
+		if n > math.MaxInt32 {
+			return 0, ErrMalformed
+		}
+		return int(n), nil
+	case majorNegInt:
+		if n > math.MaxInt32 {
+			return 0, ErrMalformed
+		}
+		return -1 - int(n), nil
+	default:
+		return 0, ErrUnexpectedType
+	}
+}
+
+// end rejects trailing bytes after the top-level map.
+func (d *decoder) end() error {
+	if len(d.b) != 0 {
+		return ErrMalformed
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/luxfi/lamport/internal/codectest"
+	"github.com/luxfi/lamport/primitives"
+	"github.com/luxfi/lamport/threshold"
+)
+
+func fixtureObjects() (*primitives.PublicKey, *primitives.Signature, *threshold.Share, *threshold.PartialSignature) {
+	o := codectest.NewObjects()
+	return o.PublicKey, o.Signature, o.Share, o.Partial
+}
+
+func TestRoundTrip(t *testing.T) {
+	must := mustMarshal(t)
+	pk, sig, share, partial := fixtureObjects()
+
+	pk2, err := UnmarshalPublicKey(MarshalPublicKey(pk))
+	if err != nil {
+		t.Fatalf("UnmarshalPublicKey failed: %v", err)
+	}
+	if *pk2 != *pk {
+		t.Error("PublicKey round trip mismatch")
+	}
+
+	sig2, err := UnmarshalSignature(MarshalSignature(sig))
+	if err != nil {
+		t.Fatalf("UnmarshalSignature failed: %v", err)
+	}
+	if *sig2 != *sig {
+		t.Error("Signature round trip mismatch")
+	}
+
+	share2, err := UnmarshalShare(must(MarshalShare(share)))
+	if err != nil {
+		t.Fatalf("UnmarshalShare failed: %v", err)
+	}
+	if *share2 != *share {
+		t.Error("Share round trip mismatch")
+	}
+
+	partial2, err := UnmarshalPartialSignature(must(MarshalPartialSignature(partial)))
+	if err != nil {
+		t.Fatalf("UnmarshalPartialSignature failed: %v", err)
+	}
+	if *partial2 != *partial {
+		t.Error("PartialSignature round trip mismatch")
+	}
+
+	// Negative indices use major type 1
+	share.Index = -5
+	share3, err := UnmarshalShare(must(MarshalShare(share)))
+	if err != nil || share3.Index != -5 {
+		t.Errorf("Negative index round trip failed: %v, %v", share3, err)
+	}
+}
+
+// mustMarshal returns a function that unwraps a Marshal result, failing t
+// on error.
+func mustMarshal(t *testing.T) func([]byte, error) []byte {
+	return func(b []byte, err error) []byte {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		return b
+	}
+}
+
+func TestMarshalIndexRange(t *testing.T) {
+	must := mustMarshal(t)
+	_, _, share, partial := fixtureObjects()
+
+	// The int32 bounds round-trip; one past them is rejected on encode
+	for _, index := range []int64{math.MaxInt32, math.MinInt32} {
+		share.Index = int(index)
+		got, err := UnmarshalShare(must(MarshalShare(share)))
+		if err != nil || got.Index != share.Index {
+			t.Errorf("Index %d round trip failed: %v", index, err)
+		}
+	}
+	if strconv.IntSize == 32 {
+		t.Skip("int cannot exceed the int32 range")
+	}
+	for _, index := range []int64{math.MaxInt32 + 1, math.MinInt32 - 1} {
+		share.Index = int(index)
+		if _, err := MarshalShare(share); err != ErrIndexRange {
+			t.Errorf("MarshalShare(index %d): expected ErrIndexRange, got %v", index, err)
+		}
+		partial.Index = int(index)
+		if _, err := MarshalPartialSignature(partial); err != ErrIndexRange {
+			t.Errorf("MarshalPartialSignature(index %d): expected ErrIndexRange, got %v", index, err)
+		}
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	must := mustMarshal(t)
+	_, _, share, _ := fixtureObjects()
+	valid := must(MarshalShare(share))
+
+	cases := map[string]struct {
+		data []byte
+		err  error
+	}{
+		"empty":          {nil, ErrMalformed},
+		"truncated":      {valid[:len(valid)-1], ErrMalformed},
+		"trailing bytes": {append(append([]byte(nil), valid...), 0x00), ErrMalformed},
+		"not a map":      {[]byte{0x40}, ErrUnexpectedType},
+		"wrong size map": {[]byte{0xa2, 0x01, 0x60, 0x02, 0x00}, ErrMalformed},
+		"indefinite map": {[]byte{0xbf, 0x01, 0x60, 0xff}, ErrMalformed},
+		// key 1 encoded with a one-byte argument instead of inline
+		"non-shortest key": {append([]byte{0xa3, 0x18, 0x01}, valid[2:]...), ErrNonCanonical},
+		// keys out of order: starts with key 2
+		"key order":         {append([]byte{0xa3, 0x02}, valid[2:]...), ErrNonCanonical},
+		"invalid utf8":      {[]byte{0xa3, 0x01, 0x61, 0xff}, ErrMalformed},
+		"party id as bytes": {append([]byte{0xa3, 0x01, 0x47}, valid[3:]...), ErrUnexpectedType},
+	}
+	for name, c := range cases {
+		if _, err := UnmarshalShare(c.data); err != c.err {
+			t.Errorf("%s: expected %v, got %v", name, c.err, err)
+		}
+	}
+
+	// Well-formed map with a short byte string
+	short := appendHead(nil, majorMap, 1)
+	short = appendHead(short, majorUint, 1)
+	short = appendBytes(short, make([]byte, 10))
+	if _, err := UnmarshalPublicKey(short); err != ErrInvalidLength {
+		t.Errorf("Expected ErrInvalidLength, got %v", err)
+	}
+}
+
+// TestFixture checks encodings against testdata/vectors.json, which other
+// language implementations use to validate their codecs.
+func TestFixture(t *testing.T) {
+	must := mustMarshal(t)
+	pk, sig, share, partial := fixtureObjects()
+	got := map[string]string{
+		"public_key":        hex.EncodeToString(MarshalPublicKey(pk)),
+		"signature":         hex.EncodeToString(MarshalSignature(sig)),
+		"share":             hex.EncodeToString(must(MarshalShare(share))),
+		"partial_signature": hex.EncodeToString(must(MarshalPartialSignature(partial))),
+	}
+
+	codectest.CheckVectors(t, got)
+}
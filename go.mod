@@ -2,6 +2,9 @@ module github.com/luxfi/lamport
 
 go 1.23.0
 
-require golang.org/x/crypto v0.31.0
+require (
+	golang.org/x/crypto v0.31.0
+	google.golang.org/protobuf v1.36.5
+)
 
 require golang.org/x/sys v0.28.0 // indirect
@@ -0,0 +1,190 @@
+// Package hors provides HORS (Hash to Obtain Random Subset) few-time signatures.
+//
+// A HORS key holds t secret values. To sign, the 32-byte message is split into
+// k indices of log2(t) bits each, and the secrets at those indices are
+// revealed. Verification hashes each revealed value and compares it to the
+// public key entry at the same index.
+//
+// Compared with a chain of Lamport keys, one HORS key can sign a small number
+// of messages with a much smaller signature (k values instead of 256).
+//
+// SECURITY: HORS degrades with every signature. After r signatures at most
+// r*k of the t secrets are public, and an attacker can forge a new message
+// whose k indices all fall in the revealed set with probability about
+// (r*k/t)^k, i.e. roughly k*(log2(t) - log2(r*k)) bits of security. Use
+// SecurityBits to plan how many messages a key may sign, and rotate the key
+// well before that drops below your target. Signatures over messages the
+// attacker can choose adaptively are weaker still.
+//
+// See: Reyzin & Reyzin, "Better than BiBa: Short One-time Signatures with
+// Fast Signing and Verifying" (2002)
+package hors
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"math/bits"
+
+	"github.com/luxfi/lamport/primitives"
+)
+
+const (
+	// MinT is the smallest supported number of secrets
+	MinT = 2
+
+	// MaxT is the largest supported number of secrets (16-bit indices)
+	MaxT = 1 << 16
+)
+
+// ErrInvalidParams indicates t is not a power of two in [MinT, MaxT] or k is out of range
+var ErrInvalidParams = errors.New("hors: invalid parameters (t must be a power of two in [2, 65536], 1 <= k <= t)")
+
+// PrivateKey holds the t secret values of a HORS key.
+type PrivateKey struct {
+	// T is the number of secrets, K the number revealed per signature
+	T, K int
+
+	// Secrets holds t 32-byte secret values
+	Secrets [][primitives.PreimageSize]byte
+
+	// SignCount tracks how many messages this key has signed
+	SignCount int
+}
+
+// PublicKey holds the keccak256 hash of each secret value.
+type PublicKey struct {
+	T, K int
+
+	// Hashes holds t 32-byte hashes, Hashes[i] = keccak256(Secrets[i])
+	Hashes [][primitives.HashSize]byte
+}
+
+// Signature reveals k secret values selected by the message.
+type Signature struct {
+	// Values[j] is the secret at index Indices(message, t, k)[j]
+	Values [][primitives.PreimageSize]byte
+}
+
+// KeyPair holds a HORS key pair for convenience.
+type KeyPair struct {
+	Private *PrivateKey
+	Public  *PublicKey
+}
+
+// GenerateKeyPair generates a HORS key pair with t secrets revealing k per
+// signature, using crypto/rand.
+func GenerateKeyPair(t, k int) (*KeyPair, error) {
+	return GenerateKeyPairFromReader(t, k, rand.Reader)
+}
+
+// GenerateKeyPairFromReader generates a HORS key pair from the given random source.
+func GenerateKeyPairFromReader(t, k int, random io.Reader) (*KeyPair, error) {
+	if !validParams(t, k) {
+		return nil, ErrInvalidParams
+	}
+	primitives.LockHashFunc()
+
+	priv := &PrivateKey{T: t, K: k, Secrets: make([][primitives.PreimageSize]byte, t)}
+	pub := &PublicKey{T: t, K: k, Hashes: make([][primitives.HashSize]byte, t)}
+
+	for i := 0; i < t; i++ {
+		if _, err := io.ReadFull(random, priv.Secrets[i][:]); err != nil {
+			return nil, err
+		}
+		pub.Hashes[i] = primitives.Keccak256(priv.Secrets[i][:])
+	}
+
+	return &KeyPair{Private: priv, Public: pub}, nil
+}
+
+// Sign reveals the k secrets selected by the message and increments SignCount.
+func Sign(priv *PrivateKey, message [32]byte) (*Signature, error) {
+	if !validParams(priv.T, priv.K) || len(priv.Secrets) != priv.T {
+		return nil, ErrInvalidParams
+	}
+
+	indices := Indices(message, priv.T, priv.K)
+	sig := &Signature{Values: make([][primitives.PreimageSize]byte, len(indices))}
+	for j, idx := range indices {
+		sig.Values[j] = priv.Secrets[idx]
+	}
+
+	priv.SignCount++
+	return sig, nil
+}
+
+// Verify checks a HORS signature against a public key and message.
+func Verify(pub *PublicKey, message [32]byte, sig *Signature) bool {
+	if !validParams(pub.T, pub.K) || len(pub.Hashes) != pub.T || len(sig.Values) != pub.K {
+		return false
+	}
+
+	for j, idx := range Indices(message, pub.T, pub.K) {
+		if primitives.Keccak256(sig.Values[j][:]) != pub.Hashes[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+// Indices returns the k secret indices selected by message for a key with t
+// secrets. Each index is log2(t) bits read MSB-first from the bit stream
+//
+//	message || keccak256(message || uint32(1)) || keccak256(message || uint32(2)) || ...
+//
+// so for k*log2(t) <= 256 the indices come straight from the message bits.
+// Indices may repeat; a repeated index reveals the same secret twice.
+func Indices(message [32]byte, t, k int) []int {
+	if !validParams(t, k) {
+		return nil
+	}
+
+	tau := bits.TrailingZeros(uint(t))
+	indices := make([]int, k)
+
+	block := message
+	counter := uint32(0)
+	pos := 0
+	for j := 0; j < k; j++ {
+		idx := 0
+		for b := 0; b < tau; b++ {
+			if pos == primitives.KeyBits {
+				counter++
+				var ctr [4]byte
+				binary.BigEndian.PutUint32(ctr[:], counter)
+				block = primitives.Keccak256Multi(message[:], ctr[:])
+				pos = 0
+			}
+			idx = idx<<1 | primitives.GetBit(block, pos)
+			pos++
+		}
+		indices[j] = idx
+	}
+	return indices
+}
+
+// SecurityBits estimates the remaining forgery resistance, in bits, of a key
+// with t secrets and k revealed per signature after r signatures:
+//
+//	k * (log2(t) - log2(r*k))
+//
+// For r = 0 this is k*log2(t). The result is clamped to [0, 256], the
+// 256-bit hash security, so it never grows with r. A result of 0 means
+// forgery is expected to be easy.
+func SecurityBits(t, k, r int) float64 {
+	if !validParams(t, k) || r < 0 {
+		return 0
+	}
+	bits := float64(k) * math.Log2(float64(t))
+	if r > 0 {
+		bits -= float64(k) * math.Log2(float64(r)*float64(k))
+	}
+	return math.Max(0, math.Min(bits, 256))
+}
+
+func validParams(t, k int) bool {
+	return t >= MinT && t <= MaxT && t&(t-1) == 0 && k >= 1 && k <= t
+}
@@ -0,0 +1,114 @@
+package hors
+
+import (
+	"testing"
+
+	"github.com/luxfi/lamport/primitives"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	kp, err := GenerateKeyPair(1024, 16)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+
+	message := primitives.Keccak256([]byte("HORS test"))
+	sig, err := Sign(kp.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if len(sig.Values) != 16 {
+		t.Errorf("Expected 16 revealed values, got %d", len(sig.Values))
+	}
+	if !Verify(kp.Public, message, sig) {
+		t.Error("Valid signature failed verification")
+	}
+
+	// Few-time: the same key signs a second message
+	message2 := primitives.Keccak256([]byte("HORS test 2"))
+	sig2, _ := Sign(kp.Private, message2)
+	if !Verify(kp.Public, message2, sig2) {
+		t.Error("Second signature failed verification")
+	}
+	if kp.Private.SignCount != 2 {
+		t.Errorf("Expected SignCount 2, got %d", kp.Private.SignCount)
+	}
+
+	// Wrong message and tampered value fail
+	if Verify(kp.Public, message2, sig) {
+		t.Error("Signature for different message should fail verification")
+	}
+	sig.Values[0][0] ^= 0xFF
+	if Verify(kp.Public, message, sig) {
+		t.Error("Modified signature should fail verification")
+	}
+}
+
+func TestIndicesDeterministic(t *testing.T) {
+	message := primitives.Keccak256([]byte("subset"))
+
+	a := Indices(message, 1<<16, 32) // 512 bits: spills into a second block
+	b := Indices(message, 1<<16, 32)
+	if len(a) != 32 {
+		t.Fatalf("Expected 32 indices, got %d", len(a))
+	}
+	for j := range a {
+		if a[j] != b[j] {
+			t.Fatalf("Index %d differs between calls", j)
+		}
+		if a[j] < 0 || a[j] >= 1<<16 {
+			t.Fatalf("Index %d out of range: %d", j, a[j])
+		}
+	}
+
+	// The first indices are read straight from the message bits
+	if want := int(message[0])<<8 | int(message[1]); a[0] != want {
+		t.Errorf("Expected first index %d, got %d", want, a[0])
+	}
+
+	other := Indices(primitives.Keccak256([]byte("other")), 1<<16, 32)
+	same := true
+	for j := range a {
+		if a[j] != other[j] {
+			same = false
+		}
+	}
+	if same {
+		t.Error("Different messages should select different subsets")
+	}
+}
+
+func TestInvalidParams(t *testing.T) {
+	for _, p := range [][2]int{{1, 1}, {1000, 4}, {1 << 17, 4}, {256, 0}, {4, 8}} {
+		if _, err := GenerateKeyPair(p[0], p[1]); err != ErrInvalidParams {
+			t.Errorf("GenerateKeyPair(%d, %d): expected ErrInvalidParams, got %v", p[0], p[1], err)
+		}
+	}
+}
+
+func TestSecurityBits(t *testing.T) {
+	fresh := SecurityBits(1024, 16, 0)
+	one := SecurityBits(1024, 16, 1)
+	ten := SecurityBits(1024, 16, 10)
+	if !(fresh > one && one > ten) {
+		t.Errorf("Security should decrease with signatures: %v, %v, %v", fresh, one, ten)
+	}
+	if SecurityBits(1024, 16, 64) != 0 {
+		t.Error("Revealing every secret should leave no security")
+	}
+
+	// Parameters above the hash cap must still never gain security with use
+	for _, p := range []struct{ t, k int }{{1 << 16, 32}, {1 << 16, 64}, {1024, 16}, {16, 1}} {
+		prev := SecurityBits(p.t, p.k, 0)
+		for r := 0; r <= 4096; r++ {
+			got := SecurityBits(p.t, p.k, r)
+			if got < 0 || got > 256 {
+				t.Errorf("SecurityBits(%d, %d, %d) = %v, outside [0, 256]", p.t, p.k, r, got)
+			}
+			if got > prev {
+				t.Errorf("SecurityBits(%d, %d, %d) = %v, above %v at r-1", p.t, p.k, r, got, prev)
+			}
+			prev = got
+		}
+	}
+}
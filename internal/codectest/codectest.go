@@ -0,0 +1,96 @@
+// Package codectest holds the fixture inputs and testdata/vectors.json
+// handling shared by the wire codec tests (proto, cbor). The inputs are
+// deterministic so other language implementations can rebuild them and
+// check their encodings against the same vectors.
+package codectest
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luxfi/lamport/primitives"
+	"github.com/luxfi/lamport/threshold"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/vectors.json")
+
+// Pattern fills b deterministically so other languages can rebuild the
+// fixture inputs: b[j] = byte(j*31 + seed).
+func Pattern(b []byte, seed byte) {
+	for j := range b {
+		b[j] = byte(j*31) + seed
+	}
+}
+
+// Objects are the values every codec encodes for its fixture.
+type Objects struct {
+	PublicKey  *primitives.PublicKey
+	Signature  *primitives.Signature
+	Share      *threshold.Share
+	Partial    *threshold.PartialSignature
+	Commitment threshold.DigestCommitment
+}
+
+// NewObjects builds the fixture values from Pattern with seeds 1 to 6.
+func NewObjects() Objects {
+	buf := make([]byte, primitives.PublicKeySize)
+
+	pk := &primitives.PublicKey{}
+	Pattern(buf, 1)
+	_ = pk.FromBytes(buf)
+
+	sig := &primitives.Signature{}
+	Pattern(buf[:primitives.SignatureSize], 2)
+	_ = sig.FromBytes(buf[:primitives.SignatureSize])
+
+	share := &threshold.Share{PartyID: "party-1", Index: 1}
+	Pattern(buf, 3)
+	_ = share.FromBytes(buf)
+
+	partial := &threshold.PartialSignature{PartyID: "party-2", Index: 2}
+	Pattern(buf[:primitives.SignatureSize], 4)
+	for i := 0; i < primitives.KeyBits; i++ {
+		copy(partial.PreimagePartials[i][:], buf[i*32:(i+1)*32])
+	}
+	Pattern(partial.BitMask[:], 5)
+
+	dc := threshold.DigestCommitment{PartyID: "party-3"}
+	Pattern(dc.Commitment[:], 6)
+
+	return Objects{PublicKey: pk, Signature: sig, Share: share, Partial: partial, Commitment: dc}
+}
+
+// CheckVectors compares got, a map from object name to hex encoding,
+// against testdata/vectors.json in the calling package's directory. With
+// -update it rewrites the file first.
+func CheckVectors(t *testing.T, got map[string]string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "vectors.json")
+	if *update {
+		data, err := json.MarshalIndent(got, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	var want map[string]string
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatalf("parsing fixture: %v", err)
+	}
+	for name, enc := range got {
+		if enc != want[name] {
+			t.Errorf("%s encoding does not match fixture", name)
+		}
+	}
+}
@@ -6,50 +6,106 @@
 //   lamport verify <pub> <sig> <msg>   Verify a signature
 //   lamport chain <n>                  Generate a key chain of n keys
 //   lamport benchmark                  Run performance benchmarks
+//   lamport vectors [count]            Emit JSON test vectors
 package main
 
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"time"
 
+	"github.com/luxfi/lamport/precompile"
 	"github.com/luxfi/lamport/primitives"
+	"github.com/luxfi/lamport/testvectors"
 	"github.com/luxfi/lamport/threshold"
 )
 
+// Output streams, replaceable in tests
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// Exit codes returned by run
+const (
+	exitOK      = 0
+	exitRuntime = 1
+	exitUsage   = 2
+)
+
+// usageError marks an error caused by invalid command-line arguments.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// usagef returns a formatted *usageError.
+func usagef(format string, args ...any) error {
+	return &usageError{err: fmt.Errorf(format, args...)}
+}
+
+// commands maps each subcommand to its implementation. Commands write
+// normal output to w and return errors to run rather than exiting.
+var commands = map[string]func(w io.Writer, args []string) error{
+	"keygen":    cmdKeygen,
+	"sign":      cmdSign,
+	"verify":    cmdVerify,
+	"chain":     cmdChain,
+	"benchmark": cmdBenchmark,
+	"threshold": cmdThreshold,
+	"vectors":   runVectors,
+	"help": func(w io.Writer, _ []string) error {
+		printUsage(w)
+		return nil
+	},
+}
+
 func main() {
-	if len(os.Args) < 2 {
-		printUsage()
-		os.Exit(1)
+	os.Exit(run(os.Args[1:]))
+}
+
+// run executes the command named by args[0] and returns the process exit
+// code: exitUsage for invalid arguments, exitRuntime for other failures.
+// Errors are printed to stderr.
+func run(args []string) int {
+	if len(args) < 1 {
+		printUsage(stderr)
+		return exitUsage
 	}
 
-	switch os.Args[1] {
-	case "keygen":
-		cmdKeygen()
-	case "sign":
-		cmdSign()
-	case "verify":
-		cmdVerify()
-	case "chain":
-		cmdChain()
-	case "benchmark":
-		cmdBenchmark()
-	case "threshold":
-		cmdThreshold()
-	case "help":
-		printUsage()
-	default:
-		fmt.Printf("Unknown command: %s\n", os.Args[1])
-		printUsage()
-		os.Exit(1)
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "Unknown command: %s\n\n", args[0])
+		printUsage(stderr)
+		return exitUsage
 	}
+
+	if err := cmd(stdout, args[1:]); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		var usage *usageError
+		if errors.As(err, &usage) {
+			fmt.Fprintln(stderr, "Run 'lamport help' for usage.")
+			return exitUsage
+		}
+		return exitRuntime
+	}
+	return exitOK
 }
 
-func printUsage() {
-	fmt.Println(`Lamport OTS - Post-Quantum One-Time Signatures
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, `Lamport OTS - Post-Quantum One-Time Signatures
 
 Usage:
   lamport <command> [arguments]
@@ -59,249 +115,336 @@ Commands:
   sign                Sign a message (requires private key)
   verify              Verify a signature
   chain <n>           Generate a key chain of n keys
-  threshold <t> <n>   Demo threshold signing (t-of-n)
+  threshold <t> <n> [module] [--seed <s>]
+                      Demo threshold signing (t-of-n), optional 0x module address;
+                      --seed makes the run reproducible
   benchmark           Run performance benchmarks
+  vectors [count] [--seed <s>]
+                      Print JSON test vectors (default 4) for ports to other
+                      languages; without --seed the all-zero seed is used
   help                Show this help
 
 Examples:
   lamport keygen
   lamport chain 10
   lamport threshold 3 5
+  lamport threshold 5 5 --seed demo
   lamport benchmark
+  lamport vectors 8 --seed demo > vectors.json
+
+Exit status is 0 on success, 1 on a runtime error, and 2 on invalid arguments.
 
 For production use, see the Go library at github.com/luxfi/lamport`)
 }
 
-func cmdKeygen() {
-	fmt.Println("Generating Lamport key pair...")
+func cmdKeygen(w io.Writer, _ []string) error {
+	fmt.Fprintln(w, "Generating Lamport key pair...")
 
 	start := time.Now()
 	kp, err := primitives.GenerateKeyPair()
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		return err
 	}
 	elapsed := time.Since(start)
 
 	pkh := kp.Public.Hash()
 
-	fmt.Printf("\nKey generated in %v\n", elapsed)
-	fmt.Printf("\nPublic Key Hash (PKH): 0x%s\n", hex.EncodeToString(pkh[:]))
-	fmt.Printf("Public Key Size: %d bytes\n", primitives.PublicKeySize)
-	fmt.Printf("Private Key Size: %d bytes\n", primitives.PrivateKeySize)
-	fmt.Printf("\n⚠️  WARNING: This key can only be used ONCE!\n")
+	fmt.Fprintf(w, "\nKey generated in %v\n", elapsed)
+	fmt.Fprintf(w, "\nPublic Key Hash (PKH): 0x%s\n", hex.EncodeToString(pkh[:]))
+	fmt.Fprintf(w, "Public Key Size: %d bytes\n", primitives.PublicKeySize)
+	fmt.Fprintf(w, "Private Key Size: %d bytes\n", primitives.PrivateKeySize)
+	fmt.Fprintf(w, "\n⚠️  WARNING: This key can only be used ONCE!\n")
+	return nil
 }
 
-func cmdSign() {
-	fmt.Println("Sign command - for demo purposes only")
-	fmt.Println("In production, use the Go library directly.")
+func cmdSign(w io.Writer, _ []string) error {
+	fmt.Fprintln(w, "Sign command - for demo purposes only")
+	fmt.Fprintln(w, "In production, use the Go library directly.")
 
 	// Demo signing
-	kp, _ := primitives.GenerateKeyPair()
+	kp, err := primitives.GenerateKeyPair()
+	if err != nil {
+		return err
+	}
 	message := primitives.Keccak256([]byte("Demo message"))
-	sig, _ := primitives.Sign(kp.Private, message)
+	sig, err := primitives.Sign(kp.Private, message)
+	if err != nil {
+		return err
+	}
 
-	fmt.Printf("\nMessage: 0x%s\n", hex.EncodeToString(message[:]))
-	fmt.Printf("Signature size: %d bytes\n", len(sig.Bytes()))
-	fmt.Printf("Verification: %v\n", primitives.Verify(kp.Public, message, sig))
+	fmt.Fprintf(w, "\nMessage: 0x%s\n", hex.EncodeToString(message[:]))
+	fmt.Fprintf(w, "Signature size: %d bytes\n", len(sig.Bytes()))
+	fmt.Fprintf(w, "Verification: %v\n", primitives.Verify(kp.Public, message, sig))
+	return nil
 }
 
-func cmdVerify() {
-	fmt.Println("Verify command - for demo purposes only")
-	fmt.Println("In production, use the Go library or Solidity verifier.")
+func cmdVerify(w io.Writer, _ []string) error {
+	fmt.Fprintln(w, "Verify command - for demo purposes only")
+	fmt.Fprintln(w, "In production, use the Go library or Solidity verifier.")
+	return nil
 }
 
-func cmdChain() {
+func cmdChain(w io.Writer, args []string) error {
 	n := 10
-	if len(os.Args) > 2 {
+	if len(args) > 0 {
 		var err error
-		n, err = strconv.Atoi(os.Args[2])
+		n, err = strconv.Atoi(args[0])
 		if err != nil || n < 1 {
-			fmt.Println("Invalid chain size. Using default: 10")
-			n = 10
+			return usagef("invalid chain size %q (must be a positive integer)", args[0])
 		}
 	}
 
-	fmt.Printf("Generating key chain with %d keys...\n", n)
+	fmt.Fprintf(w, "Generating key chain with %d keys...\n", n)
 
 	start := time.Now()
 	chain, err := primitives.NewKeyChain(n)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		return err
 	}
 	elapsed := time.Since(start)
 
-	fmt.Printf("\nChain generated in %v\n", elapsed)
-	fmt.Printf("Average per key: %v\n", elapsed/time.Duration(n))
+	fmt.Fprintf(w, "\nChain generated in %v\n", elapsed)
+	fmt.Fprintf(w, "Average per key: %v\n", elapsed/time.Duration(n))
 
 	// Print first few PKHs
-	fmt.Println("\nFirst 5 PKHs:")
+	fmt.Fprintln(w, "\nFirst 5 PKHs:")
 	for i := 0; i < 5 && i < n; i++ {
 		pkh := chain.Keys[i].Public.Hash()
-		fmt.Printf("  [%d] 0x%s\n", i, hex.EncodeToString(pkh[:]))
+		fmt.Fprintf(w, "  [%d] 0x%s\n", i, hex.EncodeToString(pkh[:]))
 	}
 
 	// Demo signing through chain
-	fmt.Println("\nDemo: Signing 3 messages through chain...")
+	fmt.Fprintln(w, "\nDemo: Signing 3 messages through chain...")
 	for i := 0; i < 3 && chain.Remaining() > 0; i++ {
 		message := primitives.Keccak256([]byte(fmt.Sprintf("Message %d", i)))
-		sig, nextPKH, err := primitives.SignWithKeyChain(chain, message)
+		_, nextPKH, err := primitives.SignWithKeyChain(chain, message)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "  Signed message %d, nextPKH: 0x%s...\n", i, hex.EncodeToString(nextPKH[:8]))
+	}
+
+	fmt.Fprintf(w, "\nRemaining keys: %d\n", chain.Remaining())
+	return nil
+}
+
+func cmdThreshold(w io.Writer, args []string) error {
+	args, seed, err := parseSeedFlag(args)
+	if err != nil {
+		return err
+	}
+
+	t, n, err := parseThresholdArgs(args)
+	if err != nil {
+		return err
+	}
+
+	// All randomness comes from one reader so --seed makes the run reproducible
+	random := rand.Reader
+	if seed != nil {
+		random = primitives.NewSeededReader(*seed)
+	}
+
+	// Optional module address; random if not given
+	var moduleAddr [20]byte
+	if len(args) > 2 {
+		moduleAddr, err = primitives.ParseAddress(args[2])
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			break
+			return &usageError{err: err}
 		}
-		fmt.Printf("  Signed message %d, nextPKH: 0x%s...\n", i, hex.EncodeToString(nextPKH[:8]))
-		_ = sig
+	} else if _, err := io.ReadFull(random, moduleAddr[:]); err != nil {
+		return err
+	}
+
+	_, _, err = runThreshold(w, t, n, moduleAddr, random)
+	return err
+}
+
+// parseThresholdArgs parses "[<t> <n>]" (default 3-of-5), requiring
+// 1 <= t <= n.
+func parseThresholdArgs(args []string) (t, n int, err error) {
+	switch {
+	case len(args) == 0:
+		return 3, 5, nil
+	case len(args) == 1:
+		return 0, 0, usagef("threshold requires both <t> and <n>")
+	case len(args) > 3:
+		return 0, 0, usagef("too many arguments to threshold: %q", args[3:])
 	}
 
-	fmt.Printf("\nRemaining keys: %d\n", chain.Remaining())
+	t, err = strconv.Atoi(args[0])
+	if err != nil {
+		return 0, 0, usagef("invalid threshold %q", args[0])
+	}
+	n, err = strconv.Atoi(args[1])
+	if err != nil {
+		return 0, 0, usagef("invalid party count %q", args[1])
+	}
+	if t < 1 || t > n {
+		return 0, 0, usagef("invalid threshold %d-of-%d (must be 1 <= t <= n)", t, n)
+	}
+	return t, n, nil
 }
 
-func cmdThreshold() {
-	t := 3
-	n := 5
-	if len(os.Args) > 3 {
-		t, _ = strconv.Atoi(os.Args[2])
-		n, _ = strconv.Atoi(os.Args[3])
+// parseSeedFlag removes "--seed <value>" from args. The seed is keccak256 of
+// the value, so any string (e.g. "demo") gives a reproducible run.
+func parseSeedFlag(args []string) ([]string, *[32]byte, error) {
+	var rest []string
+	var seed *[32]byte
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--seed" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 == len(args) {
+			return nil, nil, usagef("--seed requires a value")
+		}
+		s := primitives.Keccak256([]byte(args[i+1]))
+		seed = &s
+		i++
 	}
+	return rest, seed, nil
+}
 
-	fmt.Printf("Demo: %d-of-%d Threshold Lamport Signing\n\n", t, n)
+// runThreshold runs the t-of-n signing demo, drawing shares and the signed
+// transaction from random and printing progress to w.
+func runThreshold(w io.Writer, t, n int, moduleAddr [20]byte, random io.Reader) (*primitives.PublicKey, *primitives.Signature, error) {
+	fmt.Fprintf(w, "Demo: %d-of-%d Threshold Lamport Signing\n\n", t, n)
 
 	// Generate shares
-	fmt.Printf("1. Generating %d shares...\n", n)
+	// Shamir shares, so any t of the n parties can sign
+	fmt.Fprintf(w, "1. Generating %d shares...\n", n)
 	start := time.Now()
-	shares, pub, err := threshold.GenerateShares(n)
+	shares, pub, err := threshold.GenerateSharesShamirFromReader(t, n, random)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		return nil, nil, err
 	}
-	fmt.Printf("   Done in %v\n", time.Since(start))
+	fmt.Fprintf(w, "   Done in %v\n", time.Since(start))
 
 	pkh := pub.Hash()
-	fmt.Printf("   PKH: 0x%s\n\n", hex.EncodeToString(pkh[:]))
+	fmt.Fprintf(w, "   PKH: 0x%s\n", hex.EncodeToString(pkh[:]))
 
 	// Setup threshold config
-	var moduleAddr [20]byte
-	rand.Read(moduleAddr[:])
-	config, _ := threshold.NewConfig(t, n, "coordinator", 96369, moduleAddr)
+	config, err := threshold.NewConfig(t, n, "coordinator", 96369, moduleAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	fmt.Fprintf(w, "   Module: %s\n\n", primitives.AddressChecksum(moduleAddr))
 
 	// Simulate signing
 	var safeTxHash, nextPKH [32]byte
-	rand.Read(safeTxHash[:])
-	rand.Read(nextPKH[:])
+	if _, err := io.ReadFull(random, safeTxHash[:]); err != nil {
+		return nil, nil, err
+	}
+	if _, err := io.ReadFull(random, nextPKH[:]); err != nil {
+		return nil, nil, err
+	}
 
 	message := config.ComputeMessage(safeTxHash, nextPKH)
-	fmt.Printf("2. Message to sign: 0x%s...\n\n", hex.EncodeToString(message[:8]))
+	fmt.Fprintf(w, "2. Message to sign: 0x%s...\n\n", hex.EncodeToString(message[:8]))
 
 	// Phase 1: Collect commitments
-	fmt.Printf("3. Phase 1: Collecting digest commitments...\n")
+	fmt.Fprintf(w, "3. Phase 1: Collecting digest commitments...\n")
 	coordinator := threshold.NewCoordinator(config, pub, safeTxHash, nextPKH)
 	for i := 0; i < t; i++ {
 		shares[i].PartyID = fmt.Sprintf("party-%d", i)
-		partyConfig, _ := threshold.NewConfig(t, n, shares[i].PartyID, 96369, moduleAddr)
+		partyConfig, err := threshold.NewConfig(t, n, shares[i].PartyID, 96369, moduleAddr)
+		if err != nil {
+			return nil, nil, err
+		}
 		commitment := partyConfig.CreateDigestCommitment(safeTxHash)
-		ready, _ := coordinator.AddCommitment(commitment, safeTxHash)
-		fmt.Printf("   Party %d committed\n", i)
+		ready, err := coordinator.AddCommitment(commitment, safeTxHash)
+		if err != nil {
+			return nil, nil, err
+		}
+		fmt.Fprintf(w, "   Party %d committed\n", i)
 		if ready {
-			fmt.Printf("   -> Ready to collect partials!\n")
+			fmt.Fprintf(w, "   -> Ready to collect partials!\n")
 		}
 	}
 
-	// Phase 2: Collect partials
-	fmt.Printf("\n4. Phase 2: Collecting partial signatures...\n")
+	// Phase 2: Collect partials; the coordinator interpolates the t
+	// Shamir partials and verifies the result
+	fmt.Fprintf(w, "\n4. Phase 2: Collecting partial signatures...\n")
+	coordinator.SetAggregationMode(threshold.ModeShamir)
 	start = time.Now()
 	var finalSig *primitives.Signature
 	for i := 0; i < t; i++ {
 		partial := threshold.CreatePartialSignature(shares[i], message)
-		sig, _ := coordinator.AddPartial(partial)
-		fmt.Printf("   Party %d signed\n", i)
-		if sig != nil {
-			finalSig = sig
-			fmt.Printf("   -> Signature complete!\n")
+		finalSig, err = coordinator.AddPartial(partial)
+		if err != nil {
+			return nil, nil, err
 		}
+		fmt.Fprintf(w, "   Party %d signed\n", i)
 	}
+	if finalSig == nil {
+		return nil, nil, threshold.ErrNotEnoughParties
+	}
+	fmt.Fprintf(w, "   -> Signature complete!\n")
 	signTime := time.Since(start)
 
 	// Verify
-	fmt.Printf("\n5. Verifying aggregated signature...\n")
+	fmt.Fprintf(w, "\n5. Verifying aggregated signature...\n")
 	start = time.Now()
 	valid := primitives.Verify(pub, message, finalSig)
 	verifyTime := time.Since(start)
 
-	fmt.Printf("   Valid: %v\n", valid)
-	fmt.Printf("\nTiming:\n")
-	fmt.Printf("   Sign (aggregate %d partials): %v\n", t, signTime)
-	fmt.Printf("   Verify: %v\n", verifyTime)
+	fmt.Fprintf(w, "   Valid: %v\n", valid)
+	if !valid {
+		return nil, nil, threshold.ErrInvalidPartial
+	}
+	fmt.Fprintf(w, "\nTiming:\n")
+	fmt.Fprintf(w, "   Sign (aggregate %d partials): %v\n", t, signTime)
+	fmt.Fprintf(w, "   Verify: %v\n", verifyTime)
+	return pub, finalSig, nil
 }
 
-func cmdBenchmark() {
-	fmt.Println("Lamport OTS Benchmarks")
-	fmt.Println("======================")
-	fmt.Println()
-
-	// KeyGen
-	iterations := 100
-	start := time.Now()
-	var kp *primitives.KeyPair
-	for i := 0; i < iterations; i++ {
-		kp, _ = primitives.GenerateKeyPair()
+// runVectors writes test vectors for "vectors [count] [--seed <s>]" to w.
+func runVectors(w io.Writer, args []string) error {
+	args, seed, err := parseSeedFlag(args)
+	if err != nil {
+		return err
 	}
-	keygenTime := time.Since(start) / time.Duration(iterations)
-	fmt.Printf("KeyGen:     %v per operation\n", keygenTime)
-
-	// Sign
-	message := primitives.Keccak256([]byte("Benchmark message"))
-	start = time.Now()
-	var sig *primitives.Signature
-	for i := 0; i < iterations; i++ {
-		kp, _ = primitives.GenerateKeyPair()
-		sig, _ = primitives.Sign(kp.Private, message)
+	if seed == nil {
+		seed = &[32]byte{}
 	}
-	signTime := time.Since(start) / time.Duration(iterations)
-	fmt.Printf("Sign:       %v per operation\n", signTime)
 
-	// Verify
-	kp, _ = primitives.GenerateKeyPair()
-	sig, _ = primitives.Sign(kp.Private, message)
-	start = time.Now()
-	for i := 0; i < iterations; i++ {
-		primitives.Verify(kp.Public, message, sig)
+	count := 4
+	if len(args) > 0 {
+		count, err = strconv.Atoi(args[0])
+		if err != nil || count < 1 {
+			return usagef("invalid vector count %q", args[0])
+		}
 	}
-	verifyTime := time.Since(start) / time.Duration(iterations)
-	fmt.Printf("Verify:     %v per operation\n", verifyTime)
+	return testvectors.WriteJSON(w, *seed, count)
+}
 
-	// PKH
-	start = time.Now()
-	for i := 0; i < iterations; i++ {
-		_ = kp.Public.Hash()
-	}
-	pkhTime := time.Since(start) / time.Duration(iterations)
-	fmt.Printf("PKH:        %v per operation\n", pkhTime)
+func cmdBenchmark(w io.Writer, _ []string) error {
+	fmt.Fprintln(w, "Lamport OTS Benchmarks")
+	fmt.Fprintln(w, "======================")
+	fmt.Fprintln(w)
 
-	// Threshold (3-of-5)
-	shares, pub, _ := threshold.GenerateShares(5)
-	var moduleAddr [20]byte
-	config, _ := threshold.NewConfig(3, 5, "bench", 1, moduleAddr)
-	var safeTxHash, nextPKH [32]byte
-	msg := config.ComputeMessage(safeTxHash, nextPKH)
+	res := primitives.RunBenchmarks(100)
+	fmt.Fprintf(w, "KeyGen:     %v per operation\n", res.KeyGen)
+	fmt.Fprintf(w, "Sign:       %v per operation\n", res.Sign)
+	fmt.Fprintf(w, "Verify:     %v per operation\n", res.Verify)
+	fmt.Fprintf(w, "PKH:        %v per operation\n", res.PKH)
 
-	start = time.Now()
-	for i := 0; i < iterations; i++ {
-		partials := make([]*threshold.PartialSignature, 3)
-		for j := 0; j < 3; j++ {
-			partials[j] = threshold.CreatePartialSignature(shares[j], msg)
-		}
-		_, _ = threshold.Aggregate(partials)
+	th, err := threshold.RunBenchmarks(100, 3, 5)
+	if err != nil {
+		return err
 	}
-	thresholdTime := time.Since(start) / time.Duration(iterations)
-	fmt.Printf("Threshold:  %v per operation (3-of-5)\n", thresholdTime)
-
-	fmt.Printf("\nSizes:\n")
-	fmt.Printf("Private Key: %d bytes (%.1f KB)\n", primitives.PrivateKeySize, float64(primitives.PrivateKeySize)/1024)
-	fmt.Printf("Public Key:  %d bytes (%.1f KB)\n", primitives.PublicKeySize, float64(primitives.PublicKeySize)/1024)
-	fmt.Printf("Signature:   %d bytes (%.1f KB)\n", primitives.SignatureSize, float64(primitives.SignatureSize)/1024)
-	fmt.Printf("PKH:         %d bytes\n", primitives.PublicKeyHashSize)
-
-	_ = pub
+	fmt.Fprintf(w, "Threshold:  %v per operation (%d-of-%d)\n", th.Total, th.Threshold, th.Parties)
+
+	fmt.Fprintf(w, "\nSizes:\n")
+	fmt.Fprintf(w, "Private Key: %d bytes (%.1f KB)\n", res.PrivateKeySize, float64(res.PrivateKeySize)/1024)
+	fmt.Fprintf(w, "Public Key:  %d bytes (%.1f KB)\n", res.PublicKeySize, float64(res.PublicKeySize)/1024)
+	fmt.Fprintf(w, "Signature:   %d bytes (%.1f KB)\n", res.SignatureSize, float64(res.SignatureSize)/1024)
+	fmt.Fprintf(w, "PKH:         %d bytes\n", res.PublicKeyHashSize)
+
+	pre, sol := precompile.CompareGas()
+	fmt.Fprintf(w, "\nVerification gas (including calldata):\n")
+	fmt.Fprintf(w, "Precompile:  %d (%d execution)\n", pre, uint64(precompile.TotalGas))
+	fmt.Fprintf(w, "Solidity:    ~%d (%d keccak, lower bound)\n", sol, uint64(precompile.SolidityHashGas))
+	return nil
 }
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/lamport/primitives"
+)
+
+func TestRunThresholdSeeded(t *testing.T) {
+	run := func() (*primitives.PublicKey, *primitives.Signature) {
+		seed := primitives.Keccak256([]byte("demo"))
+		random := primitives.NewSeededReader(seed)
+		var moduleAddr [20]byte
+		if _, err := io.ReadFull(random, moduleAddr[:]); err != nil {
+			t.Fatalf("ReadFull failed: %v", err)
+		}
+		pub, sig, err := runThreshold(io.Discard, 3, 5, moduleAddr, random)
+		if err != nil {
+			t.Fatalf("runThreshold failed: %v", err)
+		}
+		return pub, sig
+	}
+
+	pub1, sig1 := run()
+	pub2, sig2 := run()
+	if pub1.Hash() != pub2.Hash() {
+		t.Error("Seeded runs should produce identical PKHs")
+	}
+	if *sig1 != *sig2 {
+		t.Error("Seeded runs should produce identical signatures")
+	}
+}
+
+func TestParseSeedFlag(t *testing.T) {
+	args, seed, err := parseSeedFlag([]string{"3", "--seed", "demo", "5"})
+	if err != nil {
+		t.Fatalf("parseSeedFlag failed: %v", err)
+	}
+	if len(args) != 2 || args[0] != "3" || args[1] != "5" {
+		t.Errorf("Unexpected remaining args: %v", args)
+	}
+	if seed == nil || *seed != primitives.Keccak256([]byte("demo")) {
+		t.Error("Seed should be keccak256 of the flag value")
+	}
+
+	if _, _, err := parseSeedFlag([]string{"--seed"}); err == nil {
+		t.Error("Expected error for missing seed value")
+	}
+}
+
+func TestRunVectors(t *testing.T) {
+	var a, b bytes.Buffer
+	if err := runVectors(&a, []string{"2", "--seed", "demo"}); err != nil {
+		t.Fatalf("runVectors failed: %v", err)
+	}
+	if err := runVectors(&b, []string{"--seed", "demo", "2"}); err != nil {
+		t.Fatalf("runVectors failed: %v", err)
+	}
+	if a.Len() == 0 || !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Error("Seeded vectors should be non-empty and reproducible")
+	}
+
+	if err := runVectors(io.Discard, []string{"zero"}); err == nil {
+		t.Error("Expected error for invalid count")
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	var out, errOut bytes.Buffer
+	stdout, stderr = &out, &errOut
+	defer func() { stdout, stderr = os.Stdout, os.Stderr }()
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{nil, exitUsage},
+		{[]string{"bogus"}, exitUsage},
+		{[]string{"help"}, exitOK},
+		{[]string{"chain", "zero"}, exitUsage},
+		{[]string{"chain", "-3"}, exitUsage},
+		{[]string{"chain", "2"}, exitOK},
+		{[]string{"threshold", "3"}, exitUsage},
+		{[]string{"threshold", "x", "5"}, exitUsage},
+		{[]string{"threshold", "3", "y"}, exitUsage},
+		{[]string{"threshold", "6", "5"}, exitUsage},
+		{[]string{"threshold", "0", "5"}, exitUsage},
+		{[]string{"threshold", "2", "3", "0xnotanaddress"}, exitUsage},
+		{[]string{"threshold", "2", "3", "--seed"}, exitUsage},
+		{[]string{"threshold"}, exitOK},
+		{[]string{"threshold", "2", "3", "--seed", "demo"}, exitOK},
+		{[]string{"threshold", "3", "3", "--seed", "demo"}, exitOK},
+		{[]string{"vectors", "zero"}, exitUsage},
+		{[]string{"vectors", "1"}, exitOK},
+	}
+	for _, tt := range tests {
+		out.Reset()
+		errOut.Reset()
+		if got := run(tt.args); got != tt.want {
+			t.Errorf("run(%q) = %d, want %d (stderr: %s)", tt.args, got, tt.want, errOut.String())
+		}
+		if tt.want == exitOK && errOut.Len() != 0 {
+			t.Errorf("run(%q) wrote to stderr: %s", tt.args, errOut.String())
+		}
+		if tt.want != exitOK && errOut.Len() == 0 {
+			t.Errorf("run(%q) should explain the failure on stderr", tt.args)
+		}
+	}
+
+	errOut.Reset()
+	run([]string{"threshold", "6", "5"})
+	if !strings.Contains(errOut.String(), "1 <= t <= n") {
+		t.Errorf("Invalid threshold should produce a clear message, got %q", errOut.String())
+	}
+}
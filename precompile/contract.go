@@ -10,15 +10,31 @@
 //   - signature: bytes[256] (256 * 32 = 8192 bytes)
 //   - publicKey: bytes32[2][256] (256 * 2 * 32 = 16384 bytes)
 //
+// Extended input for RunWithPKH (exactly 24,640 bytes) appends:
+//   - committedPKH: bytes32 (32 bytes), checked against keccak256(publicKey)
+//
+// RunStrict additionally accepts the dynamic ABI encoding
+// abi.encode(bytes32, bytes32[], bytes32[2][]) (exactly 24,736 bytes) and
+// rejects any other length.
+//
 // Output: bool (32 bytes, ABI-encoded)
 //
 // Gas cost: 3000 base + 50 per hash check = ~15,800 gas
+// (+3,102 for the PKH check in extended mode)
 // (vs ~100,000+ gas for pure Solidity verification)
+//
+// Every entry point charges GasInvalidInput (3,000) for input it rejects
+// with an error, such as a wrong length, and its full cost otherwise.
+// Earlier versions charged TotalGas for any input to Run, however short;
+// chains upgrading must schedule that change like any other gas change.
 package precompile
 
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
 
 	"github.com/luxfi/lamport/primitives"
 )
@@ -47,27 +63,89 @@ const (
 
 	// MinInputSize is the minimum valid input size
 	MinInputSize = InputSizeMessage + InputSizeSignature + InputSizePublicKey // 24608
+
+	// InputSizePKH is the size of the committed PKH in extended input
+	InputSizePKH = primitives.PublicKeyHashSize
+
+	// ExtendedInputSize is the size of input carrying a committed PKH
+	ExtendedInputSize = MinInputSize + InputSizePKH // 24640
+
+	// ABIInputSize is the size of the dynamic ABI encoding accepted by
+	// RunStrict: two offset words and two array length words more than Run
+	ABIInputSize = MinInputSize + 4*32 // 24736
+
+	// GasPKHCheck is the EVM keccak256 cost of hashing the public key:
+	// 30 + 6 per 32-byte word
+	GasPKHCheck = 30 + 6*(primitives.PublicKeySize/32) // 3,102
+
+	// GasInvalidInput is what every RequiredGas method charges for input
+	// its entry point rejects: the base cost of rejecting it, with no
+	// hashing performed
+	GasInvalidInput = GasBase
 )
 
 var (
-	// ErrInvalidInput indicates the input format is invalid
+	// ErrInvalidInput indicates the input format is invalid. The more
+	// specific input errors below all wrap it.
 	ErrInvalidInput = errors.New("lamport precompile: invalid input")
 
+	// ErrInputTooShort indicates input shorter than MinInputSize
+	ErrInputTooShort = fmt.Errorf("%w: too short", ErrInvalidInput)
+
+	// ErrInputLength indicates input long enough to parse but of a length
+	// the called entry point does not accept
+	ErrInputLength = fmt.Errorf("%w: wrong length", ErrInvalidInput)
+
+	// ErrABIOffset indicates ABI-encoded input whose offset or array length
+	// words do not match the expected layout
+	ErrABIOffset = fmt.Errorf("%w: bad ABI offset", ErrInvalidInput)
+
 	// ErrOutOfGas indicates insufficient gas for verification
 	ErrOutOfGas = errors.New("lamport precompile: out of gas")
+
+	// ErrIncompleteInput indicates an InputBuilder field was never set
+	ErrIncompleteInput = errors.New("lamport precompile: incomplete input")
 )
 
 // PrecompileContract implements the Lamport verification precompile.
 type PrecompileContract struct{}
 
-// RequiredGas returns the gas required for the input.
+// RequiredGas returns the gas required for Run input: TotalGas, or
+// GasInvalidInput for input shorter than MinInputSize, which Run rejects.
 func (c *PrecompileContract) RequiredGas(input []byte) uint64 {
 	if len(input) < MinInputSize {
-		return 0 // Invalid input, will fail in Run
+		return GasInvalidInput
 	}
 	return TotalGas
 }
 
+// RequiredGasWithPKH returns the gas required for RunWithPKH input:
+// TotalGas plus GasPKHCheck for exactly ExtendedInputSize bytes, or
+// GasInvalidInput for any other length, which RunWithPKH rejects.
+func (c *PrecompileContract) RequiredGasWithPKH(input []byte) uint64 {
+	if len(input) != ExtendedInputSize {
+		return GasInvalidInput
+	}
+	return TotalGas + GasPKHCheck
+}
+
+// RequiredGasStrict returns the gas required for RunStrict input: that of
+// the entry point RunStrict dispatches to, or GasInvalidInput for input it
+// rejects (a wrong length or a malformed ABI head).
+func (c *PrecompileContract) RequiredGasStrict(input []byte) uint64 {
+	switch len(input) {
+	case MinInputSize:
+		return TotalGas
+	case ExtendedInputSize:
+		return c.RequiredGasWithPKH(input)
+	case ABIInputSize:
+		if checkABIHead(input) == nil {
+			return TotalGas
+		}
+	}
+	return GasInvalidInput
+}
+
 // Run executes the Lamport verification precompile.
 //
 // Input format:
@@ -75,43 +153,136 @@ func (c *PrecompileContract) RequiredGas(input []byte) uint64 {
 //   [32:8224]  - signature (bytes[256], each element is 32 bytes)
 //   [8224:24608] - publicKey (bytes32[2][256])
 //
+// Bytes past MinInputSize are ignored; PKH-bound input goes to RunWithPKH.
+// Hashes are checked in place with pooled hashers, split across up to
+// four goroutines; the result matches primitives.Verify and gas is unchanged.
+//
 // Returns:
 //   - 32 bytes: ABI-encoded bool (1 = valid, 0 = invalid)
 func (c *PrecompileContract) Run(input []byte) ([]byte, error) {
 	if len(input) < MinInputSize {
-		return nil, ErrInvalidInput
+		return nil, fmt.Errorf("%w: %d bytes, want at least %d", ErrInputTooShort, len(input), MinInputSize)
 	}
+	return abiBool(verifyInput(input)), nil
+}
 
-	// Parse message (bytes32)
-	var message [32]byte
-	copy(message[:], input[0:32])
+// RunMetered is Run that also reports the gas charged, which is always
+// RequiredGas(input): TotalGas, or GasInvalidInput when the input is
+// rejected as too short.
+func (c *PrecompileContract) RunMetered(input []byte) (output []byte, gasUsed uint64, err error) {
+	output, err = c.Run(input)
+	return output, c.RequiredGas(input), err
+}
 
-	// Parse signature (bytes[256])
-	var sig primitives.Signature
-	for i := 0; i < primitives.KeyBits; i++ {
-		offset := 32 + (i * 32)
-		copy(sig.Preimages[i][:], input[offset:offset+32])
+// RunWithPKH executes verification bound to a committed PKH.
+//
+// Input format:
+//   [0:24608]     - message, signature, publicKey (as in Run)
+//   [24608:24640] - committedPKH (bytes32)
+//
+// Returns true only if keccak256(publicKey) == committedPKH and the
+// signature verifies, so contracts storing only a PKH need no extra hashing.
+func (c *PrecompileContract) RunWithPKH(input []byte) ([]byte, error) {
+	if len(input) != ExtendedInputSize {
+		return nil, fmt.Errorf("%w: %d bytes, want %d", ErrInputLength, len(input), ExtendedInputSize)
 	}
 
-	// Parse public key (bytes32[2][256])
-	var pub primitives.PublicKey
-	pubOffset := 32 + primitives.SignatureSize
-	for i := 0; i < primitives.KeyBits; i++ {
-		offset0 := pubOffset + (i * 64)
-		offset1 := offset0 + 32
-		copy(pub.Hashes[i][0][:], input[offset0:offset0+32])
-		copy(pub.Hashes[i][1][:], input[offset1:offset1+32])
+	var committedPKH [32]byte
+	copy(committedPKH[:], input[MinInputSize:ExtendedInputSize])
+	if keccak256(input[32+primitives.SignatureSize:MinInputSize]) != committedPKH {
+		return abiBool(false), nil
+	}
+	return abiBool(verifyInput(input)), nil
+}
+
+// RunStrict is Run with strict input validation, for debugging on-chain
+// integrations. Input must be exactly MinInputSize bytes (handled by Run),
+// ExtendedInputSize bytes (handled by RunWithPKH), or ABIInputSize bytes of
+// abi.encode(bytes32 message, bytes32[] signature, bytes32[2][] publicKey),
+// whose offsets and array lengths are checked before verification.
+//
+// Errors all wrap ErrInvalidInput: ErrInputTooShort, ErrInputLength for any
+// other length, and ErrABIOffset for a malformed ABI head.
+func (c *PrecompileContract) RunStrict(input []byte) ([]byte, error) {
+	switch len(input) {
+	case MinInputSize:
+		return c.Run(input)
+	case ExtendedInputSize:
+		return c.RunWithPKH(input)
+	case ABIInputSize:
+		packed, err := unpackABIInput(input)
+		if err != nil {
+			return nil, err
+		}
+		return c.Run(packed)
+	}
+	if len(input) < MinInputSize {
+		return nil, fmt.Errorf("%w: %d bytes, want at least %d", ErrInputTooShort, len(input), MinInputSize)
 	}
+	return nil, fmt.Errorf("%w: %d bytes, want %d, %d, or %d",
+		ErrInputLength, len(input), MinInputSize, ExtendedInputSize, ABIInputSize)
+}
 
-	// Verify signature
-	valid := primitives.Verify(&pub, message, &sig)
+// ABI layout of abi.encode(bytes32, bytes32[], bytes32[2][]): a three-word
+// head, then each array as a length word followed by its elements.
+const (
+	abiSigOffset = 3 * 32
+	abiPubOffset = abiSigOffset + 32 + InputSizeSignature
+)
+
+// unpackABIInput checks the head of ABIInputSize input and returns the
+// equivalent MinInputSize input for Run.
+func unpackABIInput(input []byte) ([]byte, error) {
+	if err := checkABIHead(input); err != nil {
+		return nil, err
+	}
+
+	packed := make([]byte, 0, MinInputSize)
+	packed = append(packed, input[:32]...)
+	packed = append(packed, input[abiSigOffset+32:abiPubOffset]...)
+	packed = append(packed, input[abiPubOffset+32:]...)
+	return packed, nil
+}
+
+// checkABIHead checks the offset and array length words of ABIInputSize
+// input, returning ErrABIOffset for the first that is wrong.
+func checkABIHead(input []byte) error {
+	words := []struct {
+		name string
+		at   int
+		want uint64
+	}{
+		{"signature offset", 32, abiSigOffset},
+		{"public key offset", 64, abiPubOffset},
+		{"signature length", abiSigOffset, primitives.KeyBits},
+		{"public key length", abiPubOffset, primitives.KeyBits},
+	}
+	for _, w := range words {
+		if got, ok := abiUint64(input[w.at : w.at+32]); !ok || got != w.want {
+			return fmt.Errorf("%w: %s at byte %d is not %d", ErrABIOffset, w.name, w.at, w.want)
+		}
+	}
+	return nil
+}
+
+// abiUint64 decodes a 32-byte ABI uint256 word, reporting false if it does
+// not fit in 64 bits.
+func abiUint64(word []byte) (uint64, bool) {
+	for _, b := range word[:24] {
+		if b != 0 {
+			return 0, false
+		}
+	}
+	return binary.BigEndian.Uint64(word[24:]), true
+}
 
-	// Return ABI-encoded bool
+// abiBool returns an ABI-encoded bool.
+func abiBool(valid bool) []byte {
 	result := make([]byte, 32)
 	if valid {
 		result[31] = 1
 	}
-	return result, nil
+	return result
 }
 
 // EncodeInput encodes the verification inputs for the precompile.
@@ -136,6 +307,53 @@ func EncodeInput(message [32]byte, sig *primitives.Signature, pub *primitives.Pu
 	return input
 }
 
+// WritePrecompileInput streams the precompile input for (message, sig, pub)
+// to w, producing the same bytes as EncodeInput without materializing the
+// full 24 KB input. It returns the number of bytes written.
+func WritePrecompileInput(w io.Writer, message [32]byte, sig *primitives.Signature, pub *primitives.PublicKey) (int, error) {
+	// 32 public key rows (2 KB) per write keeps the buffer on the stack
+	var buf [32 * 64]byte
+	total := 0
+
+	n, err := w.Write(message[:])
+	total += n
+	if err != nil {
+		return total, err
+	}
+
+	for i := 0; i < primitives.KeyBits; i += len(buf) / 32 {
+		off := 0
+		for j := i; j < i+len(buf)/32; j++ {
+			off += copy(buf[off:], sig.Preimages[j][:])
+		}
+		n, err := w.Write(buf[:off])
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+
+	for i := 0; i < primitives.KeyBits; i += len(buf) / 64 {
+		off := 0
+		for j := i; j < i+len(buf)/64; j++ {
+			off += copy(buf[off:], pub.Hashes[j][0][:])
+			off += copy(buf[off:], pub.Hashes[j][1][:])
+		}
+		n, err := w.Write(buf[:off])
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// EncodeInputWithPKH encodes the extended input binding the public key to a committed PKH.
+func EncodeInputWithPKH(message [32]byte, sig *primitives.Signature, pub *primitives.PublicKey, committedPKH [32]byte) []byte {
+	return append(EncodeInput(message, sig, pub), committedPKH[:]...)
+}
+
 // DecodeOutput decodes the precompile output to a boolean.
 func DecodeOutput(output []byte) bool {
 	if len(output) < 32 {
@@ -157,6 +375,35 @@ func ABIEncodedPublicKey(pub *primitives.PublicKey) []byte {
 	return pub.Bytes()
 }
 
+// RotationTxSize is the size of BuildRotationTx calldata: the selector, a
+// head of message, signature offset, inline public key, and nextPKH, then
+// the signature as a length word and 256 elements.
+const RotationTxSize = 4 + rotationHeadSize + 32 + InputSizeSignature // 24708
+
+// rotationHeadSize is the ABI head of BuildRotationTx, and so the offset of
+// the signature array.
+const rotationHeadSize = 32 + 32 + InputSizePublicKey + 32 // 16480
+
+// BuildRotationTx returns calldata for a rotation function with the
+// Solidity signature
+//
+//	rotate(bytes32 message, bytes32[] signature, bytes32[2][256] publicKey, bytes32 nextPKH)
+//
+// (any name, selected by selector). Unlike ABIEncodedSignature, the
+// signature is a properly encoded dynamic array: its head slot holds the
+// offset of a length word followed by the 256 preimages. The fixed-size
+// public key is encoded inline in the head.
+func BuildRotationTx(message [32]byte, sig *primitives.Signature, pub *primitives.PublicKey, nextPKH [32]byte, selector [4]byte) []byte {
+	data := make([]byte, 0, RotationTxSize)
+	data = append(data, selector[:]...)
+	data = append(data, message[:]...)
+	data = append(data, uint256ToBytes(rotationHeadSize)...)
+	data = append(data, pub.Bytes()...)
+	data = append(data, nextPKH[:]...)
+	data = append(data, uint256ToBytes(primitives.KeyBits)...)
+	return append(data, sig.Bytes()...)
+}
+
 // PrecompileAddressBytes returns the precompile address as bytes.
 func PrecompileAddressBytes() [20]byte {
 	var addr [20]byte
@@ -170,52 +417,142 @@ func GasEstimate() uint64 {
 	return TotalGas
 }
 
+// Standard EVM gas constants used by EstimateSolidityGas.
+const (
+	// GasKeccakBase is the static cost of the KECCAK256 opcode
+	GasKeccakBase = 30
+
+	// GasKeccakWord is the KECCAK256 cost per 32-byte word hashed
+	GasKeccakWord = 6
+
+	// GasCalldataZero is the transaction cost of a zero calldata byte
+	GasCalldataZero = 4
+
+	// GasCalldataNonZero is the transaction cost of a non-zero calldata byte
+	GasCalldataNonZero = 16
+)
+
+// EstimateSolidityGas approximates the gas a pure-Solidity verifier spends
+// on sig: calldata for the message, signature, and public key, plus one
+// keccak256 per revealed preimage. The public key and message are costed as
+// all non-zero bytes, which random hashes approach. Loop overhead, memory
+// expansion, and the transaction base fee are not counted, so this is a
+// lower bound. A nil sig is costed as all non-zero bytes.
+func EstimateSolidityGas(sig *primitives.Signature) uint64 {
+	zero := 0
+	if sig != nil {
+		for i := range sig.Preimages {
+			for _, b := range sig.Preimages[i] {
+				if b == 0 {
+					zero++
+				}
+			}
+		}
+	}
+	return solidityGas(zero)
+}
+
+// solidityGas is EstimateSolidityGas for a signature with the given number
+// of zero bytes.
+func solidityGas(zero int) uint64 {
+	return calldataGas(zero) + SolidityHashGas
+}
+
+// SolidityHashGas is the KECCAK256 cost of a pure-Solidity verifier's 256
+// preimage hashes, its execution cost before loop and memory overhead.
+const SolidityHashGas = primitives.KeyBits * (GasKeccakBase + GasKeccakWord*(primitives.PreimageSize/32)) // 9,216
+
+// calldataGas is the transaction calldata cost of MinInputSize bytes of
+// input, zero of them zero bytes. Both the precompile and a Solidity
+// verifier are sent the same input, so both pay it.
+func calldataGas(zero int) uint64 {
+	return uint64(MinInputSize-zero)*GasCalldataNonZero + uint64(zero)*GasCalldataZero
+}
+
+// CompareGas returns the total gas of verifying a signature with no zero
+// bytes through the precompile (TotalGas) and through a Solidity verifier
+// (EstimateSolidityGas), each including the calldata both pay. Calldata
+// dominates both; compare TotalGas with SolidityHashGas for execution cost
+// alone, keeping in mind that SolidityHashGas omits the verifier's loop and
+// memory costs.
+func CompareGas() (precompile, solidity uint64) {
+	return calldataGas(0) + TotalGas, solidityGas(0)
+}
+
 // InputBuilder helps construct precompile input.
+// Setters write fixed offsets of a MinInputSize buffer, so they may be
+// called in any order.
 type InputBuilder struct {
 	data []byte
+	set  inputField // fields written so far
 }
 
+// inputField identifies an InputBuilder field for Validate.
+type inputField uint8
+
+const (
+	fieldMessage inputField = 1 << iota
+	fieldSignature
+	fieldPublicKey
+
+	fieldsAll = fieldMessage | fieldSignature | fieldPublicKey
+)
+
 // NewInputBuilder creates a new input builder.
 func NewInputBuilder() *InputBuilder {
 	return &InputBuilder{
-		data: make([]byte, 0, MinInputSize),
+		data: make([]byte, MinInputSize),
 	}
 }
 
 // SetMessage sets the message (bytes32).
 func (b *InputBuilder) SetMessage(message [32]byte) *InputBuilder {
-	if len(b.data) < 32 {
-		b.data = append(b.data, make([]byte, 32-len(b.data))...)
-	}
 	copy(b.data[0:32], message[:])
+	b.set |= fieldMessage
 	return b
 }
 
 // SetSignature sets the signature.
 func (b *InputBuilder) SetSignature(sig *primitives.Signature) *InputBuilder {
-	if len(b.data) < 32+primitives.SignatureSize {
-		b.data = append(b.data, make([]byte, 32+primitives.SignatureSize-len(b.data))...)
-	}
 	for i := 0; i < primitives.KeyBits; i++ {
 		copy(b.data[32+(i*32):], sig.Preimages[i][:])
 	}
+	b.set |= fieldSignature
 	return b
 }
 
 // SetPublicKey sets the public key.
 func (b *InputBuilder) SetPublicKey(pub *primitives.PublicKey) *InputBuilder {
-	if len(b.data) < MinInputSize {
-		b.data = append(b.data, make([]byte, MinInputSize-len(b.data))...)
-	}
 	offset := 32 + primitives.SignatureSize
 	for i := 0; i < primitives.KeyBits; i++ {
 		copy(b.data[offset+(i*64):], pub.Hashes[i][0][:])
 		copy(b.data[offset+(i*64)+32:], pub.Hashes[i][1][:])
 	}
+	b.set |= fieldPublicKey
 	return b
 }
 
-// Build returns the constructed input.
+// Validate returns ErrIncompleteInput, naming the missing fields, unless
+// the message, signature, and public key have all been set.
+func (b *InputBuilder) Validate() error {
+	if b.set == fieldsAll {
+		return nil
+	}
+	var missing []string
+	if b.set&fieldMessage == 0 {
+		missing = append(missing, "message")
+	}
+	if b.set&fieldSignature == 0 {
+		missing = append(missing, "signature")
+	}
+	if b.set&fieldPublicKey == 0 {
+		missing = append(missing, "public key")
+	}
+	return fmt.Errorf("%w: missing %s", ErrIncompleteInput, strings.Join(missing, ", "))
+}
+
+// Build returns the constructed input. It is always MinInputSize bytes;
+// unset fields are zero, so call Validate first.
 func (b *InputBuilder) Build() []byte {
 	return b.data
 }
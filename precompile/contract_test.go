@@ -0,0 +1,507 @@
+package precompile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/luxfi/lamport/primitives"
+)
+
+func TestRunWithPKH(t *testing.T) {
+	kp, err := primitives.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := primitives.Keccak256([]byte("precompile"))
+	sig, err := primitives.Sign(kp.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	pkh := kp.Public.Hash()
+	c := &PrecompileContract{}
+
+	bad := *sig
+	bad.Preimages[5][0] ^= 1
+	wrongPKH := pkh
+	wrongPKH[0] ^= 1
+
+	tests := []struct {
+		name string
+		sig  *primitives.Signature
+		pkh  [32]byte
+		want bool
+	}{
+		{"matching PKH, valid signature", sig, pkh, true},
+		{"matching PKH, invalid signature", &bad, pkh, false},
+		{"wrong PKH", sig, wrongPKH, false},
+	}
+	for _, tt := range tests {
+		input := EncodeInputWithPKH(message, tt.sig, kp.Public, tt.pkh)
+		if got := c.RequiredGasWithPKH(input); got != TotalGas+GasPKHCheck {
+			t.Errorf("%s: RequiredGasWithPKH = %d, want %d", tt.name, got, TotalGas+GasPKHCheck)
+		}
+		out, err := c.RunWithPKH(input)
+		if err != nil {
+			t.Fatalf("%s: RunWithPKH failed: %v", tt.name, err)
+		}
+		if DecodeOutput(out) != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, DecodeOutput(out), tt.want)
+		}
+
+		// Run ignores the trailing PKH, as it always has
+		if got := c.RequiredGas(input); got != TotalGas {
+			t.Errorf("%s: RequiredGas = %d, want %d", tt.name, got, TotalGas)
+		}
+		out, err = c.Run(input)
+		if err != nil {
+			t.Fatalf("%s: Run failed: %v", tt.name, err)
+		}
+		if DecodeOutput(out) != (tt.sig == sig) {
+			t.Errorf("%s: Run got %v, want %v", tt.name, DecodeOutput(out), tt.sig == sig)
+		}
+	}
+
+	// Plain input still verifies without a PKH
+	out, err := c.Run(EncodeInput(message, sig, kp.Public))
+	if err != nil || !DecodeOutput(out) {
+		t.Errorf("Plain input should verify: %v", err)
+	}
+	if _, err := c.RunWithPKH(EncodeInput(message, sig, kp.Public)); !errors.Is(err, ErrInputLength) {
+		t.Errorf("Expected ErrInputLength for short extended input, got %v", err)
+	}
+	if got := c.RequiredGasWithPKH(EncodeInput(message, sig, kp.Public)); got != GasInvalidInput {
+		t.Errorf("RequiredGasWithPKH for rejected input = %d, want %d", got, GasInvalidInput)
+	}
+}
+
+// naiveVerifyInput is the straightforward Run path: parse into primitives
+// types and call primitives.Verify. verifyInput must agree with it.
+func naiveVerifyInput(input []byte) bool {
+	// Parse message (bytes32)
+	var message [32]byte
+	copy(message[:], input[0:32])
+
+	// Parse signature (bytes[256])
+	var sig primitives.Signature
+	for i := 0; i < primitives.KeyBits; i++ {
+		offset := 32 + (i * 32)
+		copy(sig.Preimages[i][:], input[offset:offset+32])
+	}
+
+	// Parse public key (bytes32[2][256])
+	var pub primitives.PublicKey
+	pubOffset := 32 + primitives.SignatureSize
+	for i := 0; i < primitives.KeyBits; i++ {
+		offset0 := pubOffset + (i * 64)
+		offset1 := offset0 + 32
+		copy(pub.Hashes[i][0][:], input[offset0:offset0+32])
+		copy(pub.Hashes[i][1][:], input[offset1:offset1+32])
+	}
+
+	// Verify signature
+	return primitives.Verify(&pub, message, &sig)
+}
+
+func TestVerifyInputMatchesNaive(t *testing.T) {
+	kp, err := primitives.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := primitives.Keccak256([]byte("fast path"))
+	sig, _ := primitives.Sign(kp.Private, message)
+
+	bad := *sig
+	bad.Preimages[255][31] ^= 1
+	otherMessage := message
+	otherMessage[0] ^= 0x80
+
+	inputs := map[string][]byte{
+		"valid":          EncodeInput(message, sig, kp.Public),
+		"tampered":       EncodeInput(message, &bad, kp.Public),
+		"wrong message":  EncodeInput(otherMessage, sig, kp.Public),
+		"zero signature": EncodeInput(message, &primitives.Signature{}, kp.Public),
+	}
+	// Cover both the sequential and the split-across-workers paths
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	for _, procs := range []int{1, maxVerifyWorkers} {
+		runtime.GOMAXPROCS(procs)
+		for name, input := range inputs {
+			if got, want := verifyInput(input), naiveVerifyInput(input); got != want {
+				t.Errorf("%s (GOMAXPROCS=%d): verifyInput = %v, naive = %v", name, procs, got, want)
+			}
+		}
+	}
+	if !verifyInput(inputs["valid"]) {
+		t.Error("Valid input should verify")
+	}
+}
+
+func BenchmarkRun(b *testing.B) {
+	kp, _ := primitives.GenerateKeyPair()
+	message := primitives.Keccak256([]byte("Benchmark"))
+	sig, _ := primitives.Sign(kp.Private, message)
+	input := EncodeInput(message, sig, kp.Public)
+
+	b.Run("optimized", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			verifyInput(input)
+		}
+	})
+	b.Run("naive", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			naiveVerifyInput(input)
+		}
+	})
+}
+
+func TestInputBuilderOrder(t *testing.T) {
+	kp, err := primitives.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := primitives.Keccak256([]byte("builder"))
+	sig, err := primitives.Sign(kp.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	want := EncodeInput(message, sig, kp.Public)
+
+	setters := map[byte]func(*InputBuilder){
+		'm': func(b *InputBuilder) { b.SetMessage(message) },
+		's': func(b *InputBuilder) { b.SetSignature(sig) },
+		'p': func(b *InputBuilder) { b.SetPublicKey(kp.Public) },
+	}
+	for _, order := range []string{"msp", "mps", "smp", "spm", "pms", "psm"} {
+		b := NewInputBuilder()
+		for i := 0; i < len(order); i++ {
+			setters[order[i]](b)
+		}
+		if err := b.Validate(); err != nil {
+			t.Errorf("%s: Validate failed: %v", order, err)
+		}
+		if got := b.Build(); !bytes.Equal(got, want) {
+			t.Errorf("%s: Build differs from EncodeInput", order)
+		}
+	}
+
+	for _, order := range []string{"", "m", "sp", "pm"} {
+		b := NewInputBuilder()
+		for i := 0; i < len(order); i++ {
+			setters[order[i]](b)
+		}
+		if err := b.Validate(); !errors.Is(err, ErrIncompleteInput) {
+			t.Errorf("%q: expected ErrIncompleteInput, got %v", order, err)
+		}
+		if len(b.Build()) != MinInputSize {
+			t.Errorf("%q: Build length %d, want %d", order, len(b.Build()), MinInputSize)
+		}
+	}
+}
+
+func TestWritePrecompileInput(t *testing.T) {
+	kp, err := primitives.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := primitives.Keccak256([]byte("stream"))
+	sig, err := primitives.Sign(kp.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := WritePrecompileInput(&buf, message, sig, kp.Public)
+	if err != nil {
+		t.Fatalf("WritePrecompileInput failed: %v", err)
+	}
+	if n != MinInputSize {
+		t.Errorf("Wrote %d bytes, want %d", n, MinInputSize)
+	}
+	if !bytes.Equal(buf.Bytes(), EncodeInput(message, sig, kp.Public)) {
+		t.Error("Streamed input differs from EncodeInput")
+	}
+}
+
+func TestRunMetered(t *testing.T) {
+	kp, err := primitives.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := primitives.Keccak256([]byte("metered"))
+	sig, err := primitives.Sign(kp.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	input := EncodeInput(message, sig, kp.Public)
+	c := &PrecompileContract{}
+
+	tests := []struct {
+		name    string
+		input   []byte
+		gas     uint64
+		wantErr error
+		valid   bool
+	}{
+		{"valid", input, TotalGas, nil, true},
+		{"extended", EncodeInputWithPKH(message, sig, kp.Public, [32]byte{}), TotalGas, nil, true},
+		{"short", input[:MinInputSize-1], GasInvalidInput, ErrInputTooShort, false},
+		{"empty", nil, GasInvalidInput, ErrInputTooShort, false},
+		{"oversized", append(append([]byte{}, input...), make([]byte, 100)...), TotalGas, nil, true},
+	}
+	for _, tt := range tests {
+		output, gas, err := c.RunMetered(tt.input)
+		if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+		if gas != tt.gas {
+			t.Errorf("%s: gasUsed = %d, want %d", tt.name, gas, tt.gas)
+		}
+		if required := c.RequiredGas(tt.input); gas != required {
+			t.Errorf("%s: gasUsed = %d, but RequiredGas = %d", tt.name, gas, required)
+		}
+		if DecodeOutput(output) != tt.valid {
+			t.Errorf("%s: output = %v, want %v", tt.name, DecodeOutput(output), tt.valid)
+		}
+	}
+}
+
+func TestCompareGas(t *testing.T) {
+	pre, sol := CompareGas()
+	// Calldata for ~24 KB of input dominates both: roughly 400k gas
+	if sol < 300_000 || sol > 500_000 {
+		t.Errorf("solidity gas = %d, outside plausible range", sol)
+	}
+	// Both sides pay the same calldata, so they differ only in execution
+	if pre-TotalGas != sol-SolidityHashGas {
+		t.Errorf("calldata differs: precompile %d, solidity %d", pre-TotalGas, sol-SolidityHashGas)
+	}
+	if SolidityHashGas != 256*36 {
+		t.Errorf("SolidityHashGas = %d, want %d", SolidityHashGas, 256*36)
+	}
+
+	kp, err := primitives.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	sig, err := primitives.Sign(kp.Private, primitives.Keccak256([]byte("gas")))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	// Zero bytes in the preimages only lower the calldata cost
+	if got := EstimateSolidityGas(sig); got > sol || got < sol-uint64(InputSizeSignature)*(GasCalldataNonZero-GasCalldataZero) {
+		t.Errorf("EstimateSolidityGas = %d, want within signature calldata of %d", got, sol)
+	}
+	var zero primitives.Signature
+	if got, want := EstimateSolidityGas(&zero), sol-uint64(InputSizeSignature)*(GasCalldataNonZero-GasCalldataZero); got != want {
+		t.Errorf("EstimateSolidityGas(zero) = %d, want %d", got, want)
+	}
+}
+
+// encodeABIInput returns abi.encode(message, sig, pub) with dynamic arrays.
+func encodeABIInput(message [32]byte, sig *primitives.Signature, pub *primitives.PublicKey) []byte {
+	packed := EncodeInput(message, sig, pub)
+	input := append([]byte{}, message[:]...)
+	input = append(input, uint256ToBytes(abiSigOffset)...)
+	input = append(input, uint256ToBytes(abiPubOffset)...)
+	input = append(input, uint256ToBytes(primitives.KeyBits)...)
+	input = append(input, packed[32:32+InputSizeSignature]...)
+	input = append(input, uint256ToBytes(primitives.KeyBits)...)
+	return append(input, packed[32+InputSizeSignature:]...)
+}
+
+func TestRunStrict(t *testing.T) {
+	kp, err := primitives.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := primitives.Keccak256([]byte("strict"))
+	sig, err := primitives.Sign(kp.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	input := EncodeInput(message, sig, kp.Public)
+	abiInput := encodeABIInput(message, sig, kp.Public)
+	if len(abiInput) != ABIInputSize {
+		t.Fatalf("ABI input is %d bytes, want %d", len(abiInput), ABIInputSize)
+	}
+	c := &PrecompileContract{}
+
+	corrupt := func(at int) []byte {
+		b := append([]byte{}, abiInput...)
+		b[at+31]++
+		return b
+	}
+	tests := []struct {
+		name    string
+		input   []byte
+		wantErr error
+		valid   bool
+	}{
+		{"packed", input, nil, true},
+		{"extended", EncodeInputWithPKH(message, sig, kp.Public, kp.Public.Hash()), nil, true},
+		{"abi", abiInput, nil, true},
+		{"short", input[:MinInputSize-1], ErrInputTooShort, false},
+		{"one extra byte", append(append([]byte{}, input...), 0), ErrInputLength, false},
+		{"one byte under abi", abiInput[:ABIInputSize-1], ErrInputLength, false},
+		{"signature offset", corrupt(32), ErrABIOffset, false},
+		{"public key offset", corrupt(64), ErrABIOffset, false},
+		{"signature length", corrupt(abiSigOffset), ErrABIOffset, false},
+		{"public key length", corrupt(abiPubOffset), ErrABIOffset, false},
+	}
+	sentinels := []error{ErrInputTooShort, ErrInputLength, ErrABIOffset}
+	for _, tt := range tests {
+		output, err := c.RunStrict(tt.input)
+		if tt.wantErr == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+		} else {
+			if !errors.Is(err, tt.wantErr) || !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("%s: expected %v, got %v", tt.name, tt.wantErr, err)
+			}
+			for _, other := range sentinels {
+				if other != tt.wantErr && errors.Is(err, other) {
+					t.Errorf("%s: error %v also matches %v", tt.name, err, other)
+				}
+			}
+		}
+		if DecodeOutput(output) != tt.valid {
+			t.Errorf("%s: output = %v, want %v", tt.name, DecodeOutput(output), tt.valid)
+		}
+
+		// Rejected input costs GasInvalidInput, as on every entry point
+		wantGas := uint64(GasInvalidInput)
+		switch {
+		case tt.wantErr != nil:
+		case len(tt.input) == ExtendedInputSize:
+			wantGas = TotalGas + GasPKHCheck
+		default:
+			wantGas = TotalGas
+		}
+		if got := c.RequiredGasStrict(tt.input); got != wantGas {
+			t.Errorf("%s: RequiredGasStrict = %d, want %d", tt.name, got, wantGas)
+		}
+	}
+
+	// Run stays lenient about trailing bytes
+	if _, err := c.Run(append(append([]byte{}, input...), 0)); err != nil {
+		t.Errorf("Run should accept oversized input, got %v", err)
+	}
+}
+
+// TestForgedProjectionRejected checks that no entry point accepts a
+// projected public key: the projection is bound to neither a PKH nor the
+// message, so anyone could build one that verifies their own signature.
+func TestForgedProjectionRejected(t *testing.T) {
+	c := &PrecompileContract{}
+	forger, err := primitives.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := primitives.Keccak256([]byte("forged"))
+	sig, err := primitives.Sign(forger.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	forged := append([]byte{}, message[:]...)
+	forged = append(forged, sig.Bytes()...)
+	projection := forger.Public.ProjectForMessage(message)
+	for i := range projection.Hashes {
+		forged = append(forged, projection.Hashes[i][:]...)
+	}
+
+	for name, run := range map[string]func([]byte) ([]byte, error){
+		"Run":        c.Run,
+		"RunStrict":  c.RunStrict,
+		"RunWithPKH": c.RunWithPKH,
+	} {
+		output, err := run(forged)
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: expected ErrInvalidInput for projected input, got %v", name, err)
+		}
+		if DecodeOutput(output) {
+			t.Errorf("%s: forged projection should not verify", name)
+		}
+	}
+}
+
+// decodeRotationTx decodes BuildRotationTx calldata, following the ABI
+// offset of the signature array.
+func decodeRotationTx(t *testing.T, data []byte) (selector [4]byte, message [32]byte, sig *primitives.Signature, pub *primitives.PublicKey, nextPKH [32]byte) {
+	t.Helper()
+	copy(selector[:], data[:4])
+	args := data[4:]
+	word := func(off int) uint64 {
+		if !bytes.Equal(args[off:off+24], make([]byte, 24)) {
+			t.Fatalf("ABI word at %d does not fit in 64 bits", off)
+		}
+		return binary.BigEndian.Uint64(args[off+24 : off+32])
+	}
+
+	copy(message[:], args[0:32])
+	sigOffset := int(word(32))
+	pub = &primitives.PublicKey{}
+	if err := pub.FromBytes(args[64 : 64+InputSizePublicKey]); err != nil {
+		t.Fatalf("public key: %v", err)
+	}
+	copy(nextPKH[:], args[64+InputSizePublicKey:])
+
+	if n := word(sigOffset); n != primitives.KeyBits {
+		t.Fatalf("signature length = %d, want %d", n, primitives.KeyBits)
+	}
+	sig = &primitives.Signature{}
+	if err := sig.FromBytes(args[sigOffset+32:]); err != nil {
+		t.Fatalf("signature: %v", err)
+	}
+	return selector, message, sig, pub, nextPKH
+}
+
+func TestBuildRotationTx(t *testing.T) {
+	kp, err := primitives.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	next, err := primitives.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := primitives.Keccak256([]byte("rotate"))
+	sig, err := primitives.Sign(kp.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	var selector [4]byte
+	fn := primitives.Keccak256([]byte("rotate(bytes32,bytes32[],bytes32[2][256],bytes32)"))
+	copy(selector[:], fn[:4])
+
+	data := BuildRotationTx(message, sig, kp.Public, next.Public.Hash(), selector)
+	if len(data) != RotationTxSize {
+		t.Fatalf("calldata is %d bytes, want %d", len(data), RotationTxSize)
+	}
+
+	gotSelector, gotMessage, gotSig, gotPub, gotNext := decodeRotationTx(t, data)
+	if gotSelector != selector {
+		t.Errorf("selector = %x, want %x", gotSelector, selector)
+	}
+	if gotMessage != message {
+		t.Error("message does not round-trip")
+	}
+	if *gotSig != *sig {
+		t.Error("signature does not round-trip")
+	}
+	if *gotPub != *kp.Public {
+		t.Error("public key does not round-trip")
+	}
+	if gotNext != next.Public.Hash() {
+		t.Error("nextPKH does not round-trip")
+	}
+	if !primitives.Verify(gotPub, gotMessage, gotSig) {
+		t.Error("decoded signature should verify")
+	}
+}
@@ -0,0 +1,119 @@
+package precompile
+
+import (
+	"bytes"
+	"hash"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/luxfi/lamport/primitives"
+)
+
+// maxVerifyWorkers caps the goroutines one Run call spreads its 256 hashes
+// over; beyond this, scheduling overhead outweighs the per-chunk work.
+const maxVerifyWorkers = 4
+
+// keccakState is a keccak256 hasher that can squeeze output without the
+// state copy made by Sum.
+type keccakState interface {
+	hash.Hash
+	Read([]byte) (int, error)
+}
+
+// hasher pairs a keccak state with its output buffer so neither escapes.
+type hasher struct {
+	state  keccakState
+	digest [primitives.HashSize]byte
+}
+
+// hasherPool reuses hasher state across calls; Run hashes 256 preimages
+// per invocation and that is the dominant cost of the precompile.
+var hasherPool = sync.Pool{
+	New: func() any {
+		return &hasher{state: sha3.NewLegacyKeccak256().(keccakState)}
+	},
+}
+
+// verifyInput verifies message, signature, and public key in place in the
+// precompile input, splitting the 256 hash checks across up to
+// maxVerifyWorkers goroutines with pooled hashers. It returns exactly what
+// primitives.Verify returns for the parsed values, including rejecting an
+// all-zero signature.
+func verifyInput(input []byte) bool {
+	sig := input[32 : 32+primitives.SignatureSize]
+
+	var zero [primitives.PreimageSize]byte
+	wellFormed := false
+	for i := 0; i < primitives.KeyBits && !wellFormed; i++ {
+		wellFormed = !bytes.Equal(sig[i*32:(i+1)*32], zero[:])
+	}
+	if !wellFormed {
+		return false
+	}
+
+	workers := min(runtime.GOMAXPROCS(0), maxVerifyWorkers)
+	if workers <= 1 {
+		return verifyRange(input, 0, primitives.KeyBits, nil)
+	}
+
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	chunk := primitives.KeyBits / workers
+	for w := 0; w < workers; w++ {
+		start, end := w*chunk, (w+1)*chunk
+		if w == workers-1 {
+			end = primitives.KeyBits
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !verifyRange(input, start, end, &failed) {
+				failed.Store(true)
+			}
+		}()
+	}
+	wg.Wait()
+	return !failed.Load()
+}
+
+// verifyRange checks bit positions [start, end) of the input. It stops
+// early on a mismatch or once another worker has set failed.
+func verifyRange(input []byte, start, end int, failed *atomic.Bool) bool {
+	message := input[0:32]
+	sig := input[32 : 32+primitives.SignatureSize]
+	pub := input[32+primitives.SignatureSize : MinInputSize]
+
+	h := hasherPool.Get().(*hasher)
+	defer hasherPool.Put(h)
+
+	for i := start; i < end; i++ {
+		if failed != nil && failed.Load() {
+			return false
+		}
+		bit := int(message[i/8]>>(7-i%8)) & 1
+		expected := pub[i*64+bit*32 : i*64+bit*32+32]
+
+		h.state.Reset()
+		h.state.Write(sig[i*32 : (i+1)*32])
+		h.state.Read(h.digest[:])
+		if !bytes.Equal(h.digest[:], expected) {
+			return false
+		}
+	}
+	return true
+}
+
+// keccak256 hashes data with a pooled keccak256 state. Unlike
+// primitives.Keccak256 it ignores primitives.SetHashFunc, as the EVM does.
+func keccak256(data []byte) [primitives.HashSize]byte {
+	h := hasherPool.Get().(*hasher)
+	defer hasherPool.Put(h)
+
+	h.state.Reset()
+	h.state.Write(data)
+	h.state.Read(h.digest[:])
+	return h.digest
+}
@@ -0,0 +1,60 @@
+package primitives
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrInvalidAddress indicates an address string is not 20 bytes of hex
+	ErrInvalidAddress = errors.New("lamport: invalid address (must be 20 bytes of hex)")
+
+	// ErrAddressChecksum indicates a mixed-case address fails EIP-55 validation
+	ErrAddressChecksum = errors.New("lamport: invalid EIP-55 address checksum")
+)
+
+// ParseAddress parses a 0x-prefixed (or bare) 40-character hex address.
+//
+// All-lowercase and all-uppercase inputs are accepted as-is. Mixed-case input
+// is treated as EIP-55 checksummed and rejected if the checksum is wrong.
+func ParseAddress(s string) ([20]byte, error) {
+	var addr [20]byte
+	h := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(h) != 40 {
+		return addr, ErrInvalidAddress
+	}
+	if _, err := hex.Decode(addr[:], []byte(h)); err != nil {
+		return [20]byte{}, ErrInvalidAddress
+	}
+
+	if h != strings.ToLower(h) && h != strings.ToUpper(h) {
+		if AddressChecksum(addr)[2:] != h {
+			return [20]byte{}, ErrAddressChecksum
+		}
+	}
+	return addr, nil
+}
+
+// AddressChecksum returns the EIP-55 mixed-case checksum encoding of addr,
+// including the 0x prefix.
+func AddressChecksum(addr [20]byte) string {
+	lower := hex.EncodeToString(addr[:])
+	hash := Keccak256([]byte(lower))
+
+	out := []byte(lower)
+	for i, c := range out {
+		if c < 'a' {
+			continue // digit
+		}
+		// Uppercase when the matching nibble of the hash is >= 8
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0x0f >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
@@ -0,0 +1,167 @@
+package primitives
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+const keychainArchiveVersion = 1
+
+// keychainArchive is the JSON layout of ExportKeychainArchive. The manifest
+// is plaintext so tooling can list chains and PKHs without the password.
+// The encrypted keystore v3 crypto section holds keccak256 of the manifest
+// JSON, binding it to the MAC, followed by the private keys of every chain
+// in manifest order.
+type keychainArchive struct {
+	Version  int                    `json:"version"`
+	Manifest []keychainArchiveEntry `json:"manifest"`
+	Crypto   keystoreCrypto         `json:"crypto"`
+}
+
+type keychainArchiveEntry struct {
+	Name         string   `json:"name"`
+	Keys         int      `json:"keys"`
+	CurrentIndex int      `json:"currentIndex"`
+	UsedCount    int      `json:"usedCount"`
+	Used         []int    `json:"used,omitempty"` // indices whose Private.Used is set
+	PKHs         []string `json:"pkhs"`
+}
+
+// ExportKeychainArchive encrypts several named key chains into one archive
+// using scrypt with the standard parameters and aes-128-ctr (as in
+// ExportKeystoreV3). Every key must have its private half.
+func ExportKeychainArchive(chains map[string]*KeyChain, password []byte) ([]byte, error) {
+	return ExportKeychainArchiveWithScrypt(chains, password, StandardScryptN, StandardScryptP)
+}
+
+// ExportKeychainArchiveWithScrypt is ExportKeychainArchive with explicit
+// scrypt N and P.
+func ExportKeychainArchiveWithScrypt(chains map[string]*KeyChain, password []byte, scryptN, scryptP int) ([]byte, error) {
+	names := make([]string, 0, len(chains))
+	for name := range chains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	archive := keychainArchive{Version: keychainArchiveVersion}
+	var keys []byte
+	for _, name := range names {
+		entry, chainKeys, err := archiveEntry(name, chains[name])
+		if err != nil {
+			return nil, err
+		}
+		archive.Manifest = append(archive.Manifest, entry)
+		keys = append(keys, chainKeys...)
+	}
+
+	manifest, err := json.Marshal(archive.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	digest := Keccak256(manifest)
+	plain := append(digest[:], keys...)
+
+	crypto, err := keystoreEncrypt(plain, password, scryptN, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	archive.Crypto = crypto
+	return json.Marshal(archive)
+}
+
+// archiveEntry builds the manifest entry and key material of one chain.
+func archiveEntry(name string, kc *KeyChain) (keychainArchiveEntry, []byte, error) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	entry := keychainArchiveEntry{
+		Name:         name,
+		Keys:         len(kc.Keys),
+		CurrentIndex: kc.CurrentIndex,
+		UsedCount:    kc.UsedCount,
+		PKHs:         make([]string, len(kc.Keys)),
+	}
+	keys := make([]byte, 0, len(kc.Keys)*PrivateKeySize)
+	for i, kp := range kc.Keys {
+		if kp.Private == nil {
+			return entry, nil, fmt.Errorf("%w: chain %q key %d has no private key", ErrInvalidPrivateKey, name, i)
+		}
+		if kp.Private.Used {
+			entry.Used = append(entry.Used, i)
+		}
+		pkh := kp.Public.Hash()
+		entry.PKHs[i] = hex.EncodeToString(pkh[:])
+		keys = append(keys, kp.Private.Bytes()...)
+	}
+	return entry, keys, nil
+}
+
+// ImportKeychainArchive decrypts an archive from ExportKeychainArchive and
+// restores each chain with its CurrentIndex, UsedCount, and per-key Used
+// flags. Public keys are recomputed and checked against the manifest PKHs.
+func ImportKeychainArchive(data, password []byte) (map[string]*KeyChain, error) {
+	var archive keychainArchive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeystoreFormat, err)
+	}
+	if archive.Version != keychainArchiveVersion {
+		return nil, ErrKeystoreFormat
+	}
+
+	total := 0
+	for _, entry := range archive.Manifest {
+		if entry.Keys < 0 || len(entry.PKHs) != entry.Keys ||
+			entry.CurrentIndex < 0 || entry.CurrentIndex > entry.Keys {
+			return nil, ErrKeystoreFormat
+		}
+		total += entry.Keys
+	}
+
+	plain, err := keystoreDecrypt(archive.Crypto, password)
+	if err != nil {
+		return nil, err
+	}
+	if len(plain) != HashSize+total*PrivateKeySize {
+		return nil, ErrKeystoreFormat
+	}
+	manifest, err := json.Marshal(archive.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	if digest := Keccak256(manifest); !ConstantTimeEqualHash(digest, [32]byte(plain[:HashSize])) {
+		return nil, ErrKeystoreDecrypt
+	}
+	plain = plain[HashSize:]
+
+	chains := make(map[string]*KeyChain, len(archive.Manifest))
+	for _, entry := range archive.Manifest {
+		if _, dup := chains[entry.Name]; dup {
+			return nil, ErrKeystoreFormat
+		}
+		kc := &KeyChain{
+			Keys:         make([]*KeyPair, entry.Keys),
+			CurrentIndex: entry.CurrentIndex,
+			UsedCount:    entry.UsedCount,
+		}
+		for i := range kc.Keys {
+			priv := &PrivateKey{}
+			if err := priv.FromBytes(plain[:PrivateKeySize]); err != nil {
+				return nil, err
+			}
+			plain = plain[PrivateKeySize:]
+			priv.Used = slices.Contains(entry.Used, i)
+
+			pub := priv.DerivePublic()
+			pkh := pub.Hash()
+			if entry.PKHs[i] != hex.EncodeToString(pkh[:]) {
+				return nil, ErrKeystoreDecrypt
+			}
+			kc.Keys[i] = &KeyPair{Private: priv, Public: pub}
+		}
+		chains[entry.Name] = kc
+	}
+	return chains, nil
+}
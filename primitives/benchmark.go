@@ -0,0 +1,68 @@
+package primitives
+
+import "time"
+
+// BenchmarkResult holds per-operation timings and the fixed object sizes.
+type BenchmarkResult struct {
+	// Iterations is the number of operations averaged for each timing
+	Iterations int
+
+	// Per-operation averages. Sign includes generating a fresh key pair,
+	// since each key signs only once.
+	KeyGen time.Duration
+	Sign   time.Duration
+	Verify time.Duration
+	PKH    time.Duration
+
+	// Sizes in bytes
+	PrivateKeySize    int
+	PublicKeySize     int
+	SignatureSize     int
+	PublicKeyHashSize int
+}
+
+// RunBenchmarks times key generation, signing, verification, and public key
+// hashing, averaging over iterations operations each (minimum 1).
+func RunBenchmarks(iterations int) BenchmarkResult {
+	if iterations < 1 {
+		iterations = 1
+	}
+	n := time.Duration(iterations)
+	res := BenchmarkResult{
+		Iterations:        iterations,
+		PrivateKeySize:    PrivateKeySize,
+		PublicKeySize:     PublicKeySize,
+		SignatureSize:     SignatureSize,
+		PublicKeyHashSize: PublicKeyHashSize,
+	}
+
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		_, _ = GenerateKeyPair()
+	}
+	res.KeyGen = time.Since(start) / n
+
+	message := Keccak256([]byte("Benchmark message"))
+	start = time.Now()
+	for i := 0; i < iterations; i++ {
+		kp, _ := GenerateKeyPair()
+		_, _ = Sign(kp.Private, message)
+	}
+	res.Sign = time.Since(start) / n
+
+	kp, _ := GenerateKeyPair()
+	sig, _ := Sign(kp.Private, message)
+	start = time.Now()
+	for i := 0; i < iterations; i++ {
+		Verify(kp.Public, message, sig)
+	}
+	res.Verify = time.Since(start) / n
+
+	start = time.Now()
+	for i := 0; i < iterations; i++ {
+		_ = kp.Public.Hash()
+	}
+	res.PKH = time.Since(start) / n
+
+	return res
+}
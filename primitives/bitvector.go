@@ -0,0 +1,31 @@
+package primitives
+
+// BitVector holds the 256 bits of a message, expanded once so hot loops can
+// read bit i with a single array index instead of recomputing byte and shift
+// positions. Bit ordering is identical to GetBit: bit 0 is the most
+// significant bit of the first byte.
+type BitVector [KeyBits]uint8
+
+// NewBitVector expands a 32-byte message into a BitVector.
+func NewBitVector(message [32]byte) BitVector {
+	var bv BitVector
+	for b := 0; b < 32; b++ {
+		v := message[b]
+		for j := 0; j < 8; j++ {
+			bv[b*8+j] = (v >> (7 - j)) & 1
+		}
+	}
+	return bv
+}
+
+// Bit returns bit i (0-255), equal to GetBit(message, i).
+func (bv *BitVector) Bit(i int) int {
+	return int(bv[i])
+}
+
+// Iterate calls fn for each bit position in order.
+func (bv *BitVector) Iterate(fn func(i, bit int)) {
+	for i, bit := range bv {
+		fn(i, int(bit))
+	}
+}
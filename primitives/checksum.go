@@ -0,0 +1,80 @@
+package primitives
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+// ChecksumSize is the size of the CRC32 suffix added by BytesWithChecksum
+const ChecksumSize = 4
+
+// ErrCorrupted indicates serialized data whose CRC32 checksum does not match
+var ErrCorrupted = errors.New("lamport: data corrupted (checksum mismatch)")
+
+// appendChecksum returns payload followed by its big-endian CRC32 (IEEE).
+func appendChecksum(payload []byte) []byte {
+	return binary.BigEndian.AppendUint32(payload, crc32.ChecksumIEEE(payload))
+}
+
+// checkChecksum returns the payload of data if data is size payload bytes
+// plus a matching CRC32. A wrong length returns lengthErr.
+func checkChecksum(data []byte, size int, lengthErr error) ([]byte, error) {
+	if len(data) != size+ChecksumSize {
+		return nil, lengthErr
+	}
+	payload := data[:size]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(data[size:]) {
+		return nil, ErrCorrupted
+	}
+	return payload, nil
+}
+
+// BytesWithChecksum returns Bytes followed by a 4-byte CRC32 of it, for
+// storage where corruption should be detected before any crypto runs.
+// Bytes remains the wire format.
+func (pk *PublicKey) BytesWithChecksum() []byte {
+	return appendChecksum(pk.Bytes())
+}
+
+// FromBytesWithChecksum parses BytesWithChecksum output. It returns
+// ErrCorrupted if the checksum does not match.
+func (pk *PublicKey) FromBytesWithChecksum(data []byte) error {
+	payload, err := checkChecksum(data, PublicKeySize, ErrInvalidPublicKey)
+	if err != nil {
+		return err
+	}
+	return pk.FromBytes(payload)
+}
+
+// BytesWithChecksum returns Bytes followed by a 4-byte CRC32 of it.
+func (sig *Signature) BytesWithChecksum() []byte {
+	return appendChecksum(sig.Bytes())
+}
+
+// FromBytesWithChecksum parses BytesWithChecksum output. It returns
+// ErrCorrupted if the checksum does not match.
+func (sig *Signature) FromBytesWithChecksum(data []byte) error {
+	payload, err := checkChecksum(data, SignatureSize, ErrInvalidSignature)
+	if err != nil {
+		return err
+	}
+	return sig.FromBytes(payload)
+}
+
+// BytesWithChecksum returns Bytes followed by a 4-byte CRC32 of it.
+// The CRC detects accidental corruption only; it is not a MAC and does not
+// protect the key (see ExportKeystoreV3 for storage at rest).
+func (priv *PrivateKey) BytesWithChecksum() []byte {
+	return appendChecksum(priv.Bytes())
+}
+
+// FromBytesWithChecksum parses BytesWithChecksum output. It returns
+// ErrCorrupted if the checksum does not match.
+func (priv *PrivateKey) FromBytesWithChecksum(data []byte) error {
+	payload, err := checkChecksum(data, PrivateKeySize, ErrInvalidPrivateKey)
+	if err != nil {
+		return err
+	}
+	return priv.FromBytes(payload)
+}
@@ -0,0 +1,102 @@
+package primitives
+
+// CompactSignatureSize is the size of a CompactSignature: 256 preimages plus
+// a MerkleDepth-entry path for each (8,192 + 73,728 bytes).
+const CompactSignatureSize = SignatureSize + KeyBits*MerkleDepth*HashSize // 81920
+
+// CompactSignature bundles a signature with the Merkle paths of each revealed
+// leaf against PublicKey.CompressedRoot, so a verifier storing only the
+// 32-byte root can check it with VerifyCompact.
+//
+// Size tradeoff: the bundle is 80 KB, versus 24 KB for a signature plus the
+// full public key. It saves verifier storage (32 bytes instead of 16 KB per
+// key), not bandwidth; send the full key when bandwidth matters more.
+type CompactSignature struct {
+	// Preimages are the revealed preimages, as in Signature
+	Preimages [KeyBits][PreimageSize]byte
+
+	// Proofs[i] is LeafProof(i, bit i of the message)
+	Proofs [KeyBits][MerkleDepth][HashSize]byte
+}
+
+// NewCompactSignature bundles sig with the Merkle paths for message from pub.
+// It returns ErrInvalidSignature if sig does not verify against pub.
+func NewCompactSignature(pub *PublicKey, message [32]byte, sig *Signature) (*CompactSignature, error) {
+	if !Verify(pub, message, sig) {
+		return nil, ErrInvalidSignature
+	}
+
+	// Build every level once rather than once per proof
+	levels := [][][32]byte{pub.leaves()}
+	for len(levels[len(levels)-1]) > 1 {
+		levels = append(levels, merkleLevel(levels[len(levels)-1]))
+	}
+
+	cs := &CompactSignature{Preimages: sig.Preimages}
+	bits := NewBitVector(message)
+	for i := 0; i < KeyBits; i++ {
+		idx := 2*i + int(bits[i])
+		for d := 0; d < MerkleDepth; d++ {
+			cs.Proofs[i][d] = levels[d][idx^1]
+			idx /= 2
+		}
+	}
+	return cs, nil
+}
+
+// VerifyCompact verifies a CompactSignature for message against the public
+// key's compressed root.
+func VerifyCompact(root [32]byte, message [32]byte, cs *CompactSignature) bool {
+	if cs == nil {
+		return false
+	}
+	sig := Signature{Preimages: cs.Preimages}
+	if !sig.IsWellFormed() {
+		return false
+	}
+
+	bits := NewBitVector(message)
+	proof := make([][32]byte, MerkleDepth)
+	for i := 0; i < KeyBits; i++ {
+		for d := range proof {
+			proof[d] = cs.Proofs[i][d]
+		}
+		if !VerifyPreimageProof(root, i, int(bits[i]), cs.Preimages[i], proof) {
+			return false
+		}
+	}
+	return true
+}
+
+// Bytes serializes the compact signature: the Signature.Bytes layout
+// followed by each position's path, leaf level first.
+func (cs *CompactSignature) Bytes() []byte {
+	out := make([]byte, CompactSignatureSize)
+	for i := 0; i < KeyBits; i++ {
+		copy(out[i*32:(i+1)*32], cs.Preimages[i][:])
+	}
+	off := SignatureSize
+	for i := 0; i < KeyBits; i++ {
+		for d := 0; d < MerkleDepth; d++ {
+			off += copy(out[off:], cs.Proofs[i][d][:])
+		}
+	}
+	return out
+}
+
+// FromBytes deserializes a compact signature from bytes.
+func (cs *CompactSignature) FromBytes(data []byte) error {
+	if len(data) != CompactSignatureSize {
+		return ErrInvalidSignature
+	}
+	for i := 0; i < KeyBits; i++ {
+		copy(cs.Preimages[i][:], data[i*32:(i+1)*32])
+	}
+	off := SignatureSize
+	for i := 0; i < KeyBits; i++ {
+		for d := 0; d < MerkleDepth; d++ {
+			off += copy(cs.Proofs[i][d][:], data[off:off+HashSize])
+		}
+	}
+	return nil
+}
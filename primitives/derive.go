@@ -0,0 +1,72 @@
+package primitives
+
+import (
+	"errors"
+	"strconv"
+)
+
+// DeriveKeyPair derives an independent Lamport key pair for a labeled path
+// (e.g. "safe/0x1234/42") from a single backed-up master seed.
+//
+// The per-path seed is keccak256(masterSeed || path). Different paths yield
+// unrelated keys; the same path always reproduces the same key. Because each
+// derived key is still one-time, callers should include a counter in the path
+// (or use a KeyChain) rather than re-deriving a path that has already signed.
+func DeriveKeyPair(masterSeed [32]byte, path string) (*KeyPair, error) {
+	seed := Keccak256Multi(masterSeed[:], []byte(path))
+	return GenerateKeyPairFromSeed(seed)
+}
+
+// DeriveKeyChain derives a key chain of n keys from a master seed. Key i uses
+// the path "chain/<i>" (decimal), so DeriveKeyPair(masterSeed, "chain/3")
+// reproduces the fourth key.
+func DeriveKeyChain(masterSeed [32]byte, n int) (*KeyChain, error) {
+	if n <= 0 {
+		return nil, errors.New("lamport: numKeys must be positive")
+	}
+
+	chain := &KeyChain{Keys: make([]*KeyPair, n)}
+	for i := 0; i < n; i++ {
+		kp, err := DeriveKeyPair(masterSeed, chainPath(i))
+		if err != nil {
+			return nil, err
+		}
+		chain.Keys[i] = kp
+	}
+	return chain, nil
+}
+
+// VerifyRecovery derives the key pair for a restored seed and reports whether
+// its PKH matches the expected (e.g. on-chain) value, so a restore can be
+// checked before the key is used.
+func VerifyRecovery(seed [32]byte, expectedPKH [32]byte) (bool, error) {
+	kp, err := GenerateKeyPairFromSeed(seed)
+	if err != nil {
+		return false, err
+	}
+	return kp.Public.Hash() == expectedPKH, nil
+}
+
+// VerifyChainRecovery derives the chain keys from a restored master seed (see
+// DeriveKeyChain) and reports whether key i hashes to expectedPKHs[i] for
+// every i. An empty expectation list never matches.
+func VerifyChainRecovery(masterSeed [32]byte, expectedPKHs [][32]byte) (bool, error) {
+	if len(expectedPKHs) == 0 {
+		return false, nil
+	}
+	for i, expected := range expectedPKHs {
+		kp, err := DeriveKeyPair(masterSeed, chainPath(i))
+		if err != nil {
+			return false, err
+		}
+		if kp.Public.Hash() != expected {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// chainPath returns the derivation path of the i-th key of a derived chain.
+func chainPath(i int) string {
+	return "chain/" + strconv.Itoa(i)
+}
@@ -0,0 +1,67 @@
+package primitives
+
+const (
+	// EIP712DomainType is the canonical EIP-712 domain type string.
+	EIP712DomainType = "EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"
+
+	// ThresholdMessageType is the EIP-712 struct type signed in threshold mode.
+	// A Solidity verifier reproduces the struct hash as
+	//
+	//	keccak256(abi.encode(keccak256(bytes(ThresholdMessageType)), safeTxHash, nextPKH))
+	ThresholdMessageType = "LamportThresholdMessage(bytes32 safeTxHash,bytes32 nextPKH)"
+
+	// EIP712DomainName is the domain name used for threshold messages
+	EIP712DomainName = "LamportThreshold"
+
+	// EIP712DomainVersion is the domain version used for threshold messages
+	EIP712DomainVersion = "1"
+)
+
+var (
+	eip712DomainTypeHash     = Keccak256([]byte(EIP712DomainType))
+	thresholdMessageTypeHash = Keccak256([]byte(ThresholdMessageType))
+)
+
+// ComputeThresholdMessageEIP712 computes the threshold message as an EIP-712
+// typed-data digest instead of the abi.encodePacked form:
+//
+//	domainSeparator = keccak256(abi.encode(
+//	    keccak256(EIP712DomainType),
+//	    keccak256("LamportThreshold"), keccak256("1"),
+//	    chainId, moduleAddress))
+//	structHash = keccak256(abi.encode(keccak256(ThresholdMessageType), safeTxHash, nextPKH))
+//	message    = keccak256(0x19 || 0x01 || domainSeparator || structHash)
+//
+// The result is signed with Lamport exactly like ComputeThresholdMessage.
+func ComputeThresholdMessageEIP712(safeTxHash, nextPKH [32]byte, moduleAddress [20]byte, chainID uint64) [32]byte {
+	domain := eip712DomainSeparator(EIP712DomainName, EIP712DomainVersion, ChainIDFromUint64(chainID), moduleAddress)
+	structHash := Keccak256Multi(thresholdMessageTypeHash[:], safeTxHash[:], nextPKH[:])
+	return eip712Digest(domain, structHash)
+}
+
+// ComputeEIP712DomainSeparator computes the canonical EIP-712 domain
+// separator for an EIP712Domain(name, version, chainId, verifyingContract),
+// as returned by Solidity's EIP712._domainSeparatorV4 and expected by Safe
+// and wallet tooling.
+//
+// Use it when the message is an EIP-712 typed-data digest (see
+// ComputeThresholdMessageEIP712). ComputeDomainSeparator is the packed
+// keccak256(moduleAddress || chainId) form used by ComputeThresholdMessage;
+// the two are not interchangeable.
+func ComputeEIP712DomainSeparator(name, version string, chainID uint64, verifyingContract [20]byte) [32]byte {
+	return eip712DomainSeparator(name, version, ChainIDFromUint64(chainID), verifyingContract)
+}
+
+// eip712DomainSeparator hashes an EIP712Domain struct with all four fields.
+func eip712DomainSeparator(name, version string, chainID [32]byte, verifyingContract [20]byte) [32]byte {
+	nameHash := Keccak256([]byte(name))
+	versionHash := Keccak256([]byte(version))
+	var contract [32]byte // address is left-padded to 32 bytes in abi.encode
+	copy(contract[12:], verifyingContract[:])
+	return Keccak256Multi(eip712DomainTypeHash[:], nameHash[:], versionHash[:], chainID[:], contract[:])
+}
+
+// eip712Digest computes keccak256("\x19\x01" || domainSeparator || structHash).
+func eip712Digest(domainSeparator, structHash [32]byte) [32]byte {
+	return Keccak256Multi([]byte{0x19, 0x01}, domainSeparator[:], structHash[:])
+}
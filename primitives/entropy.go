@@ -0,0 +1,111 @@
+package primitives
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"sync"
+)
+
+// DefaultReseedInterval is the number of output bytes a ReseedingReader
+// produces before mixing in fresh entropy (64 key pairs' worth of preimages).
+const DefaultReseedInterval = 64 * PrivateKeySize
+
+// EntropySource is a random source that can refresh its internal state.
+// It is an io.Reader, so it can be passed to GenerateKeyPairFromReader,
+// threshold.GenerateSharesFromReader, and anything else taking a reader.
+type EntropySource interface {
+	io.Reader
+
+	// Reseed mixes fresh entropy into the source's state.
+	Reseed() error
+}
+
+// ReseedingReader is a keccak256 counter-mode generator that periodically
+// mixes in fresh entropy. After every Read its key is ratcheted forward, so
+// compromising the current state does not reveal previously emitted bytes.
+// It is safe for concurrent use.
+type ReseedingReader struct {
+	mu       sync.Mutex
+	fresh    io.Reader
+	interval int
+	key      [32]byte
+	counter  uint64
+	block    [HashSize]byte
+	off      int
+	emitted  int
+}
+
+var _ EntropySource = (*ReseedingReader)(nil)
+
+// NewReseedingReader creates a ReseedingReader that draws fresh entropy from
+// fresh (crypto/rand if nil) every interval output bytes
+// (DefaultReseedInterval if interval <= 0). The reader is seeded immediately.
+func NewReseedingReader(fresh io.Reader, interval int) (*ReseedingReader, error) {
+	if fresh == nil {
+		fresh = rand.Reader
+	}
+	if interval <= 0 {
+		interval = DefaultReseedInterval
+	}
+	r := &ReseedingReader{fresh: fresh, interval: interval}
+	if err := r.Reseed(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reseed sets key = keccak256(key || 32 fresh bytes) and restarts the counter.
+func (r *ReseedingReader) Reseed() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reseed()
+}
+
+func (r *ReseedingReader) reseed() error {
+	var fresh [32]byte
+	if _, err := io.ReadFull(r.fresh, fresh[:]); err != nil {
+		return err
+	}
+	r.key = Keccak256Multi(r.key[:], fresh[:])
+	r.counter = 0
+	r.off = HashSize
+	r.emitted = 0
+	return nil
+}
+
+// Read fills p with generator output, reseeding whenever the interval is
+// reached. It only returns an error if the fresh entropy source fails.
+func (r *ReseedingReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for n < len(p) {
+		if r.emitted >= r.interval {
+			if err := r.reseed(); err != nil {
+				return n, err
+			}
+		}
+		if r.off == HashSize {
+			var buf [40]byte
+			copy(buf[:32], r.key[:])
+			binary.BigEndian.PutUint64(buf[32:], r.counter)
+			r.block = Keccak256(buf[:])
+			r.counter++
+			r.off = 0
+		}
+		c := copy(p[n:], r.block[r.off:])
+		r.off += c
+		r.emitted += c
+		n += c
+	}
+
+	// Ratchet: a 33-byte input cannot collide with the 40-byte block inputs.
+	// The last block is zeroed too, or it would leak the tail of p.
+	r.key = Keccak256Multi(r.key[:], []byte{0x01})
+	r.counter = 0
+	r.block = [HashSize]byte{}
+	r.off = HashSize
+	return n, nil
+}
@@ -0,0 +1,117 @@
+package primitives
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrInvalidEnvelope indicates a malformed SignedEnvelope encoding
+var ErrInvalidEnvelope = errors.New("lamport: invalid signed envelope")
+
+// SignedEnvelope carries a signature together with chain-of-custody
+// metadata such as signer ID, timestamp, or purpose.
+//
+// Metadata is NOT authenticated: it is outside the signed message, so anyone
+// handling the envelope can change it without invalidating the signature.
+// Fold any value that must be trusted into the message before signing
+// (e.g. with MessageBuilder).
+type SignedEnvelope struct {
+	Signature *Signature
+	Metadata  map[string]string
+}
+
+// Verify verifies the inner signature for message against pub. Metadata
+// plays no part in verification.
+func (e *SignedEnvelope) Verify(pub *PublicKey, message [32]byte) bool {
+	if e == nil {
+		return false
+	}
+	return Verify(pub, message, e.Signature)
+}
+
+// Bytes serializes the envelope: the Signature.Bytes layout, a 4-byte
+// big-endian entry count, then each metadata entry in key order as a
+// length-prefixed key and value (4-byte big-endian lengths).
+func (e *SignedEnvelope) Bytes() []byte {
+	keys := make([]string, 0, len(e.Metadata))
+	size := SignatureSize + 4
+	for k, v := range e.Metadata {
+		keys = append(keys, k)
+		size += 8 + len(k) + len(v)
+	}
+	sort.Strings(keys)
+
+	out := make([]byte, 0, size)
+	out = append(out, e.Signature.Bytes()...)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(keys)))
+	for _, k := range keys {
+		out = appendLengthPrefixed(out, k)
+		out = appendLengthPrefixed(out, e.Metadata[k])
+	}
+	return out
+}
+
+// FromBytes deserializes an envelope from Bytes output. On error e is left
+// unchanged.
+func (e *SignedEnvelope) FromBytes(data []byte) error {
+	if len(data) < SignatureSize+4 {
+		return ErrInvalidEnvelope
+	}
+	sig := &Signature{}
+	if err := sig.FromBytes(data[:SignatureSize]); err != nil {
+		return err
+	}
+	data = data[SignatureSize:]
+	count := binary.BigEndian.Uint32(data)
+	data = data[4:]
+	// Every entry takes at least 8 bytes, which bounds the allocation
+	if uint64(count)*8 > uint64(len(data)) {
+		return ErrInvalidEnvelope
+	}
+
+	metadata := make(map[string]string, count)
+	for i := uint32(0); i < count; i++ {
+		k, rest, err := readLengthPrefixed(data)
+		if err != nil {
+			return err
+		}
+		v, rest, err := readLengthPrefixed(rest)
+		if err != nil {
+			return err
+		}
+		if _, dup := metadata[k]; dup {
+			return fmt.Errorf("%w: duplicate metadata key %q", ErrInvalidEnvelope, k)
+		}
+		metadata[k] = v
+		data = rest
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrInvalidEnvelope, len(data))
+	}
+
+	e.Signature = sig
+	e.Metadata = metadata
+	return nil
+}
+
+// appendLengthPrefixed appends s with a 4-byte big-endian length prefix.
+func appendLengthPrefixed(out []byte, s string) []byte {
+	out = binary.BigEndian.AppendUint32(out, uint32(len(s)))
+	return append(out, s...)
+}
+
+// readLengthPrefixed reads a string written by appendLengthPrefixed and
+// returns it with the remaining data.
+func readLengthPrefixed(data []byte) (string, []byte, error) {
+	if len(data) < 4 {
+		return "", nil, ErrInvalidEnvelope
+	}
+	n := binary.BigEndian.Uint32(data)
+	data = data[4:]
+	if uint64(n) > uint64(len(data)) {
+		return "", nil, ErrInvalidEnvelope
+	}
+	return string(data[:n]), data[n:], nil
+}
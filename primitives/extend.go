@@ -0,0 +1,108 @@
+package primitives
+
+// SetAutoExtend makes the chain append batch freshly generated keys whenever
+// Remaining() drops to threshold or below, so long-running signers never hit
+// ErrKeyChainExhausted. threshold is raised to 1 so the current key always
+// has a next PKH to rotate to. A batch <= 0 disables auto-extension.
+//
+// New keys come from crypto/rand, even for chains built by DeriveKeyChain,
+// and are held in memory even for chains built by NewKeyChainFromSigners.
+// Use OnExtend to publish their PKHs for on-chain pre-commitment.
+func (kc *KeyChain) SetAutoExtend(threshold, batch int) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	if threshold < 1 {
+		threshold = 1
+	}
+	kc.extendThreshold = threshold
+	kc.extendBatch = batch
+}
+
+// OnExtend registers cb to receive the PKHs of keys appended by
+// auto-extension, in chain order. cb runs after the chain is unlocked and
+// may call back into it; publications are still delivered one at a time.
+func (kc *KeyChain) OnExtend(cb func(pkhs [][32]byte)) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	kc.onExtend = cb
+}
+
+// maybeExtend appends a batch of keys if auto-extension is enabled and the
+// chain is at or below its threshold. kc.mu must be held.
+func (kc *KeyChain) maybeExtend() error {
+	if kc.extendBatch <= 0 || kc.remaining() > kc.extendThreshold {
+		return nil
+	}
+
+	keys := make([]*KeyPair, kc.extendBatch)
+	pkhs := make([][32]byte, kc.extendBatch)
+	for i := range keys {
+		kp, err := GenerateKeyPair()
+		if err != nil {
+			return err
+		}
+		keys[i] = kp
+		pkhs[i] = kp.Public.Hash()
+	}
+	kc.Keys = append(kc.Keys, keys...)
+	if cb := kc.onExtend; cb != nil {
+		kc.events = append(kc.events, func() { cb(pkhs) })
+	}
+	return nil
+}
+
+// OnLowKeys registers cb to be called when Remaining() drops to threshold or
+// below during Advance (including via SignWithKeyChain). It fires once per
+// crossing: after firing it is re-armed only once the chain is back above
+// threshold, e.g. through auto-extension. cb runs after the chain is
+// unlocked and may call back into it.
+func (kc *KeyChain) OnLowKeys(threshold int, cb func(remaining int)) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	kc.lowThreshold = threshold
+	kc.onLow = cb
+	kc.lowFired = false
+}
+
+// checkLowKeys fires or re-arms the OnLowKeys callback. kc.mu must be held.
+func (kc *KeyChain) checkLowKeys() {
+	if kc.onLow == nil {
+		return
+	}
+	remaining := kc.remaining()
+	if remaining > kc.lowThreshold {
+		kc.lowFired = false
+		return
+	}
+	if !kc.lowFired {
+		kc.lowFired = true
+		cb := kc.onLow
+		kc.events = append(kc.events, func() { cb(remaining) })
+	}
+}
+
+// unlockAndNotify releases kc.mu and then runs the callbacks queued while it
+// was held, in order. Only one goroutine delivers at a time; callbacks queued
+// meanwhile (including by a callback calling back into the chain) are picked
+// up by that goroutine. kc.mu must be held.
+func (kc *KeyChain) unlockAndNotify() {
+	if kc.notifying || len(kc.events) == 0 {
+		kc.mu.Unlock()
+		return
+	}
+	kc.notifying = true
+	for len(kc.events) > 0 {
+		events := kc.events
+		kc.events = nil
+		kc.mu.Unlock()
+		for _, ev := range events {
+			ev()
+		}
+		kc.mu.Lock()
+	}
+	kc.notifying = false
+	kc.mu.Unlock()
+}
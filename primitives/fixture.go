@@ -0,0 +1,49 @@
+package primitives
+
+import (
+	"errors"
+	"io"
+)
+
+// SignedFixture is a correlated (public key, message, signature) triple
+// for testing and benchmarking verifiers.
+type SignedFixture struct {
+	Public    *PublicKey
+	Message   [32]byte
+	Signature *Signature
+}
+
+// GenerateSignedFixture generates a key pair and a random message from r
+// and returns the public key, message, and a valid signature.
+func GenerateSignedFixture(r io.Reader) (pub *PublicKey, message [32]byte, sig *Signature, err error) {
+	fixtures, err := GenerateSignedFixtures(1, r)
+	if err != nil {
+		return nil, message, nil, err
+	}
+	f := fixtures[0]
+	return f.Public, f.Message, f.Signature, nil
+}
+
+// GenerateSignedFixtures returns n fixtures for independent random messages
+// from r, all signed by one key pair generated from r. Signing bypasses the
+// one-time check, so the key is only fit for tests: n signatures reveal
+// both preimages at most positions.
+func GenerateSignedFixtures(n int, r io.Reader) ([]SignedFixture, error) {
+	if n < 1 {
+		return nil, errors.New("lamport: fixture count must be positive")
+	}
+	kp, err := GenerateKeyPairFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := make([]SignedFixture, n)
+	for i := range fixtures {
+		if _, err := io.ReadFull(r, fixtures[i].Message[:]); err != nil {
+			return nil, err
+		}
+		fixtures[i].Public = kp.Public
+		fixtures[i].Signature = signUnsafe(kp.Private, fixtures[i].Message)
+	}
+	return fixtures, nil
+}
@@ -0,0 +1,64 @@
+package primitives
+
+import (
+	"errors"
+	"hash"
+	"sync/atomic"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// HashFunc returns a new keccak256 hasher.
+type HashFunc func() hash.Hash
+
+// ErrHashFuncLocked indicates SetHashFunc was called after keys were generated
+var ErrHashFuncLocked = errors.New("lamport: hash function cannot change after keys are generated")
+
+var (
+	// hashFunc backs Keccak256 and every other hash in this package
+	hashFunc HashFunc = sha3.NewLegacyKeccak256
+
+	// hashLocked is set by the first key generation and locks hashFunc
+	hashLocked atomic.Bool
+)
+
+// SetHashFunc replaces the keccak256 implementation used throughout this
+// package, so integrators can confirm it matches their chain's. A nil f
+// restores the default (golang.org/x/crypto/sha3).
+//
+// It must be called once, at init, before any key or threshold share is
+// generated; afterwards it returns ErrHashFuncLocked, since existing keys
+// would no longer verify. It is not safe to call concurrently with hashing.
+//
+// The precompile package always uses keccak256, as the EVM does, so keys
+// generated under any other hash never verify there.
+func SetHashFunc(f HashFunc) error {
+	if hashLocked.Load() {
+		return ErrHashFuncLocked
+	}
+	setHashFunc(f)
+	return nil
+}
+
+// setHashFunc installs f (nil for the default) and recomputes the hashes
+// cached at package init.
+func setHashFunc(f HashFunc) {
+	if f == nil {
+		f = sha3.NewLegacyKeccak256
+	}
+	hashFunc = f
+	eip712DomainTypeHash = Keccak256([]byte(EIP712DomainType))
+	thresholdMessageTypeHash = Keccak256([]byte(ThresholdMessageType))
+}
+
+// newHasher returns a hasher from the configured HashFunc.
+func newHasher() hash.Hash {
+	return hashFunc()
+}
+
+// LockHashFunc locks the hash function, so later SetHashFunc calls fail.
+// Key generation in this package calls it; packages that derive keys or
+// shares with Keccak256 themselves, such as threshold and hors, call it too.
+func LockHashFunc() {
+	hashLocked.Store(true)
+}
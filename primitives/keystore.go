@@ -0,0 +1,293 @@
+package primitives
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Keystore v3 scrypt parameters, matching go-ethereum's defaults.
+const (
+	// StandardScryptN is the N parameter of scrypt for interactive use (256 MB, ~1s)
+	StandardScryptN = 1 << 18
+
+	// StandardScryptP is the P parameter of scrypt for interactive use
+	StandardScryptP = 1
+
+	// LightScryptN is the N parameter of scrypt for low-memory devices (4 MB, ~100ms)
+	LightScryptN = 1 << 12
+
+	// LightScryptP is the P parameter of scrypt for low-memory devices
+	LightScryptP = 6
+
+	keystoreVersion = 3
+	scryptR         = 8
+	scryptDKLen     = 32
+
+	// Upper bounds on imported KDF parameters, so a crafted keystore cannot
+	// demand unbounded memory or CPU before the MAC is checked. They admit
+	// every file ExportKeystoreV3 and go-ethereum write.
+	maxScryptMemory  = 1 << 30 // bytes: 128 * n * r
+	maxScryptP       = 16
+	maxPBKDF2Iter    = 10_000_000
+	maxKeystoreDKLen = 64
+
+	// keystoreUsedFlag follows the private key in the plaintext of a key
+	// whose Used flag is set.
+	keystoreUsedFlag = 0x01
+)
+
+var (
+	// ErrKeystoreDecrypt indicates a wrong password or a tampered keystore (MAC mismatch)
+	ErrKeystoreDecrypt = errors.New("lamport: could not decrypt keystore (wrong password or corrupted file)")
+
+	// ErrKeystoreFormat indicates the keystore JSON is malformed or unsupported
+	ErrKeystoreFormat = errors.New("lamport: invalid or unsupported keystore")
+)
+
+// keystoreV3 is the Ethereum Web3 Secret Storage (v3) JSON layout.
+//
+// DEVIATION: Ethereum stores a 32-byte ECDSA key as the secret and includes
+// an "address". Here the secret is the 16,384-byte Lamport private key
+// (PrivateKey.Bytes) and "pkh" replaces "address". If the key has signed,
+// one keystoreUsedFlag byte follows it, so the Used flag is covered by the
+// MAC like the key itself. The cipher, KDF, and MAC are unchanged, so
+// generic v3 tooling can decrypt the file, but will not interpret the
+// plaintext as an Ethereum key.
+type keystoreV3 struct {
+	PKH     string         `json:"pkh"`
+	Crypto  keystoreCrypto `json:"crypto"`
+	ID      string         `json:"id"`
+	Version int            `json:"version"`
+}
+
+type keystoreCrypto struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams keystoreCipherParams   `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+type keystoreCipherParams struct {
+	IV string `json:"iv"`
+}
+
+// ExportKeystoreV3 encrypts the private key into Ethereum keystore v3 JSON
+// using scrypt with the standard parameters and aes-128-ctr. The key's Used
+// flag is preserved.
+func ExportKeystoreV3(kp *KeyPair, password []byte) ([]byte, error) {
+	return ExportKeystoreV3WithScrypt(kp, password, StandardScryptN, StandardScryptP)
+}
+
+// ExportKeystoreV3WithScrypt is ExportKeystoreV3 with explicit scrypt N and P.
+func ExportKeystoreV3WithScrypt(kp *KeyPair, password []byte, scryptN, scryptP int) ([]byte, error) {
+	plain := kp.Private.Bytes()
+	if kp.Private.Used {
+		plain = append(plain, keystoreUsedFlag)
+	}
+	crypto, err := keystoreEncrypt(plain, password, scryptN, scryptP)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	pkh := kp.Public.Hash()
+	return json.Marshal(keystoreV3{
+		PKH:     hex.EncodeToString(pkh[:]),
+		Crypto:  crypto,
+		ID:      id,
+		Version: keystoreVersion,
+	})
+}
+
+// keystoreEncrypt encrypts plain with aes-128-ctr under a scrypt-derived key
+// and returns the v3 crypto section, MAC included.
+func keystoreEncrypt(plain, password []byte, scryptN, scryptP int) (keystoreCrypto, error) {
+	salt := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return keystoreCrypto{}, err
+	}
+	derivedKey, err := scrypt.Key(password, salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return keystoreCrypto{}, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return keystoreCrypto{}, err
+	}
+	cipherText, err := aesCTR(derivedKey[:16], iv, plain)
+	if err != nil {
+		return keystoreCrypto{}, err
+	}
+	mac := Keccak256Multi(derivedKey[16:32], cipherText)
+
+	return keystoreCrypto{
+		Cipher:       "aes-128-ctr",
+		CipherText:   hex.EncodeToString(cipherText),
+		CipherParams: keystoreCipherParams{IV: hex.EncodeToString(iv)},
+		KDF:          "scrypt",
+		KDFParams: map[string]interface{}{
+			"n":     scryptN,
+			"r":     scryptR,
+			"p":     scryptP,
+			"dklen": scryptDKLen,
+			"salt":  hex.EncodeToString(salt),
+		},
+		MAC: hex.EncodeToString(mac[:]),
+	}, nil
+}
+
+// ImportKeystoreV3 decrypts Ethereum keystore v3 JSON produced by
+// ExportKeystoreV3. Both scrypt and pbkdf2 (hmac-sha256) KDFs are accepted.
+// The public key is recomputed from the decrypted preimages and, if the file
+// carries a pkh, checked against it. The Used flag is restored.
+func ImportKeystoreV3(data, password []byte) (*KeyPair, error) {
+	var ks keystoreV3
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeystoreFormat, err)
+	}
+	if ks.Version != keystoreVersion {
+		return nil, ErrKeystoreFormat
+	}
+
+	plain, err := keystoreDecrypt(ks.Crypto, password)
+	if err != nil {
+		return nil, err
+	}
+
+	used := false
+	if len(plain) == PrivateKeySize+1 {
+		if plain[PrivateKeySize] != keystoreUsedFlag {
+			return nil, ErrKeystoreFormat
+		}
+		used = true
+		plain = plain[:PrivateKeySize]
+	}
+	priv := &PrivateKey{}
+	if err := priv.FromBytes(plain); err != nil {
+		return nil, err
+	}
+	priv.Used = used
+	pub := priv.DerivePublic()
+
+	if ks.PKH != "" {
+		pkh := pub.Hash()
+		if ks.PKH != hex.EncodeToString(pkh[:]) {
+			return nil, ErrKeystoreDecrypt
+		}
+	}
+
+	return &KeyPair{Private: priv, Public: pub}, nil
+}
+
+// keystoreDecrypt checks the MAC of a v3 crypto section and decrypts it.
+func keystoreDecrypt(c keystoreCrypto, password []byte) ([]byte, error) {
+	if c.Cipher != "aes-128-ctr" {
+		return nil, ErrKeystoreFormat
+	}
+	mac, err := hex.DecodeString(c.MAC)
+	if err != nil {
+		return nil, ErrKeystoreFormat
+	}
+	iv, err := hex.DecodeString(c.CipherParams.IV)
+	if err != nil {
+		return nil, ErrKeystoreFormat
+	}
+	cipherText, err := hex.DecodeString(c.CipherText)
+	if err != nil {
+		return nil, ErrKeystoreFormat
+	}
+
+	derivedKey, err := keystoreDerivedKey(c, password)
+	if err != nil {
+		return nil, err
+	}
+
+	calculated := Keccak256Multi(derivedKey[16:32], cipherText)
+	if subtle.ConstantTimeCompare(calculated[:], mac) != 1 {
+		return nil, ErrKeystoreDecrypt
+	}
+
+	return aesCTR(derivedKey[:16], iv, cipherText)
+}
+
+// keystoreDerivedKey runs the KDF named in the keystore. Parameters beyond
+// the max* bounds are rejected with ErrKeystoreFormat.
+func keystoreDerivedKey(c keystoreCrypto, password []byte) ([]byte, error) {
+	saltHex, _ := c.KDFParams["salt"].(string)
+	salt, err := hex.DecodeString(saltHex)
+	if err != nil {
+		return nil, ErrKeystoreFormat
+	}
+	dkLen := kdfInt(c.KDFParams, "dklen")
+	if dkLen < 32 || dkLen > maxKeystoreDKLen {
+		return nil, ErrKeystoreFormat
+	}
+
+	switch c.KDF {
+	case "scrypt":
+		n, r, p := kdfInt(c.KDFParams, "n"), kdfInt(c.KDFParams, "r"), kdfInt(c.KDFParams, "p")
+		if n < 2 || r < 1 || p < 1 || p > maxScryptP || n > maxScryptMemory/128/r {
+			return nil, ErrKeystoreFormat
+		}
+		return scrypt.Key(password, salt, n, r, p, dkLen)
+	case "pbkdf2":
+		if prf, _ := c.KDFParams["prf"].(string); prf != "hmac-sha256" {
+			return nil, ErrKeystoreFormat
+		}
+		iterations := kdfInt(c.KDFParams, "c")
+		if iterations < 1 || iterations > maxPBKDF2Iter {
+			return nil, ErrKeystoreFormat
+		}
+		return pbkdf2.Key(password, salt, iterations, dkLen, sha256.New), nil
+	default:
+		return nil, ErrKeystoreFormat
+	}
+}
+
+// kdfInt reads an integer KDF parameter; JSON numbers decode as float64.
+func kdfInt(params map[string]interface{}, name string) int {
+	f, _ := params[name].(float64)
+	return int(f)
+}
+
+func aesCTR(key, iv, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, ErrKeystoreFormat
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
+
+// newUUID returns a random RFC 4122 version 4 UUID string.
+func newUUID() (string, error) {
+	var u [16]byte
+	if _, err := io.ReadFull(rand.Reader, u[:]); err != nil {
+		return "", err
+	}
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16]), nil
+}
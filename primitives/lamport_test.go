@@ -1,7 +1,20 @@
 package primitives
 
 import (
+	"bytes"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+
+	"golang.org/x/crypto/sha3"
 )
 
 func TestGenerateKeyPair(t *testing.T) {
@@ -63,6 +76,35 @@ func TestSignAndVerify(t *testing.T) {
 	}
 }
 
+func TestSignConstantTime(t *testing.T) {
+	for n := 0; n < 8; n++ {
+		kp, err := GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		keyCopy := *kp.Private
+
+		message := Keccak256([]byte{byte(n)})
+		sig, err := SignConstantTime(kp.Private, message)
+		if err != nil {
+			t.Fatalf("SignConstantTime failed: %v", err)
+		}
+		want, _ := Sign(&keyCopy, message)
+		if *sig != *want {
+			t.Error("SignConstantTime should match Sign byte for byte")
+		}
+		if !Verify(kp.Public, message, sig) {
+			t.Error("Constant-time signature should verify")
+		}
+		if !kp.Private.Used {
+			t.Error("Key should be marked as used after signing")
+		}
+		if _, err := SignConstantTime(kp.Private, message); err != ErrKeyAlreadyUsed {
+			t.Errorf("Expected ErrKeyAlreadyUsed, got %v", err)
+		}
+	}
+}
+
 func TestVerifyInvalidSignature(t *testing.T) {
 	kp, err := GenerateKeyPair()
 	if err != nil {
@@ -195,6 +237,85 @@ func TestSignatureSerialization(t *testing.T) {
 	}
 }
 
+func TestGobEncoding(t *testing.T) {
+	var pubs []*PublicKey
+	var sigs []*Signature
+	var messages [][32]byte
+	for i := 0; i < 3; i++ {
+		kp, err := GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		message := Keccak256([]byte{byte(i)})
+		sig, _ := Sign(kp.Private, message)
+		pubs = append(pubs, kp.Public)
+		sigs = append(sigs, sig)
+		messages = append(messages, message)
+	}
+
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(sigs); err != nil {
+		t.Fatalf("gob Encode signatures failed: %v", err)
+	}
+	if err := enc.Encode(pubs); err != nil {
+		t.Fatalf("gob Encode public keys failed: %v", err)
+	}
+
+	var gotSigs []*Signature
+	var gotPubs []*PublicKey
+	dec := gob.NewDecoder(&buf)
+	if err := dec.Decode(&gotSigs); err != nil {
+		t.Fatalf("gob Decode signatures failed: %v", err)
+	}
+	if err := dec.Decode(&gotPubs); err != nil {
+		t.Fatalf("gob Decode public keys failed: %v", err)
+	}
+
+	if len(gotSigs) != len(sigs) || len(gotPubs) != len(pubs) {
+		t.Fatalf("Decoded %d signatures and %d keys, expected %d", len(gotSigs), len(gotPubs), len(sigs))
+	}
+	for i := range gotSigs {
+		if !Verify(gotPubs[i], messages[i], gotSigs[i]) {
+			t.Errorf("Decoded signature %d should verify", i)
+		}
+	}
+}
+
+func TestBase64(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := Keccak256([]byte("Base64"))
+	sig := signUnsafe(kp.Private, message)
+
+	pub2, err := PublicKeyFromBase64(kp.Public.Base64())
+	if err != nil {
+		t.Fatalf("PublicKeyFromBase64 failed: %v", err)
+	}
+	sig2, err := SignatureFromBase64(sig.Base64())
+	if err != nil {
+		t.Fatalf("SignatureFromBase64 failed: %v", err)
+	}
+	if !Verify(pub2, message, sig2) {
+		t.Error("Round-tripped base64 signature should verify")
+	}
+
+	// Malformed base64
+	if _, err := SignatureFromBase64("not base64!"); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for malformed input, got %v", err)
+	}
+	if _, err := PublicKeyFromBase64("@@@@"); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Errorf("Expected ErrInvalidPublicKey for malformed input, got %v", err)
+	}
+
+	// Valid base64 of the wrong length
+	if _, err := SignatureFromBase64(kp.Public.Base64()); err != ErrInvalidSignature {
+		t.Errorf("Expected ErrInvalidSignature for wrong length, got %v", err)
+	}
+}
+
 func TestPublicKeyHash(t *testing.T) {
 	kp, err := GenerateKeyPair()
 	if err != nil {
@@ -286,6 +407,144 @@ func TestKeyChain(t *testing.T) {
 	}
 }
 
+func TestKeyChainAutoExtend(t *testing.T) {
+	chain, err := NewKeyChain(3)
+	if err != nil {
+		t.Fatalf("NewKeyChain failed: %v", err)
+	}
+	chain.SetAutoExtend(1, 2)
+	var published [][32]byte
+	chain.OnExtend(func(pkhs [][32]byte) {
+		published = append(published, pkhs...)
+	})
+
+	for i := 0; i < 10; i++ {
+		kp, _ := chain.Current()
+		msg := Keccak256([]byte{byte(i)})
+		sig, nextPKH, err := SignWithKeyChain(chain, msg)
+		if err != nil {
+			t.Fatalf("SignWithKeyChain failed on iteration %d: %v", i, err)
+		}
+		if !Verify(kp.Public, msg, sig) {
+			t.Fatalf("Signature %d should verify", i)
+		}
+		next, _ := chain.Current()
+		if nextPKH != next.Public.Hash() {
+			t.Fatalf("nextPKH %d should be the new current key", i)
+		}
+	}
+
+	if chain.Remaining() <= 1 {
+		t.Errorf("Chain should stay above the threshold, got %d remaining", chain.Remaining())
+	}
+	all := chain.PKHList()
+	if len(published) != len(all)-3 {
+		t.Fatalf("Expected %d published PKHs, got %d", len(all)-3, len(published))
+	}
+	for i, pkh := range published {
+		if pkh != all[3+i] {
+			t.Errorf("Published PKH %d does not match the chain", i)
+		}
+	}
+
+	// Concurrent signers share the chain without exhausting it
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 3; i++ {
+				if _, _, err := SignWithKeyChain(chain, Keccak256([]byte{byte(g), byte(i)})); err != nil {
+					t.Errorf("Concurrent SignWithKeyChain failed: %v", err)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if chain.UsedCount != 22 {
+		t.Errorf("Expected 22 used keys, got %d", chain.UsedCount)
+	}
+}
+
+func TestKeyChainOnLowKeys(t *testing.T) {
+	chain, err := NewKeyChain(6)
+	if err != nil {
+		t.Fatalf("NewKeyChain failed: %v", err)
+	}
+	var calls []int
+	chain.OnLowKeys(2, func(remaining int) {
+		calls = append(calls, remaining)
+	})
+
+	for i := 0; i < 6; i++ {
+		if err := chain.Advance(); err != nil {
+			t.Fatalf("Advance failed: %v", err)
+		}
+	}
+	if len(calls) != 1 || calls[0] != 2 {
+		t.Errorf("Expected one callback with 2 remaining, got %v", calls)
+	}
+}
+
+// chainSigner signs locally but reads its chain while signing, as a remote
+// signer that checks chain state would.
+type chainSigner struct {
+	*LocalSigner
+	chain     *KeyChain
+	remaining int
+}
+
+func (s *chainSigner) Sign(message [32]byte) (*Signature, error) {
+	s.remaining = s.chain.Remaining()
+	return s.LocalSigner.Sign(message)
+}
+
+func TestKeyChainCallbacksOutsideLock(t *testing.T) {
+	hot, err := NewKeyChain(4)
+	if err != nil {
+		t.Fatalf("NewKeyChain failed: %v", err)
+	}
+	signers := make([]*chainSigner, 4)
+	list := make([]Signer, 4)
+	for i, kp := range hot.Keys {
+		signers[i] = &chainSigner{LocalSigner: NewLocalSigner(kp.Private, kp.Public)}
+		list[i] = signers[i]
+	}
+	chain, err := NewKeyChainFromSigners(list)
+	if err != nil {
+		t.Fatalf("NewKeyChainFromSigners failed: %v", err)
+	}
+	for _, s := range signers {
+		s.chain = chain
+	}
+
+	chain.SetAutoExtend(1, 2)
+	var extended, low []int
+	chain.OnExtend(func(pkhs [][32]byte) {
+		extended = append(extended, chain.Remaining())
+	})
+	chain.OnLowKeys(2, func(remaining int) {
+		low = append(low, chain.Remaining())
+	})
+
+	// The third signature drops the chain to the extension threshold
+	want := []int{3, 2, 3}
+	for i := range want {
+		if _, _, err := SignWithKeyChain(chain, Keccak256([]byte{byte(i)})); err != nil {
+			t.Fatalf("SignWithKeyChain failed on iteration %d: %v", i, err)
+		}
+		if signers[i].remaining != want[i] {
+			t.Errorf("Signer %d saw %d remaining, want %d", i, signers[i].remaining, want[i])
+		}
+	}
+	if len(low) != 1 || low[0] != 2 {
+		t.Errorf("Expected one low-keys callback with 2 remaining, got %v", low)
+	}
+	if len(extended) != 1 || extended[0] != 3 {
+		t.Errorf("Expected one extension callback with 3 remaining, got %v", extended)
+	}
+}
+
 func TestGetBit(t *testing.T) {
 	// Test with known values
 	var msg [32]byte
@@ -306,6 +565,64 @@ func TestGetBit(t *testing.T) {
 	}
 }
 
+func TestGetBitChecked(t *testing.T) {
+	// 0xa5 = 10100101, 0x01 in the last byte sets bit 255
+	var msg [32]byte
+	msg[0] = 0xa5
+	msg[31] = 0x01
+	want := map[int]int{0: 1, 1: 0, 2: 1, 3: 0, 4: 0, 5: 1, 6: 0, 7: 1, 8: 0, 254: 0, 255: 1}
+	for i, bit := range want {
+		got, err := GetBitChecked(msg, i)
+		if err != nil || got != bit {
+			t.Errorf("GetBitChecked(%d) = %d, %v; want %d", i, got, err, bit)
+		}
+	}
+	for _, i := range []int{-1, KeyBits, 1 << 20} {
+		if _, err := GetBitChecked(msg, i); !errors.Is(err, ErrBitIndexOutOfRange) {
+			t.Errorf("GetBitChecked(%d): expected ErrBitIndexOutOfRange, got %v", i, err)
+		}
+	}
+
+	bits := Bits(msg)
+	if !bytes.Equal(bits[:8], []byte{1, 0, 1, 0, 0, 1, 0, 1}) {
+		t.Errorf("Bits[:8] = %v, want [1 0 1 0 0 1 0 1]", bits[:8])
+	}
+	for i := 0; i < KeyBits; i++ {
+		if int(bits[i]) != GetBit(msg, i) {
+			t.Fatalf("Bits[%d] != GetBit", i)
+		}
+	}
+	if Bits([32]byte{}) != [KeyBits]byte{} {
+		t.Error("Zero message should have no set bits")
+	}
+}
+
+func TestBitVector(t *testing.T) {
+	for n := 0; n < 64; n++ {
+		msg := Keccak256([]byte{byte(n)})
+		if n == 0 {
+			msg = [32]byte{}
+		}
+		bv := NewBitVector(msg)
+		for i := 0; i < KeyBits; i++ {
+			if bv.Bit(i) != GetBit(msg, i) {
+				t.Fatalf("Message %d: BitVector.Bit(%d) != GetBit", n, i)
+			}
+		}
+
+		count := 0
+		bv.Iterate(func(i, bit int) {
+			if i != count || bit != GetBit(msg, i) {
+				t.Fatalf("Message %d: Iterate mismatch at %d", n, i)
+			}
+			count++
+		})
+		if count != KeyBits {
+			t.Errorf("Iterate visited %d bits, expected %d", count, KeyBits)
+		}
+	}
+}
+
 func TestComputeThresholdMessage(t *testing.T) {
 	var safeTxHash [32]byte
 	var nextPKH [32]byte
@@ -338,6 +655,236 @@ func TestComputeThresholdMessage(t *testing.T) {
 	}
 }
 
+func TestComputeThresholdMessageU256(t *testing.T) {
+	var safeTxHash, nextPKH [32]byte
+	var moduleAddress [20]byte
+	for i := range safeTxHash {
+		safeTxHash[i] = byte(i)
+		nextPKH[i] = byte(i + 32)
+	}
+	for i := range moduleAddress {
+		moduleAddress[i] = byte(i + 64)
+	}
+
+	// Small chain IDs match the uint64 variant
+	if ComputeThresholdMessageU256(safeTxHash, nextPKH, moduleAddress, ChainIDFromUint64(96369)) !=
+		ComputeThresholdMessage(safeTxHash, nextPKH, moduleAddress, 96369) {
+		t.Error("U256 variant should match uint64 variant for small chain IDs")
+	}
+	if ComputeDomainSeparatorU256(moduleAddress, ChainIDFromUint64(96369)) != ComputeDomainSeparator(moduleAddress, 96369) {
+		t.Error("U256 domain separator should match uint64 variant for small chain IDs")
+	}
+
+	// Chain ID 2^64 + 1: low 64 bits alone would collide with chain ID 1
+	var big [32]byte
+	big[23] = 0x01
+	big[31] = 0x01
+	msg := ComputeThresholdMessageU256(safeTxHash, nextPKH, moduleAddress, big)
+	if msg == ComputeThresholdMessage(safeTxHash, nextPKH, moduleAddress, 1) {
+		t.Error("Chain ID above 2^64 should not collide with its low 64 bits")
+	}
+
+	// abi.encodePacked(bytes32, bytes32, address, uint256)
+	packed := append(append(append(append([]byte{}, safeTxHash[:]...), nextPKH[:]...), moduleAddress[:]...), big[:]...)
+	if msg != Keccak256(packed) {
+		t.Error("U256 message should match abi.encodePacked layout")
+	}
+}
+
+func TestMessageBuilder(t *testing.T) {
+	var safeTxHash, nextPKH [32]byte
+	var moduleAddress [20]byte
+	for i := range safeTxHash {
+		safeTxHash[i] = byte(i)
+		nextPKH[i] = byte(255 - i)
+	}
+	for i := range moduleAddress {
+		moduleAddress[i] = byte(i + 64)
+	}
+
+	for _, chainID := range []uint64{0, 1, 96369, 1<<64 - 1} {
+		msg := NewMessageBuilder().Add32(safeTxHash).Add32(nextPKH).Add20(moduleAddress).AddUint256(chainID).Hash()
+		if msg != ComputeThresholdMessageU256(safeTxHash, nextPKH, moduleAddress, ChainIDFromUint64(chainID)) {
+			t.Errorf("chain %d: builder differs from the hand-packed message", chainID)
+		}
+		if msg != ComputeThresholdMessage(safeTxHash, nextPKH, moduleAddress, chainID) {
+			t.Errorf("chain %d: builder differs from ComputeThresholdMessage", chainID)
+		}
+
+		sep := NewMessageBuilder().Add20(moduleAddress).AddUint256(chainID).Hash()
+		if sep != ComputeDomainSeparator(moduleAddress, chainID) {
+			t.Errorf("chain %d: builder differs from ComputeDomainSeparator", chainID)
+		}
+	}
+
+	if NewMessageBuilder().Hash() != Keccak256(nil) {
+		t.Error("Empty builder should hash the empty string")
+	}
+}
+
+func TestComputeThresholdMessageV2(t *testing.T) {
+	safeTxHash := Keccak256([]byte("safe tx"))
+	nextPKH := Keccak256([]byte("next"))
+	var module [20]byte
+	module[19] = 0x42
+
+	v1 := ComputeThresholdMessage(safeTxHash, nextPKH, module, 1)
+	v2 := ComputeThresholdMessageV2(safeTxHash, nextPKH, module, 1)
+	if v1 == v2 {
+		t.Error("v1 and v2 messages should differ")
+	}
+
+	// Layout: version byte followed by the v1 packed fields
+	var buf [117]byte
+	buf[0] = 0x02
+	copy(buf[1:], safeTxHash[:])
+	copy(buf[33:], nextPKH[:])
+	copy(buf[65:], module[:])
+	buf[116] = 1
+	if v2 != Keccak256(buf[:]) {
+		t.Error("v2 layout mismatch")
+	}
+
+	got, err := ComputeThresholdMessageVersion(ThresholdMessageV1, safeTxHash, nextPKH, module, 1)
+	if err != nil || got != v1 {
+		t.Errorf("Version 1 should match ComputeThresholdMessage: %v", err)
+	}
+	got, err = ComputeThresholdMessageVersion(ThresholdMessageV2, safeTxHash, nextPKH, module, 1)
+	if err != nil || got != v2 {
+		t.Errorf("Version 2 should match ComputeThresholdMessageV2: %v", err)
+	}
+	if _, err := ComputeThresholdMessageVersion(3, safeTxHash, nextPKH, module, 1); err != ErrUnsupportedMessageVersion {
+		t.Errorf("Expected ErrUnsupportedMessageVersion, got %v", err)
+	}
+}
+
+func TestComputeThresholdMessageEIP712(t *testing.T) {
+	// Domain separator for the EIP-712 specification's "Ether Mail" example
+	var mailContract [20]byte
+	for i := range mailContract {
+		mailContract[i] = 0xcc
+	}
+	domain := eip712DomainSeparator("Ether Mail", "1", ChainIDFromUint64(1), mailContract)
+	if hex.EncodeToString(domain[:]) != "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f" {
+		t.Errorf("EIP-712 domain separator mismatch: %x", domain)
+	}
+
+	var safeTxHash, nextPKH [32]byte
+	var moduleAddress [20]byte
+	for i := range safeTxHash {
+		safeTxHash[i] = byte(i)
+		nextPKH[i] = byte(i + 32)
+	}
+	for i := range moduleAddress {
+		moduleAddress[i] = byte(i + 64)
+	}
+
+	// Fixture digest for the inputs above on chain 96369
+	msg := ComputeThresholdMessageEIP712(safeTxHash, nextPKH, moduleAddress, 96369)
+	if hex.EncodeToString(msg[:]) != "22d436fb39f9ec05de2b0057820b95aa382337e685b952ceaf4db4696f709459" {
+		t.Errorf("EIP-712 threshold message mismatch: %x", msg)
+	}
+
+	if msg == ComputeThresholdMessage(safeTxHash, nextPKH, moduleAddress, 96369) {
+		t.Error("EIP-712 message should differ from the packed message")
+	}
+	if msg == ComputeThresholdMessageEIP712(safeTxHash, nextPKH, moduleAddress, 1) {
+		t.Error("Different chainID should produce different EIP-712 message")
+	}
+}
+
+func TestComputeEIP712DomainSeparator(t *testing.T) {
+	// EIP-712 specification "Ether Mail" example domain
+	var mailContract [20]byte
+	for i := range mailContract {
+		mailContract[i] = 0xcc
+	}
+	domain := ComputeEIP712DomainSeparator("Ether Mail", "1", 1, mailContract)
+	if hex.EncodeToString(domain[:]) != "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f" {
+		t.Errorf("EIP-712 domain separator mismatch: %x", domain)
+	}
+
+	if domain == ComputeDomainSeparator(mailContract, 1) {
+		t.Error("EIP-712 domain separator should differ from the packed form")
+	}
+	if domain == ComputeEIP712DomainSeparator("Ether Mail", "2", 1, mailContract) {
+		t.Error("Different version should produce a different domain separator")
+	}
+}
+
+func TestBindNextKey(t *testing.T) {
+	current, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	next, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	other, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	var moduleAddress [20]byte
+	moduleAddress[19] = 0x42
+	safeTxHash := Keccak256([]byte("rotate"))
+
+	sig, err := SignThresholdMessage(current.Private, safeTxHash, next.Public.Hash(), moduleAddress, 96369)
+	if err != nil {
+		t.Fatalf("SignThresholdMessage failed: %v", err)
+	}
+
+	pkh, ok := BindNextKey(sig, safeTxHash, current.Public, next.Public, moduleAddress, 96369)
+	if !ok || pkh != next.Public.Hash() {
+		t.Error("Correctly bound next key should be proven")
+	}
+	if pkh, ok := BindNextKey(sig, safeTxHash, current.Public, other.Public, moduleAddress, 96369); ok || pkh != ([32]byte{}) {
+		t.Error("Mismatched next key should not be bound")
+	}
+	if _, ok := BindNextKey(sig, safeTxHash, current.Public, next.Public, moduleAddress, 1); ok {
+		t.Error("Binding on another chain should fail")
+	}
+	if _, ok := BindNextKey(sig, safeTxHash, current.Public, nil, moduleAddress, 96369); ok {
+		t.Error("Nil next key should not be bound")
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	// EIP-55 known vectors
+	for _, want := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+	} {
+		addr, err := ParseAddress(want)
+		if err != nil {
+			t.Fatalf("ParseAddress(%s) failed: %v", want, err)
+		}
+		if got := AddressChecksum(addr); got != want {
+			t.Errorf("Expected checksum %s, got %s", want, got)
+		}
+
+		// Lowercase and bare forms parse to the same address
+		lower, err := ParseAddress(strings.ToLower(want[2:]))
+		if err != nil || lower != addr {
+			t.Errorf("Lowercase form of %s should parse to the same address", want)
+		}
+	}
+
+	// Wrong length
+	if _, err := ParseAddress("0x1234"); err != ErrInvalidAddress {
+		t.Errorf("Expected ErrInvalidAddress for short input, got %v", err)
+	}
+	// Non-hex
+	if _, err := ParseAddress("0xzz00000000000000000000000000000000000000"); err != ErrInvalidAddress {
+		t.Errorf("Expected ErrInvalidAddress for non-hex input, got %v", err)
+	}
+	// Bad checksum
+	if _, err := ParseAddress("0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"); err != ErrAddressChecksum {
+		t.Errorf("Expected ErrAddressChecksum, got %v", err)
+	}
+}
+
 func TestVerifyU256(t *testing.T) {
 	kp, err := GenerateKeyPair()
 	if err != nil {
@@ -363,38 +910,1490 @@ func TestVerifyU256(t *testing.T) {
 	}
 }
 
-func TestToCalldata(t *testing.T) {
+func TestVerifyU256WithOrder(t *testing.T) {
 	kp, err := GenerateKeyPair()
 	if err != nil {
 		t.Fatalf("GenerateKeyPair failed: %v", err)
 	}
+	message := Keccak256([]byte("Bit order"))
 
-	message := Keccak256([]byte("Calldata test"))
-	sig := signUnsafe(kp.Private, message)
+	// MSB-first signature (the default)
+	msb := signUnsafe(kp.Private, message)
 
-	// Test signature calldata
-	calldata := sig.ToCalldata()
-	if len(calldata) != KeyBits {
-		t.Errorf("Expected %d elements, got %d", KeyBits, len(calldata))
+	// LSB-first signature: position i reveals the preimage for bit i of the uint256
+	var lsb [KeyBits][PreimageSize]byte
+	for i := 0; i < KeyBits; i++ {
+		lsb[i] = kp.Private.Preimages[i][LSBFirst.Bit(message, i)]
 	}
-	for i, data := range calldata {
-		if len(data) != PreimageSize {
-			t.Errorf("Element %d has wrong size: %d", i, len(data))
-		}
+
+	if !VerifyU256WithOrder(message, msb.Preimages, kp.Public.Hashes, MSBFirst) {
+		t.Error("MSB-first signature should verify with MSBFirst")
+	}
+	if VerifyU256WithOrder(message, msb.Preimages, kp.Public.Hashes, LSBFirst) {
+		t.Error("MSB-first signature should fail with LSBFirst")
+	}
+	if !VerifyU256WithOrder(message, lsb, kp.Public.Hashes, LSBFirst) {
+		t.Error("LSB-first signature should verify with LSBFirst")
+	}
+	if VerifyU256(message, lsb, kp.Public.Hashes) {
+		t.Error("LSB-first signature should fail with default VerifyU256")
 	}
 
-	// Test public key calldata
-	pubCalldata := kp.Public.ToCalldata()
-	if len(pubCalldata) != KeyBits {
-		t.Errorf("Expected %d elements, got %d", KeyBits, len(pubCalldata))
+	// LSBFirst position 0 is the lowest bit of the last byte
+	var one [32]byte
+	one[31] = 1
+	if LSBFirst.Bit(one, 0) != 1 || MSBFirst.Bit(one, 255) != 1 || LSBFirst.Bit(one, 255) != 0 {
+		t.Error("Unexpected bit mapping for uint256(1)")
 	}
 }
 
-func BenchmarkGenerateKeyPair(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		_, _ = GenerateKeyPair()
+func TestCompressedRoot(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
 	}
-}
+	root := kp.Public.CompressedRoot()
+	message := Keccak256([]byte("Merkle"))
+	sig := signUnsafe(kp.Private, message)
+
+	// Inclusion: every revealed preimage verifies against the root
+	for _, i := range []int{0, 1, 127, 128, 254, 255} {
+		bit := GetBit(message, i)
+		proof := kp.Public.LeafProof(i, bit)
+		if len(proof) != MerkleDepth {
+			t.Fatalf("Expected proof of length %d, got %d", MerkleDepth, len(proof))
+		}
+		if !VerifyPreimageProof(root, i, bit, sig.Preimages[i], proof) {
+			t.Errorf("Preimage %d should verify against the root", i)
+		}
+		if !VerifyLeafProof(root, i, bit, kp.Public.Hashes[i][bit], proof) {
+			t.Errorf("Leaf %d should verify against the root", i)
+		}
+
+		// Exclusion: wrong side, wrong position, wrong preimage, other key
+		if VerifyPreimageProof(root, i, 1-bit, sig.Preimages[i], proof) {
+			t.Errorf("Preimage %d should not verify for the other side", i)
+		}
+		if VerifyPreimageProof(root, (i+1)%KeyBits, bit, sig.Preimages[i], proof) {
+			t.Errorf("Preimage %d should not verify at another position", i)
+		}
+		wrong := sig.Preimages[i]
+		wrong[0] ^= 1
+		if VerifyPreimageProof(root, i, bit, wrong, proof) {
+			t.Errorf("Modified preimage %d should not verify", i)
+		}
+		if VerifyPreimageProof(root, i, bit, sig.Preimages[i], proof[:MerkleDepth-1]) {
+			t.Errorf("Truncated proof for %d should not verify", i)
+		}
+	}
+
+	kp2, _ := GenerateKeyPair()
+	if kp2.Public.CompressedRoot() == root {
+		t.Error("Different keys should have different roots")
+	}
+}
+
+func TestRemainingSecurityBits(t *testing.T) {
+	tests := []struct {
+		revealed int
+		want     float64
+	}{
+		{0, 256},
+		{128, 128},
+		{256, 0},
+		{-1, 256},
+		{300, 0},
+	}
+	for _, tt := range tests {
+		if got := RemainingSecurityBits(tt.revealed); got != tt.want {
+			t.Errorf("RemainingSecurityBits(%d) = %v, want %v", tt.revealed, got, tt.want)
+		}
+	}
+}
+
+func TestExposedPositionsAfter(t *testing.T) {
+	m := Keccak256([]byte("plan"))
+	var complement [32]byte
+	for i := range m {
+		complement[i] = ^m[i]
+	}
+
+	if BitDifference(m, m) != 0 {
+		t.Error("Identical messages should differ in 0 bits")
+	}
+	if BitDifference(m, complement) != KeyBits {
+		t.Errorf("Complementary messages should differ in %d bits", KeyBits)
+	}
+	if got := BitDifference([32]byte{0x81}, [32]byte{0x01, 0x10}); got != 2 {
+		t.Errorf("BitDifference = %d, want 2", got)
+	}
+
+	tests := []struct {
+		name     string
+		messages [][32]byte
+		want     int
+	}{
+		{"none", nil, 0},
+		{"single", [][32]byte{m}, 0},
+		{"identical", [][32]byte{m, m, m}, 0},
+		{"complementary", [][32]byte{m, complement}, KeyBits},
+		{"pair", [][32]byte{{0xf0}, {0x0f}}, 8},
+		{"three", [][32]byte{{0x80}, {0x40}, {0xc0}}, 2},
+	}
+	for _, tt := range tests {
+		if got := ExposedPositionsAfter(tt.messages); got != tt.want {
+			t.Errorf("%s: ExposedPositionsAfter = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+	other := Keccak256([]byte("other"))
+	if ExposedPositionsAfter([][32]byte{m, other}) != BitDifference(m, other) {
+		t.Error("Two messages should expose exactly BitDifference positions")
+	}
+}
+
+func TestToCalldata(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+
+	message := Keccak256([]byte("Calldata test"))
+	sig := signUnsafe(kp.Private, message)
+
+	// Test signature calldata
+	calldata := sig.ToCalldata()
+	if len(calldata) != KeyBits {
+		t.Errorf("Expected %d elements, got %d", KeyBits, len(calldata))
+	}
+	for i, data := range calldata {
+		if len(data) != PreimageSize {
+			t.Errorf("Element %d has wrong size: %d", i, len(data))
+		}
+	}
+
+	// Test public key calldata
+	pubCalldata := kp.Public.ToCalldata()
+	if len(pubCalldata) != KeyBits {
+		t.Errorf("Expected %d elements, got %d", KeyBits, len(pubCalldata))
+	}
+}
+
+func TestVerifyCache(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := Keccak256([]byte("Cached"))
+	sig := signUnsafe(kp.Private, message)
+	bad := *sig
+	bad.Preimages[0][0] ^= 0xFF
+
+	cache := NewVerifyCache(2)
+	calls := 0
+	cache.verify = func(pub *PublicKey, message [32]byte, sig *Signature) bool {
+		calls++
+		return Verify(pub, message, sig)
+	}
+
+	// Miss then hit
+	if !cache.Verify(kp.Public, message, sig) || !cache.Verify(kp.Public, message, sig) {
+		t.Error("Cached verification of valid signature should succeed")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 underlying verification, got %d", calls)
+	}
+
+	// Invalid results are cached too
+	if cache.Verify(kp.Public, message, &bad) || cache.Verify(kp.Public, message, &bad) {
+		t.Error("Cached verification of invalid signature should fail")
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 underlying verifications, got %d", calls)
+	}
+
+	// Bounded: a third entry evicts the least recently used
+	other := Keccak256([]byte("Other"))
+	cache.Verify(kp.Public, other, sig)
+	if cache.Len() != 2 {
+		t.Errorf("Expected 2 cached results, got %d", cache.Len())
+	}
+	cache.Verify(kp.Public, message, sig)
+	if calls != 4 {
+		t.Errorf("Evicted entry should be recomputed, got %d calls", calls)
+	}
+}
+
+func TestMultiSig(t *testing.T) {
+	message := Keccak256([]byte("Multisig test"))
+
+	ms := NewMultiSig(message)
+	var pubs []*PublicKey
+	var sigs []*Signature
+	for i := 0; i < 3; i++ {
+		kp, err := GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		sig, _ := Sign(kp.Private, message)
+		if err := ms.Add(kp.Public, sig); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		pubs = append(pubs, kp.Public)
+		sigs = append(sigs, sig)
+	}
+
+	// All valid
+	if !ms.Verify() {
+		t.Error("Multisig with all valid signatures should verify")
+	}
+	if !VerifyMultiSig(pubs, message, sigs) {
+		t.Error("VerifyMultiSig should succeed for all valid signatures")
+	}
+
+	// Group PKH is deterministic and order-sensitive
+	if ms.GroupPKH() != MultiSigPKH(pubs) {
+		t.Error("GroupPKH should match MultiSigPKH")
+	}
+	if MultiSigPKH([]*PublicKey{pubs[1], pubs[0], pubs[2]}) == ms.GroupPKH() {
+		t.Error("Reordered signers should produce a different group PKH")
+	}
+	if MultiSigPKH(pubs[:1]) != pubs[0].Hash() {
+		t.Error("Single-signer group PKH should equal the signer PKH")
+	}
+
+	// Duplicate signer is rejected
+	if err := ms.Add(pubs[0], sigs[0]); err != ErrDuplicateSigner {
+		t.Errorf("Expected ErrDuplicateSigner, got %v", err)
+	}
+	if VerifyMultiSig([]*PublicKey{pubs[0], pubs[0]}, message, []*Signature{sigs[0], sigs[0]}) {
+		t.Error("VerifyMultiSig should reject duplicate signers")
+	}
+
+	// One invalid signature fails the group
+	bad := *sigs[1]
+	bad.Preimages[7][0] ^= 0xFF
+	if VerifyMultiSig(pubs, message, []*Signature{sigs[0], &bad, sigs[2]}) {
+		t.Error("VerifyMultiSig should fail when one signature is invalid")
+	}
+
+	kp, _ := GenerateKeyPair()
+	if err := ms.Add(kp.Public, &bad); err != ErrVerificationFailed {
+		t.Errorf("Expected ErrVerificationFailed, got %v", err)
+	}
+	if ms.Len() != 3 {
+		t.Errorf("Expected 3 signers, got %d", ms.Len())
+	}
+}
+
+func TestDeriveKeyPair(t *testing.T) {
+	var master [32]byte
+	for i := range master {
+		master[i] = byte(i)
+	}
+
+	a1, err := DeriveKeyPair(master, "app/a")
+	if err != nil {
+		t.Fatalf("DeriveKeyPair failed: %v", err)
+	}
+	a2, _ := DeriveKeyPair(master, "app/a")
+	b, _ := DeriveKeyPair(master, "app/b")
+
+	// Same path is reproducible
+	if a1.Public.Hash() != a2.Public.Hash() {
+		t.Error("Same path should derive the same key")
+	}
+	if a1.Private.Preimages != a2.Private.Preimages {
+		t.Error("Same path should derive the same private key")
+	}
+
+	// Different paths are independent
+	if a1.Public.Hash() == b.Public.Hash() {
+		t.Error("Different paths should derive different keys")
+	}
+	for i := 0; i < KeyBits; i++ {
+		for bit := 0; bit < 2; bit++ {
+			if a1.Private.Preimages[i][bit] == b.Private.Preimages[i][bit] {
+				t.Fatalf("Preimage collision between paths at position %d, bit %d", i, bit)
+			}
+		}
+	}
+
+	// Derived keys sign and verify like any other key
+	message := Keccak256([]byte("Derived"))
+	sig, _ := Sign(a1.Private, message)
+	if !Verify(a2.Public, message, sig) {
+		t.Error("Signature from derived key should verify")
+	}
+}
+
+func TestSeedPrivateKey(t *testing.T) {
+	seed := Keccak256([]byte("seed key"))
+	key := NewSeedPrivateKey(seed)
+
+	// Derived public key matches a normally generated one from the same seed
+	kp, err := GenerateKeyPairFromSeed(seed)
+	if err != nil {
+		t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+	}
+	if key.Hash() != kp.Public.Hash() {
+		t.Error("SeedPrivateKey public key should match GenerateKeyPairFromSeed")
+	}
+	if key.Expand().Preimages != kp.Private.Preimages {
+		t.Error("Expanded SeedPrivateKey should match GenerateKeyPairFromSeed")
+	}
+
+	message := Keccak256([]byte("Seed-backed signing"))
+	sig, err := key.Sign(message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if !Verify(key.PublicKey(), message, sig) {
+		t.Error("SeedPrivateKey signature should verify against derived public key")
+	}
+	if want, _ := Sign(kp.Private, message); *want != *sig {
+		t.Error("SeedPrivateKey signature should match Sign with the expanded key")
+	}
+
+	if !key.Used {
+		t.Error("Key should be marked as used after signing")
+	}
+	if _, err := key.Sign(message); err != ErrKeyAlreadyUsed {
+		t.Errorf("Expected ErrKeyAlreadyUsed, got %v", err)
+	}
+
+	// Usable anywhere a Signer is expected
+	var _ Signer = key
+}
+
+func TestMnemonic(t *testing.T) {
+	// Known-answer vectors from the BIP39 specification (256-bit entropy)
+	vectors := []struct {
+		fill   byte
+		phrase string
+	}{
+		{0x00, "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art"},
+		{0x7f, "legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title"},
+		{0x80, "letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic bless"},
+		{0xff, "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote"},
+	}
+	for _, v := range vectors {
+		var seed [32]byte
+		for i := range seed {
+			seed[i] = v.fill
+		}
+		phrase, err := MnemonicFromSeed(seed)
+		if err != nil {
+			t.Fatalf("MnemonicFromSeed failed: %v", err)
+		}
+		if phrase != v.phrase {
+			t.Errorf("Seed %#x: expected %q, got %q", v.fill, v.phrase, phrase)
+		}
+		got, err := SeedFromMnemonic(v.phrase)
+		if err != nil {
+			t.Fatalf("SeedFromMnemonic failed: %v", err)
+		}
+		if got != seed {
+			t.Errorf("Seed %#x did not round-trip", v.fill)
+		}
+	}
+
+	// Random round trip
+	seed := Keccak256([]byte("mnemonic"))
+	phrase, _ := MnemonicFromSeed(seed)
+	got, err := SeedFromMnemonic(phrase)
+	if err != nil || got != seed {
+		t.Errorf("Round trip failed: %v", err)
+	}
+
+	// Errors
+	if _, err := SeedFromMnemonic("abandon abandon"); err != ErrInvalidMnemonicLength {
+		t.Errorf("Expected ErrInvalidMnemonicLength, got %v", err)
+	}
+	bad := strings.Replace(vectors[0].phrase, "art", "zoo", 1)
+	if _, err := SeedFromMnemonic(bad); err != ErrInvalidMnemonicChecksum {
+		t.Errorf("Expected ErrInvalidMnemonicChecksum, got %v", err)
+	}
+	unknown := strings.Replace(vectors[0].phrase, "art", "lamport", 1)
+	if _, err := SeedFromMnemonic(unknown); !errors.Is(err, ErrInvalidMnemonicWord) {
+		t.Errorf("Expected ErrInvalidMnemonicWord, got %v", err)
+	}
+}
+
+func TestKeystoreV3(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	password := []byte("correct horse battery staple")
+
+	data, err := ExportKeystoreV3WithScrypt(kp, password, LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatalf("ExportKeystoreV3 failed: %v", err)
+	}
+
+	// Round trip
+	kp2, err := ImportKeystoreV3(data, password)
+	if err != nil {
+		t.Fatalf("ImportKeystoreV3 failed: %v", err)
+	}
+	if kp2.Private.Preimages != kp.Private.Preimages {
+		t.Error("Imported private key mismatch")
+	}
+	if kp2.Public.Hash() != kp.Public.Hash() {
+		t.Error("Imported public key mismatch")
+	}
+
+	// Wrong password
+	if _, err := ImportKeystoreV3(data, []byte("wrong")); err != ErrKeystoreDecrypt {
+		t.Errorf("Expected ErrKeystoreDecrypt for wrong password, got %v", err)
+	}
+
+	// Tampered MAC
+	var ks map[string]interface{}
+	if err := json.Unmarshal(data, &ks); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	c := ks["crypto"].(map[string]interface{})
+	mac := []byte(c["mac"].(string))
+	if mac[0] == '0' {
+		mac[0] = '1'
+	} else {
+		mac[0] = '0'
+	}
+	c["mac"] = string(mac)
+	tampered, _ := json.Marshal(ks)
+	if _, err := ImportKeystoreV3(tampered, password); err != ErrKeystoreDecrypt {
+		t.Errorf("Expected ErrKeystoreDecrypt for tampered MAC, got %v", err)
+	}
+
+	// Unsupported version
+	ks["version"] = 1
+	old, _ := json.Marshal(ks)
+	if _, err := ImportKeystoreV3(old, password); err != ErrKeystoreFormat {
+		t.Errorf("Expected ErrKeystoreFormat, got %v", err)
+	}
+	ks["version"] = keystoreVersion
+
+	// Oversized KDF parameters are rejected before any work is done
+	params := c["kdfparams"].(map[string]interface{})
+	for _, tc := range []struct {
+		name  string
+		value float64
+	}{
+		{"n", 1 << 40},
+		{"r", 1 << 20},
+		{"p", 1 << 20},
+		{"dklen", 1 << 30},
+	} {
+		saved := params[tc.name]
+		params[tc.name] = tc.value
+		huge, _ := json.Marshal(ks)
+		if _, err := ImportKeystoreV3(huge, password); err != ErrKeystoreFormat {
+			t.Errorf("Expected ErrKeystoreFormat for %s=%v, got %v", tc.name, tc.value, err)
+		}
+		params[tc.name] = saved
+	}
+	c["kdf"] = "pbkdf2"
+	c["kdfparams"] = map[string]interface{}{
+		"c": 1 << 40, "dklen": 32, "prf": "hmac-sha256", "salt": params["salt"],
+	}
+	huge, _ := json.Marshal(ks)
+	if _, err := ImportKeystoreV3(huge, password); err != ErrKeystoreFormat {
+		t.Errorf("Expected ErrKeystoreFormat for huge pbkdf2 c, got %v", err)
+	}
+}
+
+func TestKeystoreV3Used(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	password := []byte("correct horse battery staple")
+
+	if _, err := Sign(kp.Private, Keccak256([]byte("spent"))); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	data, err := ExportKeystoreV3WithScrypt(kp, password, LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatalf("ExportKeystoreV3 failed: %v", err)
+	}
+	restored, err := ImportKeystoreV3(data, password)
+	if err != nil {
+		t.Fatalf("ImportKeystoreV3 failed: %v", err)
+	}
+	if !restored.Private.Used {
+		t.Error("Used flag should survive a keystore round trip")
+	}
+	if _, err := Sign(restored.Private, Keccak256([]byte("again"))); err != ErrKeyAlreadyUsed {
+		t.Errorf("Expected ErrKeyAlreadyUsed from restored key, got %v", err)
+	}
+}
+
+func TestKeychainArchive(t *testing.T) {
+	hot, err := NewKeyChain(3)
+	if err != nil {
+		t.Fatalf("NewKeyChain failed: %v", err)
+	}
+	cold, err := NewKeyChain(4)
+	if err != nil {
+		t.Fatalf("NewKeyChain failed: %v", err)
+	}
+	if err := hot.Advance(); err != nil {
+		t.Fatalf("Advance failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := cold.Advance(); err != nil {
+			t.Fatalf("Advance failed: %v", err)
+		}
+	}
+	chains := map[string]*KeyChain{"hot": hot, "cold": cold}
+	password := []byte("correct horse battery staple")
+
+	data, err := ExportKeychainArchiveWithScrypt(chains, password, LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatalf("ExportKeychainArchive failed: %v", err)
+	}
+
+	// Round trip restores keys and usage state
+	restored, err := ImportKeychainArchive(data, password)
+	if err != nil {
+		t.Fatalf("ImportKeychainArchive failed: %v", err)
+	}
+	if len(restored) != len(chains) {
+		t.Fatalf("Expected %d chains, got %d", len(chains), len(restored))
+	}
+	for name, want := range chains {
+		got, ok := restored[name]
+		if !ok {
+			t.Fatalf("Chain %q missing", name)
+		}
+		if got.CurrentIndex != want.CurrentIndex || got.UsedCount != want.UsedCount {
+			t.Errorf("Chain %q: index/used %d/%d, want %d/%d", name,
+				got.CurrentIndex, got.UsedCount, want.CurrentIndex, want.UsedCount)
+		}
+		for i, kp := range want.Keys {
+			if got.Keys[i].Private.Preimages != kp.Private.Preimages {
+				t.Errorf("Chain %q key %d: private key mismatch", name, i)
+			}
+			if got.Keys[i].Private.Used != kp.Private.Used {
+				t.Errorf("Chain %q key %d: Used = %v, want %v", name, i, got.Keys[i].Private.Used, kp.Private.Used)
+			}
+		}
+	}
+
+	// Wrong password
+	if _, err := ImportKeychainArchive(data, []byte("wrong")); err != ErrKeystoreDecrypt {
+		t.Errorf("Expected ErrKeystoreDecrypt for wrong password, got %v", err)
+	}
+
+	// The plaintext manifest is bound to the MAC
+	var archive map[string]interface{}
+	if err := json.Unmarshal(data, &archive); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	manifest := archive["manifest"].([]interface{})
+	manifest[0].(map[string]interface{})["currentIndex"] = 0
+	tampered, _ := json.Marshal(archive)
+	if _, err := ImportKeychainArchive(tampered, password); err != ErrKeystoreDecrypt {
+		t.Errorf("Expected ErrKeystoreDecrypt for tampered manifest, got %v", err)
+	}
+
+	// Chains without private keys cannot be archived
+	remote, err := NewKeyChainFromSigners([]Signer{NewLocalSigner(hot.Keys[0].Private, hot.Keys[0].Public)})
+	if err != nil {
+		t.Fatalf("NewKeyChainFromSigners failed: %v", err)
+	}
+	if _, err := ExportKeychainArchiveWithScrypt(map[string]*KeyChain{"remote": remote}, password, LightScryptN, LightScryptP); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Errorf("Expected ErrInvalidPrivateKey, got %v", err)
+	}
+}
+
+// mockRemoteSigner stands in for an HSM: it never exposes preimages and
+// returns a canned signature.
+type mockRemoteSigner struct {
+	pub   *PublicKey
+	sig   *Signature
+	calls int
+}
+
+func (m *mockRemoteSigner) PublicKey() *PublicKey { return m.pub }
+
+func (m *mockRemoteSigner) Sign(message [32]byte) (*Signature, error) {
+	m.calls++
+	if m.calls > 1 {
+		return nil, ErrKeyAlreadyUsed
+	}
+	return m.sig, nil
+}
+
+func TestSigner(t *testing.T) {
+	message := Keccak256([]byte("Remote signer"))
+
+	// Canned signatures produced out of process
+	var signers []Signer
+	var remotes []*mockRemoteSigner
+	for i := 0; i < 2; i++ {
+		kp, _ := GenerateKeyPair()
+		remote := &mockRemoteSigner{pub: kp.Public, sig: signUnsafe(kp.Private, message)}
+		signers = append(signers, remote)
+		remotes = append(remotes, remote)
+	}
+
+	chain, err := NewKeyChainFromSigners(signers)
+	if err != nil {
+		t.Fatalf("NewKeyChainFromSigners failed: %v", err)
+	}
+	if chain.Keys[0].Private != nil {
+		t.Error("Signer-backed chain should not hold private keys")
+	}
+
+	sig, nextPKH, err := SignWithKeyChain(chain, message)
+	if err != nil {
+		t.Fatalf("SignWithKeyChain failed: %v", err)
+	}
+	if remotes[0].calls != 1 {
+		t.Errorf("Expected remote signer to be called once, got %d", remotes[0].calls)
+	}
+	if !Verify(remotes[0].pub, message, sig) {
+		t.Error("Signature from remote signer should verify")
+	}
+	if nextPKH != remotes[1].pub.Hash() {
+		t.Error("nextPKH should match the second signer's public key")
+	}
+	if chain.Remaining() != 1 {
+		t.Errorf("Expected 1 remaining, got %d", chain.Remaining())
+	}
+
+	// LocalSigner recomputes the public key and enforces one-time use
+	kp, _ := GenerateKeyPair()
+	local := NewLocalSigner(kp.Private, nil)
+	if local.PublicKey().Hash() != kp.Public.Hash() {
+		t.Error("LocalSigner public key mismatch")
+	}
+	if _, err := local.Sign(message); err != nil {
+		t.Fatalf("LocalSigner.Sign failed: %v", err)
+	}
+	if _, err := local.Sign(message); err != ErrKeyAlreadyUsed {
+		t.Errorf("Expected ErrKeyAlreadyUsed, got %v", err)
+	}
+}
+
+func TestFromCalldata(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := Keccak256([]byte("Calldata round trip"))
+	sig := signUnsafe(kp.Private, message)
+
+	// Round trip
+	sig2, err := SignatureFromCalldata(sig.ToCalldata())
+	if err != nil {
+		t.Fatalf("SignatureFromCalldata failed: %v", err)
+	}
+	if *sig2 != *sig {
+		t.Error("Signature calldata round trip mismatch")
+	}
+	pub2 := PublicKeyFromCalldata(kp.Public.ToCalldata())
+	if pub2.Hash() != kp.Public.Hash() {
+		t.Error("Public key calldata round trip mismatch")
+	}
+	if !Verify(pub2, message, sig2) {
+		t.Error("Round-tripped signature should verify")
+	}
+
+	// Wrong element count
+	if _, err := SignatureFromCalldata(sig.ToCalldata()[:255]); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for 255 elements, got %v", err)
+	}
+
+	// Wrong element size
+	data := sig.ToCalldata()
+	data[10] = data[10][:31]
+	if _, err := SignatureFromCalldata(data); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for short element, got %v", err)
+	}
+}
+
+func TestSignatureIsWellFormed(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := Keccak256([]byte("well-formed"))
+	sig := signUnsafe(kp.Private, message)
+	if !sig.IsWellFormed() {
+		t.Error("Normal signature should be well-formed")
+	}
+
+	zero := &Signature{}
+	if zero.IsWellFormed() {
+		t.Error("All-zero signature should not be well-formed")
+	}
+	if Verify(kp.Public, message, zero) {
+		t.Error("All-zero signature should not verify")
+	}
+
+	// A key committing to zero preimages would accept zero without the check;
+	zeroKey := &PublicKey{}
+	zeroHash := Keccak256(make([]byte, PreimageSize))
+	for i := range zeroKey.Hashes {
+		zeroKey.Hashes[i] = [2][HashSize]byte{zeroHash, zeroHash}
+	}
+	// every verifier must still reject it
+	if Verify(zeroKey, message, zero) || VerifyConstantTime(zeroKey, message, zero) {
+		t.Error("All-zero signature should fail both Verify and VerifyConstantTime")
+	}
+	if VerifyU256(message, zero.Preimages, zeroKey.Hashes) || VerifyU256WithOrder(message, zero.Preimages, zeroKey.Hashes, LSBFirst) {
+		t.Error("All-zero signature should fail VerifyU256 and VerifyU256WithOrder")
+	}
+	if _, ok := VerifyAny([]*PublicKey{zeroKey}, message, zero); ok {
+		t.Error("All-zero signature should fail VerifyAny")
+	}
+	if _, _, ok := VerifyAgainstMessages(zeroKey, zero, [][32]byte{message}); ok {
+		t.Error("All-zero signature should fail VerifyAgainstMessages")
+	}
+
+	oneZero := *sig
+	oneZero.Preimages[17] = [PreimageSize]byte{}
+	if !oneZero.IsWellFormed() {
+		t.Error("Signature with a single zero preimage should be well-formed")
+	}
+}
+
+func TestVerifyAgainstMessages(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	m1 := Keccak256([]byte("message one"))
+	m2 := Keccak256([]byte("message two"))
+	sig1 := signUnsafe(kp.Private, m1)
+	sig2 := signUnsafe(kp.Private, m2)
+
+	matched, message, ok := VerifyAgainstMessages(kp.Public, sig1, [][32]byte{m2, m1})
+	if !ok || matched != KeyBits || message != m1 {
+		t.Errorf("Expected clean match on m1, got matched=%d ok=%v", matched, ok)
+	}
+
+	// Mix: first half of preimages from m1, second half from m2
+	mixed := *sig1
+	for i := KeyBits / 2; i < KeyBits; i++ {
+		mixed.Preimages[i] = sig2.Preimages[i]
+	}
+	matched, _, ok = VerifyAgainstMessages(kp.Public, &mixed, [][32]byte{m1, m2})
+	if ok {
+		t.Error("Mixed signature should be reported inconsistent")
+	}
+	if matched == KeyBits || matched < KeyBits/2 {
+		t.Errorf("Unexpected match count for mixed signature: %d", matched)
+	}
+
+	if _, _, ok := VerifyAgainstMessages(kp.Public, sig1, nil); ok {
+		t.Error("No candidates should not be ok")
+	}
+}
+
+func TestBatchVerifyChain(t *testing.T) {
+	chain, err := NewKeyChain(4)
+	if err != nil {
+		t.Fatalf("NewKeyChain failed: %v", err)
+	}
+
+	// Sign three messages with keys 1..3
+	if err := chain.Advance(); err != nil {
+		t.Fatalf("Advance failed: %v", err)
+	}
+	messages := make([][32]byte, 3)
+	sigs := make([]*Signature, 3)
+	for j := range messages {
+		messages[j] = Keccak256([]byte{byte(j)})
+		sig, _, err := SignWithKeyChain(chain, messages[j])
+		if err != nil {
+			t.Fatalf("SignWithKeyChain failed: %v", err)
+		}
+		sigs[j] = sig
+	}
+
+	for j, ok := range BatchVerifyChain(chain, 1, messages, sigs) {
+		if !ok {
+			t.Errorf("Signature %d should verify", j)
+		}
+	}
+
+	// Wrong start index maps every signature to the wrong key
+	for j, ok := range BatchVerifyChain(chain, 0, messages, sigs) {
+		if ok {
+			t.Errorf("Signature %d should not verify at offset 0", j)
+		}
+	}
+
+	// Running off the end of the chain
+	results := BatchVerifyChain(chain, 2, messages, sigs)
+	if results[2] {
+		t.Error("Entry beyond the chain should be invalid")
+	}
+
+	if results := BatchVerifyChain(chain, 1, messages, sigs[:2]); results[0] {
+		t.Error("Mismatched lengths should return all false")
+	}
+}
+
+func TestKeyChainPKHLookup(t *testing.T) {
+	chain, err := NewKeyChain(3)
+	if err != nil {
+		t.Fatalf("NewKeyChain failed: %v", err)
+	}
+
+	for i := range chain.Keys {
+		pkh, err := chain.PKHAt(i)
+		if err != nil {
+			t.Fatalf("PKHAt(%d) failed: %v", i, err)
+		}
+		if pkh != chain.Keys[i].Public.Hash() {
+			t.Errorf("PKHAt(%d) mismatch", i)
+		}
+		idx, ok := chain.FindByPKH(pkh)
+		if !ok || idx != i {
+			t.Errorf("FindByPKH for key %d returned %d, %v", i, idx, ok)
+		}
+	}
+
+	for _, i := range []int{-1, 3} {
+		if _, err := chain.PKHAt(i); err != ErrKeyIndexOutOfRange {
+			t.Errorf("PKHAt(%d): expected ErrKeyIndexOutOfRange, got %v", i, err)
+		}
+	}
+
+	if _, ok := chain.FindByPKH([32]byte{1}); ok {
+		t.Error("Unknown PKH should not be found")
+	}
+
+	// Appended keys are picked up by the cache
+	kp, _ := GenerateKeyPair()
+	chain.Keys = append(chain.Keys, kp)
+	if idx, ok := chain.FindByPKH(kp.Public.Hash()); !ok || idx != 3 {
+		t.Errorf("Appended key should be found at 3, got %d, %v", idx, ok)
+	}
+}
+
+func TestPublicKeyHashStreamed(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		kp, err := GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		if kp.Public.Hash() != Keccak256(kp.Public.Bytes()) {
+			t.Error("Streamed PKH should equal keccak256(Bytes())")
+		}
+	}
+	var zero PublicKey
+	if zero.Hash() != Keccak256(make([]byte, PublicKeySize)) {
+		t.Error("Streamed PKH of the zero key should match")
+	}
+}
+
+func TestKeyChainIsUsedPKH(t *testing.T) {
+	chain, err := NewKeyChain(3)
+	if err != nil {
+		t.Fatalf("NewKeyChain failed: %v", err)
+	}
+	first := chain.Keys[0].Public.Hash()
+	second := chain.Keys[1].Public.Hash()
+
+	if used, err := chain.IsUsedPKH(first); err != nil || used {
+		t.Errorf("Fresh key: got %v, %v, want false, nil", used, err)
+	}
+	if err := chain.Advance(); err != nil {
+		t.Fatalf("Advance failed: %v", err)
+	}
+	if used, err := chain.IsUsedPKH(first); err != nil || !used {
+		t.Errorf("Advanced key: got %v, %v, want true, nil", used, err)
+	}
+	if used, err := chain.IsUsedPKH(second); err != nil || used {
+		t.Errorf("Next key: got %v, %v, want false, nil", used, err)
+	}
+	if _, err := chain.IsUsedPKH([32]byte{1}); err != ErrUnknownPKH {
+		t.Errorf("Unknown PKH: expected ErrUnknownPKH, got %v", err)
+	}
+}
+
+func TestVerifyAny(t *testing.T) {
+	pubs := make([]*PublicKey, 5)
+	var signer *PrivateKey
+	for i := range pubs {
+		kp, err := GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		pubs[i] = kp.Public
+		if i == 2 {
+			signer = kp.Private
+		}
+	}
+	message := Keccak256([]byte("any"))
+	sig, err := Sign(signer, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	if index, ok := VerifyAny(pubs, message, sig); !ok || index != 2 {
+		t.Errorf("VerifyAny = %d, %v, want 2, true", index, ok)
+	}
+	withNil := append([]*PublicKey{nil}, pubs...)
+	if index, ok := VerifyAny(withNil, message, sig); !ok || index != 3 {
+		t.Errorf("VerifyAny with a nil entry = %d, %v, want 3, true", index, ok)
+	}
+
+	others := []*PublicKey{pubs[0], pubs[1], pubs[3], pubs[4]}
+	if index, ok := VerifyAny(others, message, sig); ok || index != -1 {
+		t.Errorf("No-match VerifyAny = %d, %v, want -1, false", index, ok)
+	}
+	if index, ok := VerifyAny(pubs, Keccak256([]byte("other")), sig); ok || index != -1 {
+		t.Errorf("Wrong message VerifyAny = %d, %v, want -1, false", index, ok)
+	}
+	if index, ok := VerifyAny(pubs, message, nil); ok || index != -1 {
+		t.Errorf("Nil signature VerifyAny = %d, %v, want -1, false", index, ok)
+	}
+}
+
+func TestVerifyChainSignature(t *testing.T) {
+	chain, err := NewKeyChain(3)
+	if err != nil {
+		t.Fatalf("NewKeyChain failed: %v", err)
+	}
+	pkhs := chain.PKHList()
+	message := Keccak256([]byte("chain"))
+
+	if err := chain.Advance(); err != nil {
+		t.Fatalf("Advance failed: %v", err)
+	}
+	pub := chain.Keys[1].Public
+	sig, _, err := SignWithKeyChain(chain, message)
+	if err != nil {
+		t.Fatalf("SignWithKeyChain failed: %v", err)
+	}
+
+	if ok, err := VerifyChainSignature(pkhs, 1, pub, message, sig); !ok || err != nil {
+		t.Errorf("Valid chain signature: got %v, %v", ok, err)
+	}
+	if ok, err := VerifyChainSignature(pkhs, 0, pub, message, sig); ok || err != ErrPKHMismatch {
+		t.Errorf("Wrong index: expected ErrPKHMismatch, got %v, %v", ok, err)
+	}
+	for _, index := range []int{-1, 3} {
+		if ok, err := VerifyChainSignature(pkhs, index, pub, message, sig); ok || err != ErrKeyIndexOutOfRange {
+			t.Errorf("Index %d: expected ErrKeyIndexOutOfRange, got %v, %v", index, ok, err)
+		}
+	}
+	if ok, err := VerifyChainSignature(pkhs, 1, pub, Keccak256([]byte("other")), sig); ok || err != nil {
+		t.Errorf("Wrong message: expected false, nil, got %v, %v", ok, err)
+	}
+}
+
+func TestKeyChainPKHList(t *testing.T) {
+	chain, err := NewKeyChain(3)
+	if err != nil {
+		t.Fatalf("NewKeyChain failed: %v", err)
+	}
+
+	pkhs := chain.PKHList()
+	if len(pkhs) != 3 {
+		t.Fatalf("Expected 3 PKHs, got %d", len(pkhs))
+	}
+	for i, pkh := range pkhs {
+		if pkh != chain.Keys[i].Public.Hash() {
+			t.Errorf("PKH %d out of order", i)
+		}
+	}
+
+	data := chain.PKHListBytes()
+	if len(data) != 3*32 {
+		t.Fatalf("Expected %d bytes, got %d", 3*32, len(data))
+	}
+	parsed, err := PKHListFromBytes(data)
+	if err != nil {
+		t.Fatalf("PKHListFromBytes failed: %v", err)
+	}
+	for i := range pkhs {
+		if parsed[i] != pkhs[i] {
+			t.Errorf("Parsed PKH %d mismatch", i)
+		}
+	}
+	if _, err := PKHListFromBytes(data[:33]); err != ErrInvalidPKHList {
+		t.Errorf("Expected ErrInvalidPKHList, got %v", err)
+	}
+
+	message := Keccak256([]byte("rotation"))
+	sig, _, err := SignWithKeyChain(chain, message)
+	if err != nil {
+		t.Fatalf("SignWithKeyChain failed: %v", err)
+	}
+	pub := chain.Keys[0].Public
+	if !VerifyWithPKHList(parsed, 0, pub, message, sig) {
+		t.Error("Signature should verify at index 0")
+	}
+	if VerifyWithPKHList(parsed, 1, pub, message, sig) {
+		t.Error("Signature should not verify at index 1")
+	}
+	if VerifyWithPKHList(parsed, 3, pub, message, sig) {
+		t.Error("Out-of-range index should not verify")
+	}
+}
+
+func TestVerifyRecovery(t *testing.T) {
+	seed := Keccak256([]byte("backup seed"))
+	kp, err := GenerateKeyPairFromSeed(seed)
+	if err != nil {
+		t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+	}
+	pkh := kp.Public.Hash()
+
+	if ok, err := VerifyRecovery(seed, pkh); err != nil || !ok {
+		t.Errorf("Matching seed should recover: %v", err)
+	}
+	wrong := seed
+	wrong[0] ^= 1
+	if ok, _ := VerifyRecovery(wrong, pkh); ok {
+		t.Error("Wrong seed should not recover")
+	}
+}
+
+func TestVerifyChainRecovery(t *testing.T) {
+	master := Keccak256([]byte("master seed"))
+	chain, err := DeriveKeyChain(master, 3)
+	if err != nil {
+		t.Fatalf("DeriveKeyChain failed: %v", err)
+	}
+	kp, _ := DeriveKeyPair(master, "chain/2")
+	if kp.Public.Hash() != chain.Keys[2].Public.Hash() {
+		t.Error("Chain key 2 should match path chain/2")
+	}
+
+	pkhs := chain.PKHList()
+	if ok, err := VerifyChainRecovery(master, pkhs); err != nil || !ok {
+		t.Errorf("Matching master seed should recover: %v", err)
+	}
+
+	wrong := master
+	wrong[31] ^= 1
+	if ok, _ := VerifyChainRecovery(wrong, pkhs); ok {
+		t.Error("Wrong master seed should not recover")
+	}
+
+	pkhs[1], pkhs[2] = pkhs[2], pkhs[1]
+	if ok, _ := VerifyChainRecovery(master, pkhs); ok {
+		t.Error("Out-of-order PKHs should not recover")
+	}
+	if ok, _ := VerifyChainRecovery(master, nil); ok {
+		t.Error("Empty PKH list should not recover")
+	}
+}
+
+func TestVerifyProjected(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := Keccak256([]byte("projected"))
+	other := Keccak256([]byte("other"))
+	sig := signUnsafe(kp.Private, message)
+	projected := kp.Public.ProjectForMessage(message)
+
+	if got, want := VerifyProjected(projected, message, sig), Verify(kp.Public, message, sig); got != want || !got {
+		t.Errorf("VerifyProjected = %v, Verify = %v", got, want)
+	}
+
+	bad := *sig
+	bad.Preimages[200][0] ^= 1
+	if got, want := VerifyProjected(projected, message, &bad), Verify(kp.Public, message, &bad); got != want || got {
+		t.Errorf("Tampered: VerifyProjected = %v, Verify = %v", got, want)
+	}
+
+	if VerifyProjected(kp.Public.ProjectForMessage(other), message, sig) {
+		t.Error("Projection for another message should not verify")
+	}
+
+	// The same matching projection and signature, checked for another message
+	if VerifyProjected(projected, other, sig) {
+		t.Error("Projection and signature should not verify for a different message")
+	}
+	if VerifyProjected(nil, message, sig) {
+		t.Error("Nil projection should not verify")
+	}
+}
+
+func TestVerifyByPKH(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	store := NewMemoryPublicKeyStore()
+	pkh := store.Put(kp.Public)
+	if store.Len() != 1 {
+		t.Errorf("Expected 1 key, got %d", store.Len())
+	}
+
+	message := Keccak256([]byte("registry"))
+	sig := signUnsafe(kp.Private, message)
+
+	if ok, err := VerifyByPKH(store, pkh, message, sig); err != nil || !ok {
+		t.Errorf("Known PKH with valid signature should verify: %v", err)
+	}
+
+	bad := *sig
+	bad.Preimages[0][0] ^= 1
+	if ok, err := VerifyByPKH(store, pkh, message, &bad); err != nil || ok {
+		t.Errorf("Known PKH with invalid signature should fail without error, got %v, %v", ok, err)
+	}
+
+	unknown := pkh
+	unknown[0] ^= 1
+	if ok, err := VerifyByPKH(store, unknown, message, sig); err != ErrUnknownPKH || ok {
+		t.Errorf("Expected ErrUnknownPKH, got %v, %v", ok, err)
+	}
+}
+
+func TestReseedingReader(t *testing.T) {
+	r, err := NewReseedingReader(nil, 4*HashSize)
+	if err != nil {
+		t.Fatalf("NewReseedingReader failed: %v", err)
+	}
+
+	seen := make(map[[HashSize]byte]bool)
+	for i := 0; i < 64; i++ {
+		var block [HashSize]byte
+		if _, err := io.ReadFull(r, block[:]); err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		if seen[block] {
+			t.Fatalf("Block %d repeats earlier output", i)
+		}
+		seen[block] = true
+		if r.block != ([HashSize]byte{}) {
+			t.Fatalf("Block %d still held by the reader after Read", i)
+		}
+	}
+
+	var partial [HashSize / 2]byte
+	if _, err := r.Read(partial[:]); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if r.block != ([HashSize]byte{}) {
+		t.Error("Unread half of the output block should be zeroed by the ratchet")
+	}
+
+	kp, err := GenerateKeyPairFromReader(r)
+	if err != nil {
+		t.Fatalf("GenerateKeyPairFromReader failed: %v", err)
+	}
+	message := Keccak256([]byte("reseeding"))
+	if !Verify(kp.Public, message, signUnsafe(kp.Private, message)) {
+		t.Error("Key generated from ReseedingReader should verify")
+	}
+}
+
+func TestConstantTimeEqualHash(t *testing.T) {
+	a := Keccak256([]byte("pkh"))
+	if !ConstantTimeEqualHash(a, a) {
+		t.Error("Equal hashes should compare equal")
+	}
+	for _, pos := range []int{0, 15, 31} {
+		b := a
+		b[pos] ^= 0x80
+		if ConstantTimeEqualHash(a, b) {
+			t.Errorf("Hashes differing at byte %d should not compare equal", pos)
+		}
+	}
+}
+
+func TestVerifyBit(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+
+	preimage := kp.Private.Preimages[7][1]
+	if !kp.Public.VerifyBit(7, 1, preimage) {
+		t.Error("Correct preimage should verify")
+	}
+	if kp.Public.VerifyBit(7, 0, preimage) {
+		t.Error("Preimage should not verify against the other side")
+	}
+
+	wrong := preimage
+	wrong[0] ^= 1
+	if kp.Public.VerifyBit(7, 1, wrong) {
+		t.Error("Wrong preimage should not verify")
+	}
+
+	for _, c := range []struct{ i, bit int }{{-1, 0}, {KeyBits, 0}, {0, -1}, {0, 2}} {
+		if kp.Public.VerifyBit(c.i, c.bit, preimage) {
+			t.Errorf("Out-of-range (%d, %d) should not verify", c.i, c.bit)
+		}
+	}
+}
+
+func TestVerifyRange(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := Keccak256([]byte("pipeline"))
+	sig := signUnsafe(kp.Private, message)
+
+	if !VerifyRange(kp.Public, message, sig, 0, KeyBits) {
+		t.Error("Full range should verify like Verify")
+	}
+	if !VerifyRange(kp.Public, message, sig, 0, 100) || !VerifyRange(kp.Public, message, sig, 100, KeyBits) {
+		t.Error("Disjoint halves of a valid signature should both verify")
+	}
+
+	bad := *sig
+	bad.Preimages[200][0] ^= 1
+	if !VerifyRange(kp.Public, message, &bad, 0, 100) {
+		t.Error("Range not covering the tampered bit should verify")
+	}
+	if VerifyRange(kp.Public, message, &bad, 100, KeyBits) {
+		t.Error("Range covering the tampered bit should fail")
+	}
+	if VerifyRange(kp.Public, message, &bad, 0, KeyBits) != Verify(kp.Public, message, &bad) {
+		t.Error("Full range should match Verify on an invalid signature")
+	}
+
+	for _, r := range [][2]int{{-1, 10}, {0, KeyBits + 1}, {10, 10}, {20, 10}} {
+		if VerifyRange(kp.Public, message, sig, r[0], r[1]) {
+			t.Errorf("Invalid range [%d, %d) should not verify", r[0], r[1])
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	EnableStats(true)
+	ResetStats()
+	defer EnableStats(false)
+
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := Keccak256([]byte("stats"))
+	sig, err := Sign(kp.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if _, err := Sign(kp.Private, message); err != ErrKeyAlreadyUsed {
+		t.Fatalf("Expected ErrKeyAlreadyUsed, got %v", err)
+	}
+	Verify(kp.Public, message, sig)
+	Verify(kp.Public, Keccak256([]byte("other")), sig)
+
+	want := Stats{KeysGenerated: 1, Signatures: 1, VerifySuccess: 1, VerifyFail: 1, KeyReuseAttempts: 1}
+	if got := ReadStats(); got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	EnableStats(false)
+	Verify(kp.Public, message, sig)
+	if got := ReadStats(); got != want {
+		t.Errorf("Disabled stats should not change, got %+v", got)
+	}
+
+	ResetStats()
+	if got := ReadStats(); got != (Stats{}) {
+		t.Errorf("Expected zero stats after reset, got %+v", got)
+	}
+}
+
+func TestVerifyThresholdMessageStrict(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	pkh := kp.Public.Hash()
+	var safeTxHash, nextPKH [32]byte
+	safeTxHash[0] = 1
+	var zeroAddr [20]byte
+	sig := signUnsafe(kp.Private, ComputeThresholdMessage(safeTxHash, nextPKH, zeroAddr, 96369))
+
+	// Permissive default accepts the zero module address
+	if !VerifyThresholdMessage(kp.Public, sig, safeTxHash, nextPKH, zeroAddr, 96369, pkh) {
+		t.Error("Default mode should accept a zero module address")
+	}
+
+	// Strict mode rejects it, and a zero chain ID, with ErrWeakDomain
+	if ok, err := VerifyThresholdMessageStrict(kp.Public, sig, safeTxHash, nextPKH, zeroAddr, 96369, pkh); ok || err != ErrWeakDomain {
+		t.Errorf("Expected ErrWeakDomain for zero module address, got %v, %v", ok, err)
+	}
+	moduleAddr := [20]byte{1}
+	if ok, err := VerifyThresholdMessageStrict(kp.Public, sig, safeTxHash, nextPKH, moduleAddr, 0, pkh); ok || err != ErrWeakDomain {
+		t.Errorf("Expected ErrWeakDomain for zero chain ID, got %v, %v", ok, err)
+	}
+
+	// Strict mode verifies normally with a full domain
+	kp2, _ := GenerateKeyPair()
+	sig2 := signUnsafe(kp2.Private, ComputeThresholdMessage(safeTxHash, nextPKH, moduleAddr, 96369))
+	if ok, err := VerifyThresholdMessageStrict(kp2.Public, sig2, safeTxHash, nextPKH, moduleAddr, 96369, kp2.Public.Hash()); !ok || err != nil {
+		t.Errorf("Strict mode should accept a valid signature, got %v, %v", ok, err)
+	}
+}
+
+func TestFromBytesChecked(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+
+	pkh := kp.Public.Hash()
+
+	var pub PublicKey
+	if err := pub.FromBytesChecked(kp.Public.Bytes(), pkh); err != nil || pub != *kp.Public {
+		t.Fatalf("Real public key should pass: %v", err)
+	}
+
+	// A private key passed where the public key was expected
+	if err := pub.FromBytesChecked(kp.Private.Bytes(), pkh); err != ErrPKHMismatch {
+		t.Errorf("Expected ErrPKHMismatch for private key bytes, got %v", err)
+	}
+
+	// Zeroed buffer of the right length
+	if err := pub.FromBytesChecked(make([]byte, PublicKeySize), pkh); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Errorf("Expected ErrInvalidPublicKey for zero buffer, got %v", err)
+	}
+
+	// Duplicated halves at a single position
+	dup := kp.Public.Bytes()
+	copy(dup[5*64+32:5*64+64], dup[5*64:5*64+32])
+	if err := pub.FromBytesChecked(dup, pkh); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Errorf("Expected ErrInvalidPublicKey for duplicated halves, got %v", err)
+	}
+	if pub != *kp.Public {
+		t.Error("Rejected input should leave the key unchanged")
+	}
+
+	var sig Signature
+	if err := sig.FromBytesChecked(make([]byte, SignatureSize)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for zero signature, got %v", err)
+	}
+	real := signUnsafe(kp.Private, Keccak256([]byte("checked")))
+	if err := sig.FromBytesChecked(real.Bytes()); err != nil || sig != *real {
+		t.Errorf("Real signature should pass: %v", err)
+	}
+}
+
+func TestBytesWithChecksum(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	sig := signUnsafe(kp.Private, Keccak256([]byte("checksum")))
+
+	var pub PublicKey
+	var sig2 Signature
+	var priv PrivateKey
+	if err := pub.FromBytesWithChecksum(kp.Public.BytesWithChecksum()); err != nil || pub != *kp.Public {
+		t.Errorf("PublicKey checksum round trip failed: %v", err)
+	}
+	if err := sig2.FromBytesWithChecksum(sig.BytesWithChecksum()); err != nil || sig2 != *sig {
+		t.Errorf("Signature checksum round trip failed: %v", err)
+	}
+	if err := priv.FromBytesWithChecksum(kp.Private.BytesWithChecksum()); err != nil || priv.Preimages != kp.Private.Preimages {
+		t.Errorf("PrivateKey checksum round trip failed: %v", err)
+	}
+
+	flip := func(b []byte, i int) []byte {
+		b[i] ^= 0x01
+		return b
+	}
+	if err := pub.FromBytesWithChecksum(flip(kp.Public.BytesWithChecksum(), 100)); err != ErrCorrupted {
+		t.Errorf("Expected ErrCorrupted for public key, got %v", err)
+	}
+	if err := sig2.FromBytesWithChecksum(flip(sig.BytesWithChecksum(), SignatureSize)); err != ErrCorrupted {
+		t.Errorf("Expected ErrCorrupted for signature checksum byte, got %v", err)
+	}
+	if err := priv.FromBytesWithChecksum(flip(kp.Private.BytesWithChecksum(), 0)); err != ErrCorrupted {
+		t.Errorf("Expected ErrCorrupted for private key, got %v", err)
+	}
+	if err := pub.FromBytesWithChecksum(kp.Public.Bytes()); err != ErrInvalidPublicKey {
+		t.Errorf("Expected ErrInvalidPublicKey without checksum, got %v", err)
+	}
+}
+
+func TestKeyPairVerify(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	if err := kp.Verify(); err != nil {
+		t.Errorf("Consistent pair should verify: %v", err)
+	}
+
+	priv := &PrivateKey{}
+	if err := priv.FromBytes(kp.Private.Bytes()); err != nil {
+		t.Fatalf("FromBytes failed: %v", err)
+	}
+	if *priv.DerivePublic() != *kp.Public {
+		t.Error("DerivePublic should rebuild the original public key")
+	}
+
+	other, _ := GenerateKeyPair()
+	mismatched := &KeyPair{Private: kp.Private, Public: other.Public}
+	if err := mismatched.Verify(); err != ErrKeyPairMismatch {
+		t.Errorf("Expected ErrKeyPairMismatch, got %v", err)
+	}
+
+	tampered := *kp.Public
+	tampered.Hashes[9][1][0] ^= 1
+	if err := (&KeyPair{Private: kp.Private, Public: &tampered}).Verify(); err != ErrKeyPairMismatch {
+		t.Errorf("Expected ErrKeyPairMismatch for tampered key, got %v", err)
+	}
+}
+
+func TestKeccak256Batch(t *testing.T) {
+	inputs := [][]byte{nil, []byte("a"), make([]byte, 32), make([]byte, 200)}
+	for i, got := range Keccak256Batch(inputs) {
+		if got != Keccak256(inputs[i]) {
+			t.Errorf("Keccak256Batch[%d] mismatch", i)
+		}
+	}
+
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	preimages := make([][PreimageSize]byte, KeyBits)
+	for i := range preimages {
+		preimages[i] = kp.Private.Preimages[i][1]
+	}
+	for i, got := range Keccak256Batch256(preimages) {
+		if got != kp.Public.Hashes[i][1] {
+			t.Errorf("Keccak256Batch256[%d] mismatch", i)
+		}
+	}
+
+	if len(Keccak256Batch256(nil)) != 0 {
+		t.Error("Empty batch should return no hashes")
+	}
+}
+
+func TestRunBenchmarks(t *testing.T) {
+	res := RunBenchmarks(2)
+	if res.Iterations != 2 {
+		t.Errorf("Expected 2 iterations, got %d", res.Iterations)
+	}
+	if res.KeyGen <= 0 || res.Sign <= 0 || res.Verify <= 0 || res.PKH <= 0 {
+		t.Errorf("Expected nonzero timings, got %+v", res)
+	}
+	if res.PrivateKeySize != PrivateKeySize || res.PublicKeySize != PublicKeySize ||
+		res.SignatureSize != SignatureSize || res.PublicKeyHashSize != PublicKeyHashSize {
+		t.Errorf("Sizes do not match constants: %+v", res)
+	}
+}
+
+func BenchmarkGenerateKeyPair(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = GenerateKeyPair()
+	}
+}
 
 func BenchmarkSign(b *testing.B) {
 	message := Keccak256([]byte("Benchmark"))
@@ -415,12 +2414,104 @@ func BenchmarkVerify(b *testing.B) {
 	}
 }
 
+// BenchmarkPreparedVerify verifies many signatures against one key, as
+// prepared and as plain Verify.
+func BenchmarkPreparedVerify(b *testing.B) {
+	fixtures, err := GenerateSignedFixtures(64, NewSeededReader([32]byte{9}))
+	if err != nil {
+		b.Fatalf("GenerateSignedFixtures failed: %v", err)
+	}
+	pub := fixtures[0].Public
+	prepared, err := NewPreparedPublicKey(pub)
+	if err != nil {
+		b.Fatalf("NewPreparedPublicKey failed: %v", err)
+	}
+	b.Run("prepared", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			f := &fixtures[i%len(fixtures)]
+			prepared.Verify(f.Message, f.Signature)
+		}
+	})
+	b.Run("verify", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			f := &fixtures[i%len(fixtures)]
+			Verify(pub, f.Message, f.Signature)
+		}
+	})
+}
+
 func BenchmarkPublicKeyHash(b *testing.B) {
 	kp, _ := GenerateKeyPair()
+	b.Run("streamed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = kp.Public.Hash()
+		}
+	})
+	b.Run("bytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = Keccak256(kp.Public.Bytes())
+		}
+	})
+}
+
+// BenchmarkConstantTimeEqualHash compares hashes differing in the first and
+// the last byte; the timings should match since there is no early exit.
+func BenchmarkConstantTimeEqualHash(b *testing.B) {
+	a := Keccak256([]byte("pkh"))
+	for _, pos := range []int{0, 31} {
+		other := a
+		other[pos] ^= 1
+		b.Run(fmt.Sprintf("diff-at-%d", pos), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ConstantTimeEqualHash(a, other)
+			}
+		})
+	}
+}
+
+func BenchmarkKeccak256Individual(b *testing.B) {
+	preimages := make([][PreimageSize]byte, 2*KeyBits)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range preimages {
+			_ = Keccak256(preimages[j][:])
+		}
+	}
+}
+
+func BenchmarkKeccak256Batch256(b *testing.B) {
+	preimages := make([][PreimageSize]byte, 2*KeyBits)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = kp.Public.Hash()
+		_ = Keccak256Batch256(preimages)
+	}
+}
+
+func BenchmarkGetBit(b *testing.B) {
+	message := Keccak256([]byte("Benchmark"))
+	sum := 0
+	for n := 0; n < b.N; n++ {
+		for i := 0; i < KeyBits; i++ {
+			sum += GetBit(message, i)
+		}
+	}
+	_ = sum
+}
+
+func BenchmarkBitVector(b *testing.B) {
+	message := Keccak256([]byte("Benchmark"))
+	sum := 0
+	for n := 0; n < b.N; n++ {
+		bv := NewBitVector(message)
+		for i := 0; i < KeyBits; i++ {
+			sum += bv.Bit(i)
+		}
 	}
+	_ = sum
 }
 
 // Fuzz test for sign/verify
@@ -446,3 +2537,472 @@ func FuzzSignVerify(f *testing.F) {
 		}
 	})
 }
+
+func TestNilInputs(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := Keccak256([]byte("nil"))
+	sig, err := Sign(kp.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	if _, err := Sign(nil, message); err != ErrInvalidPrivateKey {
+		t.Errorf("Sign(nil): expected ErrInvalidPrivateKey, got %v", err)
+	}
+	if _, err := SignConstantTime(nil, message); err != ErrInvalidPrivateKey {
+		t.Errorf("SignConstantTime(nil): expected ErrInvalidPrivateKey, got %v", err)
+	}
+	if Verify(nil, message, sig) || Verify(kp.Public, message, nil) || Verify(nil, message, nil) {
+		t.Error("Verify should return false for nil inputs")
+	}
+	if VerifyConstantTime(nil, message, sig) || VerifyConstantTime(kp.Public, message, nil) {
+		t.Error("VerifyConstantTime should return false for nil inputs")
+	}
+	if VerifyRange(nil, message, sig, 0, KeyBits) || VerifyRange(kp.Public, message, nil, 0, KeyBits) {
+		t.Error("VerifyRange should return false for nil inputs")
+	}
+	if _, _, ok := VerifyAgainstMessages(nil, sig, [][32]byte{message}); ok {
+		t.Error("VerifyAgainstMessages should not match a nil public key")
+	}
+	if _, _, ok := VerifyAgainstMessages(kp.Public, nil, [][32]byte{message}); ok {
+		t.Error("VerifyAgainstMessages should not match a nil signature")
+	}
+	pkh := kp.Public.Hash()
+	if VerifyWithPKH(nil, message, sig, pkh) || VerifyWithPKH(kp.Public, message, nil, pkh) {
+		t.Error("VerifyWithPKH should return false for nil inputs")
+	}
+	if VerifyThresholdMessage(nil, sig, message, pkh, [20]byte{1}, 1, pkh) {
+		t.Error("VerifyThresholdMessage should return false for a nil public key")
+	}
+	var nilSig *Signature
+	if nilSig.IsWellFormed() {
+		t.Error("A nil signature should not be well-formed")
+	}
+}
+
+func TestCompactSignature(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := Keccak256([]byte("compact"))
+	sig, err := Sign(kp.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	root := kp.Public.CompressedRoot()
+
+	cs, err := NewCompactSignature(kp.Public, message, sig)
+	if err != nil {
+		t.Fatalf("NewCompactSignature failed: %v", err)
+	}
+	for _, i := range []int{0, 137, KeyBits - 1} {
+		bit := int(NewBitVector(message)[i])
+		want := kp.Public.LeafProof(i, bit)
+		for d := range want {
+			if cs.Proofs[i][d] != want[d] {
+				t.Fatalf("Proof %d differs from LeafProof at depth %d", i, d)
+			}
+		}
+	}
+
+	data := cs.Bytes()
+	if len(data) != CompactSignatureSize {
+		t.Fatalf("Bytes length %d, want %d", len(data), CompactSignatureSize)
+	}
+	var decoded CompactSignature
+	if err := decoded.FromBytes(data); err != nil {
+		t.Fatalf("FromBytes failed: %v", err)
+	}
+	if !VerifyCompact(root, message, &decoded) {
+		t.Error("Round-tripped compact signature should verify against the root")
+	}
+	if VerifyCompact(root, Keccak256([]byte("other")), &decoded) {
+		t.Error("Compact signature should not verify for another message")
+	}
+
+	decoded.Proofs[5][3][0] ^= 1
+	if VerifyCompact(root, message, &decoded) {
+		t.Error("Tampered proof should not verify")
+	}
+	if VerifyCompact(root, message, nil) {
+		t.Error("Nil compact signature should not verify")
+	}
+	if err := decoded.FromBytes(data[1:]); err != ErrInvalidSignature {
+		t.Errorf("Expected ErrInvalidSignature, got %v", err)
+	}
+	if _, err := NewCompactSignature(kp.Public, Keccak256([]byte("other")), sig); err != ErrInvalidSignature {
+		t.Errorf("Expected ErrInvalidSignature for a non-verifying signature, got %v", err)
+	}
+}
+
+func TestPublicKeyIsDegenerate(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	if kp.Public.IsDegenerate() {
+		t.Error("Generated key should not be degenerate")
+	}
+
+	// Position 42 accepts one preimage for both bit values
+	priv := *kp.Private
+	priv.Preimages[42][1] = priv.Preimages[42][0]
+	priv.Used = false
+	pub := priv.DerivePublic()
+	if !pub.IsDegenerate() {
+		t.Error("Key with a colliding pair should be degenerate")
+	}
+
+	message := Keccak256([]byte("degenerate"))
+	sig, err := Sign(&priv, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if !VerifyWithPKH(pub, message, sig, pub.Hash()) {
+		t.Error("VerifyWithPKH stays permissive for degenerate keys")
+	}
+	if ok, err := VerifyWithPKHStrict(pub, message, sig, pub.Hash()); ok || err != ErrInvalidPublicKey {
+		t.Errorf("Expected ErrInvalidPublicKey for a degenerate key, got %v, %v", ok, err)
+	}
+
+	sig, err = Sign(kp.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if ok, err := VerifyWithPKHStrict(kp.Public, message, sig, kp.Public.Hash()); !ok || err != nil {
+		t.Errorf("Strict verification of a normal key failed: %v, %v", ok, err)
+	}
+}
+
+func TestSignatureDiff(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	sig, err := Sign(kp.Private, Keccak256([]byte("diff")))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	same := *sig
+	if d := sig.Diff(&same); len(d) != 0 {
+		t.Errorf("Identical signatures should not differ, got %v", d)
+	}
+
+	corrupted := *sig
+	want := []int{0, 17, 128, 255}
+	for _, i := range want {
+		corrupted.Preimages[i][31] ^= 0x80
+	}
+	if got := sig.Diff(&corrupted); !slices.Equal(got, want) {
+		t.Errorf("Diff = %v, want %v", got, want)
+	}
+	if got := corrupted.Diff(sig); !slices.Equal(got, want) {
+		t.Errorf("Diff should be symmetric, got %v", got)
+	}
+	if got := sig.Diff(nil); len(got) != KeyBits {
+		t.Errorf("Diff(nil) should report all %d positions, got %d", KeyBits, len(got))
+	}
+}
+
+func TestBitConvention(t *testing.T) {
+	message := Keccak256([]byte("convention"))
+	for i := 0; i < KeyBits; i++ {
+		if GetBitLSB(message, i) != int(message[31-i/8]>>(i%8)&1) {
+			t.Fatalf("GetBitLSB(%d) wrong", i)
+		}
+	}
+	var one [32]byte
+	one[31] = 1
+	if GetBitLSB(one, 0) != 1 || GetBit(one, 255) != 1 || GetBit(one, 0) != 0 {
+		t.Error("Bit 0 should be the uint256 LSB under LSBFirst and the MSB under MSBFirst")
+	}
+
+	for _, conv := range []BitOrder{MSBFirst, LSBFirst} {
+		kp, err := GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		sig, err := SignWithConvention(kp.Private, message, conv)
+		if err != nil {
+			t.Fatalf("SignWithConvention failed: %v", err)
+		}
+
+		// Position i reveals the preimage selected by the convention's bit i
+		for _, i := range []int{0, 7, 8, 255} {
+			bit := GetBit(message, i)
+			if conv == LSBFirst {
+				bit = GetBitLSB(message, i)
+			}
+			if sig.Preimages[i] != kp.Private.Preimages[i][bit] {
+				t.Errorf("convention %d: position %d revealed the wrong preimage", conv, i)
+			}
+		}
+
+		if !VerifyWithConvention(kp.Public, message, sig, conv) {
+			t.Errorf("convention %d: matched pair should verify", conv)
+		}
+		if !VerifyU256WithOrder(message, sig.Preimages, kp.Public.Hashes, conv) {
+			t.Errorf("convention %d: should agree with VerifyU256WithOrder", conv)
+		}
+		mismatched := LSBFirst
+		if conv == LSBFirst {
+			mismatched = MSBFirst
+		}
+		if VerifyWithConvention(kp.Public, message, sig, mismatched) {
+			t.Errorf("convention %d: mismatched convention should fail", conv)
+		}
+		if (conv == MSBFirst) != Verify(kp.Public, message, sig) {
+			t.Errorf("convention %d: only MSBFirst should match Verify", conv)
+		}
+	}
+}
+
+func TestSignedEnvelope(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	message := Keccak256([]byte("custody"))
+	sig, err := Sign(kp.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	env := &SignedEnvelope{
+		Signature: sig,
+		Metadata: map[string]string{
+			"signer":    "ops-1",
+			"timestamp": "2026-01-02T03:04:05Z",
+			"purpose":   "",
+		},
+	}
+
+	var decoded SignedEnvelope
+	if err := decoded.FromBytes(env.Bytes()); err != nil {
+		t.Fatalf("FromBytes failed: %v", err)
+	}
+	if *decoded.Signature != *sig {
+		t.Error("Round trip changed the signature")
+	}
+	if len(decoded.Metadata) != len(env.Metadata) {
+		t.Errorf("Expected %d metadata entries, got %d", len(env.Metadata), len(decoded.Metadata))
+	}
+	for k, v := range env.Metadata {
+		if got, ok := decoded.Metadata[k]; !ok || got != v {
+			t.Errorf("Metadata %q = %q, want %q", k, got, v)
+		}
+	}
+	if !decoded.Verify(kp.Public, message) {
+		t.Error("Inner signature should verify")
+	}
+
+	// Metadata is unauthenticated: changing it leaves the signature valid
+	decoded.Metadata["signer"] = "someone-else"
+	if !decoded.Verify(kp.Public, message) {
+		t.Error("Metadata must not affect verification")
+	}
+	if decoded.Verify(kp.Public, Keccak256([]byte("other"))) {
+		t.Error("Wrong message should fail")
+	}
+
+	// Encoding is deterministic and rejects malformed input
+	data := env.Bytes()
+	if !bytes.Equal(data, env.Bytes()) {
+		t.Error("Bytes should be deterministic")
+	}
+	for name, bad := range map[string][]byte{
+		"short":     data[:SignatureSize+3],
+		"truncated": data[:len(data)-1],
+		"trailing":  append(append([]byte{}, data...), 0),
+	} {
+		if err := decoded.FromBytes(bad); !errors.Is(err, ErrInvalidEnvelope) {
+			t.Errorf("%s: expected ErrInvalidEnvelope, got %v", name, err)
+		}
+	}
+}
+
+func TestSetHashFunc(t *testing.T) {
+	seed := [32]byte{1, 2, 3}
+	message := Keccak256([]byte("hash func"))
+	ref, err := GenerateKeyPairFromSeed(seed)
+	if err != nil {
+		t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+	}
+	refSig, err := Sign(ref.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	refThreshold := ComputeThresholdMessageEIP712(message, ref.Public.Hash(), [20]byte{1}, 1)
+
+	// Keys now exist, so the hash function is locked
+	if err := SetHashFunc(nil); err != ErrHashFuncLocked {
+		t.Errorf("Expected ErrHashFuncLocked, got %v", err)
+	}
+	defer setHashFunc(nil)
+
+	// An identical keccak256 implementation gives byte-identical output
+	calls := 0
+	setHashFunc(func() hash.Hash {
+		calls++
+		return sha3.NewLegacyKeccak256()
+	})
+	alt, err := GenerateKeyPairFromSeed(seed)
+	if err != nil {
+		t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+	}
+	altSig, err := Sign(alt.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if calls == 0 {
+		t.Fatal("Injected HashFunc was never called")
+	}
+	if alt.Private.Preimages != ref.Private.Preimages || *alt.Public != *ref.Public {
+		t.Error("Identical hash should give identical keys")
+	}
+	if *altSig != *refSig {
+		t.Error("Identical hash should give identical signatures")
+	}
+	if ComputeThresholdMessageEIP712(message, alt.Public.Hash(), [20]byte{1}, 1) != refThreshold {
+		t.Error("Identical hash should give identical EIP-712 messages")
+	}
+
+	// A different hash yields different PKHs but a working scheme
+	setHashFunc(sha3.New256)
+	other, err := GenerateKeyPairFromSeed(seed)
+	if err != nil {
+		t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+	}
+	if other.Public.Hash() == ref.Public.Hash() {
+		t.Error("Different hash should give a different PKH")
+	}
+	otherSig, err := Sign(other.Private, message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if !Verify(other.Public, message, otherSig) {
+		t.Error("Signature should verify under the hash that made it")
+	}
+	if Verify(ref.Public, message, otherSig) {
+		t.Error("Signature should not verify against a keccak256 key")
+	}
+}
+
+func TestGenerateSignedFixtures(t *testing.T) {
+	pub, message, sig, err := GenerateSignedFixture(NewSeededReader([32]byte{7}))
+	if err != nil {
+		t.Fatalf("GenerateSignedFixture failed: %v", err)
+	}
+	if !Verify(pub, message, sig) {
+		t.Error("Fixture should verify")
+	}
+
+	fixtures, err := GenerateSignedFixtures(8, NewSeededReader([32]byte{8}))
+	if err != nil {
+		t.Fatalf("GenerateSignedFixtures failed: %v", err)
+	}
+	if len(fixtures) != 8 {
+		t.Fatalf("Expected 8 fixtures, got %d", len(fixtures))
+	}
+	seen := make(map[[32]byte]bool)
+	for i, f := range fixtures {
+		if f.Public != fixtures[0].Public {
+			t.Errorf("Fixture %d: expected the shared public key", i)
+		}
+		if seen[f.Message] {
+			t.Errorf("Fixture %d: repeated message", i)
+		}
+		seen[f.Message] = true
+		if !Verify(f.Public, f.Message, f.Signature) {
+			t.Errorf("Fixture %d should verify", i)
+		}
+	}
+
+	if _, err := GenerateSignedFixtures(0, NewSeededReader([32]byte{})); err == nil {
+		t.Error("Expected error for zero fixtures")
+	}
+	if _, _, _, err := GenerateSignedFixture(bytes.NewReader(nil)); err == nil {
+		t.Error("Expected error for an exhausted reader")
+	}
+}
+
+func TestPreparedPublicKey(t *testing.T) {
+	fixtures, err := GenerateSignedFixtures(4, NewSeededReader([32]byte{10}))
+	if err != nil {
+		t.Fatalf("GenerateSignedFixtures failed: %v", err)
+	}
+	pub := fixtures[0].Public
+	prepared, err := NewPreparedPublicKey(pub)
+	if err != nil {
+		t.Fatalf("NewPreparedPublicKey failed: %v", err)
+	}
+
+	check := func(name string, message [32]byte, sig *Signature) {
+		t.Helper()
+		if got, want := prepared.Verify(message, sig), Verify(pub, message, sig); got != want {
+			t.Errorf("%s: prepared Verify = %v, Verify = %v", name, got, want)
+		}
+	}
+	for i, f := range fixtures {
+		if !prepared.Verify(f.Message, f.Signature) {
+			t.Errorf("Fixture %d should verify", i)
+		}
+		check(fmt.Sprintf("fixture %d", i), f.Message, f.Signature)
+		check(fmt.Sprintf("fixture %d, other message", i), fixtures[(i+1)%len(fixtures)].Message, f.Signature)
+
+		tampered := *f.Signature
+		tampered.Preimages[KeyBits-1][0] ^= 1
+		check(fmt.Sprintf("fixture %d, tampered", i), f.Message, &tampered)
+	}
+	check("zero signature", fixtures[0].Message, &Signature{})
+	check("nil signature", fixtures[0].Message, nil)
+
+	if _, err := NewPreparedPublicKey(nil); err != ErrInvalidPublicKey {
+		t.Errorf("Expected ErrInvalidPublicKey, got %v", err)
+	}
+}
+
+func TestSignBytesZeroMessage(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	zero := make([]byte, 32)
+	if _, err := SignBytes(kp.Private, zero); err != ErrZeroMessage {
+		t.Fatalf("Expected ErrZeroMessage, got %v", err)
+	}
+	if kp.Private.Used {
+		t.Error("Refused message should not consume the key")
+	}
+
+	message := Keccak256([]byte("not zero"))
+	sig, err := SignBytes(kp.Private, message[:])
+	if err != nil {
+		t.Fatalf("SignBytes failed: %v", err)
+	}
+	if !Verify(kp.Public, message, sig) {
+		t.Error("Signature should verify")
+	}
+
+	// Sign stays unguarded, and the guard can be turned off
+	kp2, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	if _, err := Sign(kp2.Private, [32]byte{}); err != nil {
+		t.Errorf("Sign should accept the zero message, got %v", err)
+	}
+	SetZeroMessageGuard(false)
+	defer SetZeroMessageGuard(true)
+	kp3, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	if _, err := SignBytes(kp3.Private, zero); err != nil {
+		t.Errorf("Disabled guard should allow the zero message, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package primitives
+
+// MerkleDepth is the depth of the public key Merkle tree (2^9 = 512 leaves)
+const MerkleDepth = 9
+
+// CompressedRoot returns the Merkle root over the 512 public key hashes.
+//
+// Tree layout (for Solidity ports):
+//   - Leaf 2*i + bit is Hashes[i][bit], in the same order as Bytes().
+//     Leaves are used as-is; they are already keccak256 outputs.
+//   - The tree is perfect with depth MerkleDepth. A parent at level l+1 is
+//     keccak256(left || right) of its children at level l, where the left
+//     child has the even index.
+//
+// A verifier holding only the root can check a revealed preimage for
+// position i with VerifyPreimageProof and the 9-element path from LeafProof,
+// instead of receiving the full 16 KB key.
+func (pk *PublicKey) CompressedRoot() [32]byte {
+	return merkleRoot(pk.leaves())
+}
+
+// LeafProof returns the Merkle path for leaf (i, bit): the sibling hashes
+// from the leaf level up to just below the root, MerkleDepth entries long.
+func (pk *PublicKey) LeafProof(i, bit int) [][32]byte {
+	return merkleProof(pk.leaves(), 2*i+bit)
+}
+
+// VerifyLeafProof checks that leaf is Hashes[i][bit] of the public key with
+// the given compressed root.
+func VerifyLeafProof(root [32]byte, i, bit int, leaf [32]byte, proof [][32]byte) bool {
+	if i < 0 || i >= KeyBits || bit < 0 || bit > 1 || len(proof) != MerkleDepth {
+		return false
+	}
+	return merkleVerify(root, 2*i+bit, leaf, proof)
+}
+
+// VerifyPreimageProof checks that preimage is the secret for position i and
+// side bit of the public key with the given compressed root.
+func VerifyPreimageProof(root [32]byte, i, bit int, preimage [PreimageSize]byte, proof [][32]byte) bool {
+	return VerifyLeafProof(root, i, bit, Keccak256(preimage[:]), proof)
+}
+
+// leaves returns the 512 Merkle leaves in Bytes() order.
+func (pk *PublicKey) leaves() [][32]byte {
+	leaves := make([][32]byte, 2*KeyBits)
+	for i := 0; i < KeyBits; i++ {
+		leaves[2*i] = pk.Hashes[i][0]
+		leaves[2*i+1] = pk.Hashes[i][1]
+	}
+	return leaves
+}
+
+// merkleRoot computes a binary Merkle root over leaves, promoting odd nodes.
+func merkleRoot(leaves [][32]byte) [32]byte {
+	if len(leaves) == 0 {
+		return [32]byte{}
+	}
+
+	level := leaves
+	for len(level) > 1 {
+		level = merkleLevel(level)
+	}
+	return level[0]
+}
+
+// merkleLevel hashes one level of the tree into its parents.
+func merkleLevel(level [][32]byte) [][32]byte {
+	next := make([][32]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+			continue
+		}
+		next = append(next, Keccak256Multi(level[i][:], level[i+1][:]))
+	}
+	return next
+}
+
+// merkleProof returns the sibling path for leaves[idx]. Levels where the
+// node has no sibling (odd promotion) contribute no entry.
+func merkleProof(leaves [][32]byte, idx int) [][32]byte {
+	var proof [][32]byte
+	level := leaves
+	for len(level) > 1 {
+		sibling := idx ^ 1
+		if sibling < len(level) {
+			proof = append(proof, level[sibling])
+		}
+		level = merkleLevel(level)
+		idx /= 2
+	}
+	return proof
+}
+
+// merkleVerify recomputes the root from a leaf and a full (no promotion) path.
+func merkleVerify(root [32]byte, idx int, leaf [32]byte, proof [][32]byte) bool {
+	node := leaf
+	for _, sibling := range proof {
+		if idx%2 == 0 {
+			node = Keccak256Multi(node[:], sibling[:])
+		} else {
+			node = Keccak256Multi(sibling[:], node[:])
+		}
+		idx /= 2
+	}
+	return idx == 0 && node == root
+}
@@ -0,0 +1,45 @@
+package primitives
+
+import "hash"
+
+// MessageBuilder computes keccak256(abi.encodePacked(...)) from typed fields,
+// so callers never compute byte offsets by hand. Fields are hashed in the
+// order they are added:
+//
+//	msg := NewMessageBuilder().Add32(safeTxHash).Add32(nextPKH).
+//		Add20(moduleAddress).AddUint256(chainID).Hash()
+type MessageBuilder struct {
+	h hash.Hash
+}
+
+// NewMessageBuilder returns an empty builder.
+func NewMessageBuilder() *MessageBuilder {
+	return &MessageBuilder{h: newHasher()}
+}
+
+// Add32 appends a bytes32 (or uint256 already encoded big-endian).
+func (b *MessageBuilder) Add32(v [32]byte) *MessageBuilder {
+	b.h.Write(v[:])
+	return b
+}
+
+// Add20 appends an address.
+func (b *MessageBuilder) Add20(v [20]byte) *MessageBuilder {
+	b.h.Write(v[:])
+	return b
+}
+
+// AddUint256 appends v zero-extended to a big-endian uint256.
+func (b *MessageBuilder) AddUint256(v uint64) *MessageBuilder {
+	u := ChainIDFromUint64(v)
+	b.h.Write(u[:])
+	return b
+}
+
+// Hash returns keccak256 of everything added so far. Further fields may
+// still be added afterwards.
+func (b *MessageBuilder) Hash() [32]byte {
+	var result [HashSize]byte
+	b.h.Sum(result[:0])
+	return result
+}
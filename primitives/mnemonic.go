@@ -0,0 +1,95 @@
+package primitives
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MnemonicWords is the number of words encoding a 32-byte seed
+// (256 bits of entropy + 8 bits of checksum = 24 * 11 bits).
+const MnemonicWords = 24
+
+var (
+	// ErrInvalidMnemonicLength indicates the phrase does not have MnemonicWords words
+	ErrInvalidMnemonicLength = errors.New("lamport: invalid mnemonic length (must be 24 words)")
+
+	// ErrInvalidMnemonicWord indicates a word is not in the BIP39 English wordlist
+	ErrInvalidMnemonicWord = errors.New("lamport: invalid mnemonic word")
+
+	// ErrInvalidMnemonicChecksum indicates the phrase checksum does not match
+	ErrInvalidMnemonicChecksum = errors.New("lamport: invalid mnemonic checksum")
+)
+
+//go:embed bip39_english.txt
+var bip39English string
+
+var (
+	bip39Words = strings.Fields(bip39English)
+	bip39Index = func() map[string]int {
+		m := make(map[string]int, len(bip39Words))
+		for i, w := range bip39Words {
+			m[w] = i
+		}
+		return m
+	}()
+)
+
+// MnemonicFromSeed encodes a 32-byte master seed as a 24-word BIP39 phrase
+// using the standard English wordlist and SHA-256 checksum.
+//
+// The seed is used directly as BIP39 entropy, so the phrase round-trips to
+// the exact same seed via SeedFromMnemonic. This is NOT the BIP39 PBKDF2
+// "seed"; no passphrase stretching is applied.
+func MnemonicFromSeed(seed [32]byte) (string, error) {
+	checksum := sha256.Sum256(seed[:])
+
+	// 264 bits: entropy followed by the first 8 bits of the checksum
+	var data [33]byte
+	copy(data[:32], seed[:])
+	data[32] = checksum[0]
+
+	words := make([]string, MnemonicWords)
+	for w := 0; w < MnemonicWords; w++ {
+		idx := 0
+		for b := 0; b < 11; b++ {
+			pos := w*11 + b
+			idx = idx<<1 | int((data[pos/8]>>(7-pos%8))&1)
+		}
+		words[w] = bip39Words[idx]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// SeedFromMnemonic decodes a 24-word BIP39 phrase back to the 32-byte master seed.
+// Words are matched case-insensitively and may be separated by any whitespace.
+func SeedFromMnemonic(phrase string) ([32]byte, error) {
+	words := strings.Fields(strings.ToLower(phrase))
+	if len(words) != MnemonicWords {
+		return [32]byte{}, ErrInvalidMnemonicLength
+	}
+
+	var data [33]byte
+	for w, word := range words {
+		idx, ok := bip39Index[word]
+		if !ok {
+			return [32]byte{}, fmt.Errorf("%w: %q (word %d)", ErrInvalidMnemonicWord, word, w+1)
+		}
+		for b := 0; b < 11; b++ {
+			if idx&(1<<(10-b)) != 0 {
+				pos := w*11 + b
+				data[pos/8] |= 1 << (7 - pos%8)
+			}
+		}
+	}
+
+	var seed [32]byte
+	copy(seed[:], data[:32])
+	checksum := sha256.Sum256(seed[:])
+	if checksum[0] != data[32] {
+		return [32]byte{}, ErrInvalidMnemonicChecksum
+	}
+	return seed, nil
+}
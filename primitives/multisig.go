@@ -0,0 +1,96 @@
+package primitives
+
+import "errors"
+
+// ErrDuplicateSigner indicates the same public key was added twice to a multisig
+var ErrDuplicateSigner = errors.New("lamport: duplicate signer in multisig")
+
+// MultiSig collects signatures from k independent Lamport keys over one message.
+//
+// This is NOT the MPC threshold flow: each signer owns a complete key pair and
+// produces a complete signature. The group is valid only if every signature
+// verifies against its own public key.
+type MultiSig struct {
+	// Message is the 32-byte message every signer signs
+	Message [32]byte
+
+	// PublicKeys and Signatures are parallel slices, one entry per signer
+	PublicKeys []*PublicKey
+	Signatures []*Signature
+}
+
+// NewMultiSig creates an empty multisig for the given message.
+func NewMultiSig(message [32]byte) *MultiSig {
+	return &MultiSig{Message: message}
+}
+
+// Add verifies a signer's signature and adds it to the multisig.
+// Returns ErrVerificationFailed if the signature is invalid and
+// ErrDuplicateSigner if the public key is already present.
+func (m *MultiSig) Add(pub *PublicKey, sig *Signature) error {
+	pkh := pub.Hash()
+	for _, existing := range m.PublicKeys {
+		if existing.Hash() == pkh {
+			return ErrDuplicateSigner
+		}
+	}
+
+	if !Verify(pub, m.Message, sig) {
+		return ErrVerificationFailed
+	}
+
+	m.PublicKeys = append(m.PublicKeys, pub)
+	m.Signatures = append(m.Signatures, sig)
+	return nil
+}
+
+// Len returns the number of collected signers.
+func (m *MultiSig) Len() int {
+	return len(m.PublicKeys)
+}
+
+// Verify checks that every collected signature is valid.
+func (m *MultiSig) Verify() bool {
+	return VerifyMultiSig(m.PublicKeys, m.Message, m.Signatures)
+}
+
+// GroupPKH returns the Merkle root of the signer PKHs (see MultiSigPKH).
+func (m *MultiSig) GroupPKH() [32]byte {
+	return MultiSigPKH(m.PublicKeys)
+}
+
+// VerifyMultiSig verifies k independent signatures over the same message.
+// Returns true only if there is at least one signer, the slices have equal
+// length, no public key appears twice, and every signature verifies.
+func VerifyMultiSig(pubs []*PublicKey, message [32]byte, sigs []*Signature) bool {
+	if len(pubs) == 0 || len(pubs) != len(sigs) {
+		return false
+	}
+
+	seen := make(map[[32]byte]struct{}, len(pubs))
+	for i := range pubs {
+		pkh := pubs[i].Hash()
+		if _, dup := seen[pkh]; dup {
+			return false
+		}
+		seen[pkh] = struct{}{}
+
+		if !Verify(pubs[i], message, sigs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// MultiSigPKH computes the group identity of a set of signers as the Merkle
+// root of their PKHs, in the order given.
+//
+// Interior nodes are keccak256(left || right). An odd node at any level is
+// promoted unchanged. A single signer's group PKH is its own PKH.
+func MultiSigPKH(pubs []*PublicKey) [32]byte {
+	leaves := make([][32]byte, len(pubs))
+	for i, pub := range pubs {
+		leaves[i] = pub.Hash()
+	}
+	return merkleRoot(leaves)
+}
@@ -0,0 +1,47 @@
+package primitives
+
+// PreparedPublicKey is a public key laid out for repeated verification,
+// e.g. a gateway checking many signatures from one signer's key chain
+// entry or a benchmark. The hash table is flattened so the expected hash
+// for position i and side bit is entry 2*i+bit, and each Verify reuses a
+// single hasher across its 256 hashes instead of creating one per preimage.
+//
+// A PreparedPublicKey is immutable and safe for concurrent use.
+type PreparedPublicKey struct {
+	hashes [2 * KeyBits][HashSize]byte
+}
+
+// NewPreparedPublicKey prepares pub for repeated verification. It returns
+// ErrInvalidPublicKey for a nil key.
+func NewPreparedPublicKey(pub *PublicKey) (*PreparedPublicKey, error) {
+	if pub == nil {
+		return nil, ErrInvalidPublicKey
+	}
+	p := &PreparedPublicKey{}
+	for i := 0; i < KeyBits; i++ {
+		p.hashes[2*i] = pub.Hashes[i][0]
+		p.hashes[2*i+1] = pub.Hashes[i][1]
+	}
+	return p, nil
+}
+
+// Verify checks sig for message and returns the same result as Verify with
+// the original public key.
+func (p *PreparedPublicKey) Verify(message [32]byte, sig *Signature) bool {
+	if !sig.IsWellFormed() {
+		return countVerify(false)
+	}
+
+	h := newHasher()
+	var digest [HashSize]byte
+	for i := 0; i < KeyBits; i++ {
+		bit := int(message[i/8]>>(7-i%8)) & 1
+		h.Reset()
+		h.Write(sig.Preimages[i][:])
+		h.Sum(digest[:0])
+		if digest != p.hashes[2*i+bit] {
+			return countVerify(false)
+		}
+	}
+	return countVerify(true)
+}
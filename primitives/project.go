@@ -0,0 +1,44 @@
+package primitives
+
+// Projection is a public key projected for one message: the 256 hashes the
+// message selects, together with that message.
+type Projection struct {
+	// Message is the message the projection was made for
+	Message [32]byte
+
+	// Hashes holds entry i = Hashes[i][bit i of Message] of the full key
+	Hashes [KeyBits][HashSize]byte
+}
+
+// ProjectForMessage returns the projection of pk for message. A thin
+// verifier that only checks signatures over this committed message needs
+// half the public key.
+//
+// SECURITY: the projection cannot be hashed back to the PKH, so the thin
+// verifier must trust whoever projected it (or check it against a
+// CompressedRoot with LeafProof) to have used the right public key.
+func (pk *PublicKey) ProjectForMessage(message [32]byte) *Projection {
+	p := &Projection{Message: message}
+	bits := NewBitVector(message)
+	for i := 0; i < KeyBits; i++ {
+		p.Hashes[i] = pk.Hashes[i][bits[i]]
+	}
+	return p
+}
+
+// VerifyProjected checks a signature for message against a projection made
+// by ProjectForMessage. For a projection of the right key for message it
+// returns the same result as Verify with the full public key. A nil
+// projection, or one made for a different message, fails: its hashes sit on
+// the sides chosen by that other message, not by the bits of this one.
+func VerifyProjected(p *Projection, message [32]byte, sig *Signature) bool {
+	if p == nil || p.Message != message || !sig.IsWellFormed() {
+		return false
+	}
+	for i := 0; i < KeyBits; i++ {
+		if Keccak256(sig.Preimages[i][:]) != p.Hashes[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package primitives
+
+import "math/bits"
+
+// RemainingSecurityBits estimates the forgery resistance, in bits, of a key
+// after reuse has exposed both preimages at revealedBothSides bit positions.
+//
+// Model: at a position with both preimages known, the attacker can sign
+// either bit value. At every other position only the one preimage already
+// revealed is known (or none), so a forged message must match that bit.
+// A random target message matches all 256 - revealedBothSides constrained
+// positions with probability 2^-(256 - revealedBothSides), giving
+//
+//	256 - revealedBothSides
+//
+// bits. Two signatures over messages m1 and m2 expose both sides exactly at
+// the positions where m1 and m2 differ (popcount(m1 XOR m2), about 128 for
+// hashed messages), so a single reuse typically leaves ~128 bits, and each
+// further reuse erodes it quickly. The input is clamped to [0, KeyBits].
+func RemainingSecurityBits(revealedBothSides int) float64 {
+	if revealedBothSides < 0 {
+		revealedBothSides = 0
+	}
+	if revealedBothSides > KeyBits {
+		revealedBothSides = KeyBits
+	}
+	return float64(KeyBits - revealedBothSides)
+}
+
+// BitDifference returns popcount(m1 XOR m2): the number of positions at
+// which two signatures by the same key over m1 and m2 reveal both preimages.
+func BitDifference(m1, m2 [32]byte) int {
+	n := 0
+	for i := range m1 {
+		n += bits.OnesCount8(m1[i] ^ m2[i])
+	}
+	return n
+}
+
+// ExposedPositionsAfter returns how many of the 256 positions would have both
+// preimages revealed if every message were signed with one key: positions
+// where at least one message has a 0 bit and another a 1 bit. Feed the
+// result to RemainingSecurityBits to size a planned few-time use.
+func ExposedPositionsAfter(messages [][32]byte) int {
+	if len(messages) == 0 {
+		return 0
+	}
+	var anyOne [32]byte
+	allOne := messages[0]
+	for _, m := range messages {
+		for i := range m {
+			anyOne[i] |= m[i]
+			allOne[i] &= m[i]
+		}
+	}
+	return BitDifference(anyOne, allOne)
+}
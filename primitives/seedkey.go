@@ -0,0 +1,75 @@
+package primitives
+
+// SeedPrivateKey is a Lamport private key held as a 32-byte seed instead of
+// 16 KB of preimages. Each preimage is derived on demand as
+//
+//	preimage[i][bit] = keccak256(seed || uint16(i) || uint8(bit))
+//
+// which is the same derivation as GenerateKeyPairFromSeed, so both produce
+// identical keys from the same seed.
+//
+// SeedPrivateKey implements Signer.
+// SECURITY: This key MUST only be used to sign ONE message.
+type SeedPrivateKey struct {
+	seed [32]byte
+
+	// Used tracks whether this key has been used (one-time property)
+	Used bool
+}
+
+// NewSeedPrivateKey creates a seed-backed private key.
+func NewSeedPrivateKey(seed [32]byte) *SeedPrivateKey {
+	return &SeedPrivateKey{seed: seed}
+}
+
+// Preimage derives the preimage for bit position i and side bit.
+func (k *SeedPrivateKey) Preimage(i, bit int) [PreimageSize]byte {
+	return seedPreimage(k.seed, i, bit)
+}
+
+// Sign signs message, deriving only the 256 revealed preimages, and marks the key as used.
+func (k *SeedPrivateKey) Sign(message [32]byte) (*Signature, error) {
+	if k.Used {
+		return nil, ErrKeyAlreadyUsed
+	}
+
+	sig := &Signature{}
+	bits := NewBitVector(message)
+	for i := 0; i < KeyBits; i++ {
+		sig.Preimages[i] = seedPreimage(k.seed, i, int(bits[i]))
+	}
+
+	k.Used = true
+	return sig, nil
+}
+
+// PublicKey derives the full public key. This costs 1024 keccak256 calls
+// (512 preimages plus their hashes); callers that need it repeatedly should
+// keep the result or just its Hash.
+func (k *SeedPrivateKey) PublicKey() *PublicKey {
+	LockHashFunc()
+	pub := &PublicKey{}
+	for i := 0; i < KeyBits; i++ {
+		for bit := 0; bit < 2; bit++ {
+			preimage := seedPreimage(k.seed, i, bit)
+			pub.Hashes[i][bit] = Keccak256(preimage[:])
+		}
+	}
+	return pub
+}
+
+// Hash returns the PKH of the derived public key.
+func (k *SeedPrivateKey) Hash() [PublicKeyHashSize]byte {
+	return k.PublicKey().Hash()
+}
+
+// Expand derives the full 16 KB PrivateKey, carrying over the Used flag.
+func (k *SeedPrivateKey) Expand() *PrivateKey {
+	priv := &PrivateKey{Used: k.Used}
+	for i := 0; i < KeyBits; i++ {
+		for bit := 0; bit < 2; bit++ {
+			priv.Preimages[i][bit] = seedPreimage(k.seed, i, bit)
+		}
+	}
+	return priv
+}
@@ -1,5 +1,7 @@
 package primitives
 
+import "sync/atomic"
+
 // Sign creates a Lamport signature for a 32-byte message.
 //
 // SECURITY: This function should only be called ONCE per private key.
@@ -9,30 +11,86 @@ package primitives
 //   - If bit i is 0, reveal preimage[i][0]
 //   - If bit i is 1, reveal preimage[i][1]
 func Sign(priv *PrivateKey, message [32]byte) (*Signature, error) {
+	if priv == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	if priv.Used {
+		count(&counters.keyReuseAttempts)
+		return nil, ErrKeyAlreadyUsed
+	}
+
+	sig := &Signature{}
+	bits := NewBitVector(message)
+
+	for i := 0; i < KeyBits; i++ {
+		sig.Preimages[i] = priv.Preimages[i][bits[i]]
+	}
+
+	// Mark key as used
+	priv.Used = true
+	count(&counters.signatures)
+
+	return sig, nil
+}
+
+// SignConstantTime creates a Lamport signature like Sign, but selects each
+// revealed preimage with a constant-time mask instead of indexing by the
+// message bit. Both preimages at every position are read, so the memory
+// access pattern does not depend on the message.
+//
+// The output is byte-identical to Sign, and the key is marked as used.
+func SignConstantTime(priv *PrivateKey, message [32]byte) (*Signature, error) {
+	if priv == nil {
+		return nil, ErrInvalidPrivateKey
+	}
 	if priv.Used {
+		count(&counters.keyReuseAttempts)
 		return nil, ErrKeyAlreadyUsed
 	}
 
 	sig := &Signature{}
+	bits := NewBitVector(message)
 
 	for i := 0; i < KeyBits; i++ {
-		bit := GetBit(message, i)
-		sig.Preimages[i] = priv.Preimages[i][bit]
+		mask := -bits[i] // 0x00 if bit is 0, 0xFF if bit is 1
+		p0 := &priv.Preimages[i][0]
+		p1 := &priv.Preimages[i][1]
+		for k := 0; k < PreimageSize; k++ {
+			sig.Preimages[i][k] = (p0[k] &^ mask) | (p1[k] & mask)
+		}
 	}
 
 	// Mark key as used
 	priv.Used = true
+	count(&counters.signatures)
 
 	return sig, nil
 }
 
+// allowZeroMessage disables the SignBytes zero-message guard.
+var allowZeroMessage atomic.Bool
+
+// SetZeroMessageGuard turns the SignBytes zero-message guard on or off. The
+// guard is on by default.
+func SetZeroMessageGuard(enabled bool) {
+	allowZeroMessage.Store(!enabled)
+}
+
 // SignBytes signs a 32-byte message slice.
+//
+// Unless disabled with SetZeroMessageGuard, an all-zero message returns
+// ErrZeroMessage without using the key: it almost always means an
+// uninitialized buffer, and its signature reveals a predictable preimage
+// set. Sign does not apply this guard.
 func SignBytes(priv *PrivateKey, message []byte) (*Signature, error) {
 	if len(message) != 32 {
 		return nil, ErrInvalidMessage
 	}
 	var msg [32]byte
 	copy(msg[:], message)
+	if msg == [32]byte{} && !allowZeroMessage.Load() {
+		return nil, ErrZeroMessage
+	}
 	return Sign(priv, msg)
 }
 
@@ -40,10 +98,10 @@ func SignBytes(priv *PrivateKey, message []byte) (*Signature, error) {
 // INTERNAL: Only accessible within this package for testing.
 func signUnsafe(priv *PrivateKey, message [32]byte) *Signature {
 	sig := &Signature{}
+	bits := NewBitVector(message)
 
 	for i := 0; i < KeyBits; i++ {
-		bit := GetBit(message, i)
-		sig.Preimages[i] = priv.Preimages[i][bit]
+		sig.Preimages[i] = priv.Preimages[i][bits[i]]
 	}
 
 	return sig
@@ -51,29 +109,61 @@ func signUnsafe(priv *PrivateKey, message [32]byte) *Signature {
 
 // SignWithKeyChain signs a message using the current key in the chain
 // and automatically advances to the next key.
+// Keys backed by an external Signer (see NewKeyChainFromSigners) are signed remotely.
+//
+// With SetAutoExtend, the chain is extended before signing if it is already
+// at the threshold, so nextPKH is always available.
+//
+// The key is reserved under the chain lock and signed after it is released,
+// so a slow Signer does not block other users of the chain. A reserved key
+// is consumed even if signing fails.
 func SignWithKeyChain(chain *KeyChain, message [32]byte) (*Signature, [32]byte, error) {
-	kp, err := chain.Current()
+	signer, nextPKH, err := chain.reserve()
 	if err != nil {
 		return nil, [32]byte{}, err
 	}
 
-	sig, err := Sign(kp.Private, message)
+	sig, err := signer.Sign(message)
+	if err != nil {
+		return nil, [32]byte{}, err
+	}
+	return sig, nextPKH, nil
+}
+
+// reserve takes the current key for signing and advances past it, returning
+// its Signer and the PKH of the key that follows.
+func (kc *KeyChain) reserve() (Signer, [32]byte, error) {
+	kc.mu.Lock()
+	defer kc.unlockAndNotify()
+
+	if err := kc.maybeExtend(); err != nil {
+		return nil, [32]byte{}, err
+	}
+
+	signer, err := kc.signer()
 	if err != nil {
 		return nil, [32]byte{}, err
 	}
 
 	// Get next PKH before advancing (if available)
 	var nextPKH [32]byte
-	if chain.CurrentIndex+1 < len(chain.Keys) {
-		nextPKH = chain.Keys[chain.CurrentIndex+1].Public.Hash()
+	if kc.CurrentIndex+1 < len(kc.Keys) {
+		nextPKH = kc.Keys[kc.CurrentIndex+1].Public.Hash()
 	}
 
-	// Advance to next key
-	if err := chain.Advance(); err != nil {
+	// Advance to next key; a LocalSigner marks the private key used itself
+	if err := kc.step(); err != nil {
 		return nil, [32]byte{}, err
 	}
+	return signer, nextPKH, nil
+}
 
-	return sig, nextPKH, nil
+// SignWithConvention is Sign with the message bits read under conv: the
+// preimage revealed at position i is selected by bit i of message as conv
+// defines it. The signature only verifies with VerifyWithConvention and the
+// same conv; MSBFirst is identical to Sign.
+func SignWithConvention(priv *PrivateKey, message [32]byte, conv BitOrder) (*Signature, error) {
+	return Sign(priv, conv.canonical(message))
 }
 
 // SignThresholdMessage signs a domain-separated threshold message.
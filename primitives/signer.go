@@ -0,0 +1,40 @@
+package primitives
+
+// Signer produces Lamport signatures without exposing private preimages to
+// the caller. Implementations may keep the key in memory (LocalSigner), in an
+// HSM, or behind a remote signing service.
+//
+// SECURITY: Implementations MUST enforce the one-time property themselves;
+// a Signer must refuse to sign a second message with the same key.
+type Signer interface {
+	// PublicKey returns the public key matching the signing key.
+	PublicKey() *PublicKey
+
+	// Sign signs a 32-byte message with the one-time key.
+	Sign(message [32]byte) (*Signature, error)
+}
+
+// LocalSigner is an in-memory Signer wrapping a *PrivateKey.
+type LocalSigner struct {
+	priv *PrivateKey
+	pub  *PublicKey
+}
+
+// NewLocalSigner creates a Signer for priv. If pub is nil it is recomputed
+// from the private preimages on first use.
+func NewLocalSigner(priv *PrivateKey, pub *PublicKey) *LocalSigner {
+	return &LocalSigner{priv: priv, pub: pub}
+}
+
+// PublicKey returns the public key matching the wrapped private key.
+func (s *LocalSigner) PublicKey() *PublicKey {
+	if s.pub == nil {
+		s.pub = s.priv.DerivePublic()
+	}
+	return s.pub
+}
+
+// Sign signs message with the wrapped private key and marks it as used.
+func (s *LocalSigner) Sign(message [32]byte) (*Signature, error) {
+	return Sign(s.priv, message)
+}
@@ -0,0 +1,66 @@
+package primitives
+
+import "sync/atomic"
+
+// Stats is a snapshot of the package's operation counters.
+type Stats struct {
+	KeysGenerated    uint64 // key pairs from GenerateKeyPair* (including key chains)
+	Signatures       uint64 // successful Sign and SignConstantTime calls
+	VerifySuccess    uint64 // Verify and VerifyConstantTime calls returning true
+	VerifyFail       uint64 // Verify and VerifyConstantTime calls returning false
+	KeyReuseAttempts uint64 // signing attempts rejected with ErrKeyAlreadyUsed
+}
+
+// statsEnabled gates counting so unused stats cost one atomic load per call.
+var statsEnabled atomic.Bool
+
+var counters struct {
+	keysGenerated    atomic.Uint64
+	signatures       atomic.Uint64
+	verifySuccess    atomic.Uint64
+	verifyFail       atomic.Uint64
+	keyReuseAttempts atomic.Uint64
+}
+
+// EnableStats turns operation counting on or off. Counting is off by default.
+func EnableStats(enabled bool) {
+	statsEnabled.Store(enabled)
+}
+
+// ReadStats returns the current counter values. Counters are read
+// individually, so a snapshot taken under load is not atomic as a whole.
+func ReadStats() Stats {
+	return Stats{
+		KeysGenerated:    counters.keysGenerated.Load(),
+		Signatures:       counters.signatures.Load(),
+		VerifySuccess:    counters.verifySuccess.Load(),
+		VerifyFail:       counters.verifyFail.Load(),
+		KeyReuseAttempts: counters.keyReuseAttempts.Load(),
+	}
+}
+
+// ResetStats sets every counter to zero.
+func ResetStats() {
+	counters.keysGenerated.Store(0)
+	counters.signatures.Store(0)
+	counters.verifySuccess.Store(0)
+	counters.verifyFail.Store(0)
+	counters.keyReuseAttempts.Store(0)
+}
+
+// count increments c if stats are enabled.
+func count(c *atomic.Uint64) {
+	if statsEnabled.Load() {
+		c.Add(1)
+	}
+}
+
+// countVerify records a verification result and returns it.
+func countVerify(ok bool) bool {
+	if ok {
+		count(&counters.verifySuccess)
+	} else {
+		count(&counters.verifyFail)
+	}
+	return ok
+}
@@ -0,0 +1,64 @@
+package primitives
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrUnknownPKH indicates no public key is registered for a PKH
+var ErrUnknownPKH = errors.New("lamport: unknown public key hash")
+
+// PublicKeyStore resolves a public key hash to the full public key.
+type PublicKeyStore interface {
+	Get(pkh [32]byte) (*PublicKey, bool)
+}
+
+// MemoryPublicKeyStore is an in-memory PublicKeyStore safe for concurrent use.
+type MemoryPublicKeyStore struct {
+	mu   sync.RWMutex
+	keys map[[32]byte]*PublicKey
+}
+
+// NewMemoryPublicKeyStore creates an empty in-memory store.
+func NewMemoryPublicKeyStore() *MemoryPublicKeyStore {
+	return &MemoryPublicKeyStore{keys: make(map[[32]byte]*PublicKey)}
+}
+
+// Put registers a public key under its hash and returns the hash.
+func (s *MemoryPublicKeyStore) Put(pub *PublicKey) [32]byte {
+	pkh := pub.Hash()
+	s.mu.Lock()
+	s.keys[pkh] = pub
+	s.mu.Unlock()
+	return pkh
+}
+
+// Get returns the public key registered for pkh.
+func (s *MemoryPublicKeyStore) Get(pkh [32]byte) (*PublicKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	pub, ok := s.keys[pkh]
+	return pub, ok
+}
+
+// Len returns the number of registered keys.
+func (s *MemoryPublicKeyStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.keys)
+}
+
+// VerifyByPKH resolves pkh in the store and verifies the signature against
+// the resulting public key. Returns ErrUnknownPKH if the store has no key for
+// pkh, and ErrInvalidPublicKey if the stored key does not hash to pkh.
+// An invalid signature returns false with a nil error.
+func VerifyByPKH(store PublicKeyStore, pkh [32]byte, message [32]byte, sig *Signature) (bool, error) {
+	pub, ok := store.Get(pkh)
+	if !ok {
+		return false, ErrUnknownPKH
+	}
+	if !ConstantTimeEqualHash(pub.Hash(), pkh) {
+		return false, ErrInvalidPublicKey
+	}
+	return Verify(pub, message, sig), nil
+}
@@ -0,0 +1,42 @@
+package primitives
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// seededReader is a deterministic keccak256 counter-mode stream.
+type seededReader struct {
+	seed    [32]byte
+	counter uint64
+	block   [HashSize]byte
+	off     int
+}
+
+// NewSeededReader returns an io.Reader producing the deterministic stream
+//
+//	keccak256(seed || uint64(0)) || keccak256(seed || uint64(1)) || ...
+//
+// It never returns an error. Use it where reproducible key material is
+// required (tests, disaster recovery); the output is only as secret as seed.
+func NewSeededReader(seed [32]byte) io.Reader {
+	return &seededReader{seed: seed, off: HashSize}
+}
+
+func (r *seededReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if r.off == HashSize {
+			var buf [40]byte
+			copy(buf[:32], r.seed[:])
+			binary.BigEndian.PutUint64(buf[32:], r.counter)
+			r.block = Keccak256(buf[:])
+			r.counter++
+			r.off = 0
+		}
+		c := copy(p[n:], r.block[r.off:])
+		r.off += c
+		n += c
+	}
+	return n, nil
+}
@@ -12,11 +12,12 @@ package primitives
 
 import (
 	"crypto/rand"
+	"encoding/base64"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
-
-	"golang.org/x/crypto/sha3"
+	"sync"
 )
 
 const (
@@ -49,6 +50,9 @@ var (
 	// ErrInvalidPublicKey indicates the public key format is invalid
 	ErrInvalidPublicKey = errors.New("lamport: invalid public key")
 
+	// ErrInvalidPrivateKey indicates the private key format is invalid
+	ErrInvalidPrivateKey = errors.New("lamport: invalid private key")
+
 	// ErrInvalidSignature indicates the signature format is invalid
 	ErrInvalidSignature = errors.New("lamport: invalid signature")
 
@@ -63,6 +67,30 @@ var (
 
 	// ErrKeyChainExhausted indicates no more keys available in chain
 	ErrKeyChainExhausted = errors.New("lamport: key chain exhausted")
+
+	// ErrKeyIndexOutOfRange indicates a key chain index outside [0, len(Keys))
+	ErrKeyIndexOutOfRange = errors.New("lamport: key index out of range")
+
+	// ErrInvalidPKHList indicates a serialized PKH list is not a multiple of 32 bytes
+	ErrInvalidPKHList = errors.New("lamport: invalid PKH list length")
+
+	// ErrUnsupportedMessageVersion indicates an unknown threshold message format version
+	ErrUnsupportedMessageVersion = errors.New("lamport: unsupported threshold message version")
+
+	// ErrWeakDomain indicates a zero module address or chain ID, which disables domain separation
+	ErrWeakDomain = errors.New("lamport: zero module address or chain ID disables domain separation")
+
+	// ErrKeyPairMismatch indicates a key pair whose public key was not derived from its private key
+	ErrKeyPairMismatch = errors.New("lamport: public key does not match private key")
+
+	// ErrPKHMismatch indicates a public key that does not hash to the expected PKH
+	ErrPKHMismatch = errors.New("lamport: public key does not match PKH")
+
+	// ErrBitIndexOutOfRange indicates a message bit index outside [0, KeyBits)
+	ErrBitIndexOutOfRange = errors.New("lamport: bit index out of range")
+
+	// ErrZeroMessage indicates an all-zero message, usually an uninitialized buffer
+	ErrZeroMessage = errors.New("lamport: refusing to sign the all-zero message")
 )
 
 // PrivateKey represents a Lamport private key.
@@ -98,6 +126,8 @@ type KeyPair struct {
 
 // KeyChain manages a chain of one-time Lamport keys for continuous operation.
 // As each key is used, the next key in the chain becomes active.
+// Its methods and SignWithKeyChain are safe for concurrent use; direct access
+// to the exported fields is not synchronized.
 type KeyChain struct {
 	// Keys is the list of available key pairs
 	Keys []*KeyPair
@@ -107,11 +137,36 @@ type KeyChain struct {
 
 	// UsedCount tracks how many keys have been used
 	UsedCount int
+
+	// Signers optionally holds an external Signer for each key (e.g. HSM-backed).
+	// When set, Keys[i].Private may be nil and signing goes through Signers[i].
+	Signers []Signer
+
+	// pkhIndex lazily caches PKH -> index for FindByPKH, built over pkhCount keys
+	pkhIndex map[[32]byte]int
+	pkhCount int
+
+	// extendThreshold and extendBatch configure SetAutoExtend (batch 0 = off)
+	extendThreshold int
+	extendBatch     int
+	onExtend        func(pkhs [][32]byte)
+
+	// lowThreshold, onLow, and lowFired configure OnLowKeys
+	lowThreshold int
+	onLow        func(remaining int)
+	lowFired     bool
+
+	// events queues OnExtend/OnLowKeys callbacks until the lock is released;
+	// notifying is set while a goroutine is delivering them
+	events    []func()
+	notifying bool
+
+	mu sync.Mutex
 }
 
 // Keccak256 computes the Keccak-256 hash of data.
 func Keccak256(data []byte) [HashSize]byte {
-	h := sha3.NewLegacyKeccak256()
+	h := newHasher()
 	h.Write(data)
 	var result [HashSize]byte
 	h.Sum(result[:0])
@@ -120,7 +175,7 @@ func Keccak256(data []byte) [HashSize]byte {
 
 // Keccak256Multi computes keccak256 of multiple byte slices.
 func Keccak256Multi(parts ...[]byte) [HashSize]byte {
-	h := sha3.NewLegacyKeccak256()
+	h := newHasher()
 	for _, p := range parts {
 		h.Write(p)
 	}
@@ -129,6 +184,30 @@ func Keccak256Multi(parts ...[]byte) [HashSize]byte {
 	return result
 }
 
+// Keccak256Batch computes keccak256 of each input, reusing a single hasher.
+func Keccak256Batch(inputs [][]byte) [][HashSize]byte {
+	out := make([][HashSize]byte, len(inputs))
+	h := newHasher()
+	for i, in := range inputs {
+		h.Reset()
+		h.Write(in)
+		h.Sum(out[i][:0])
+	}
+	return out
+}
+
+// Keccak256Batch256 is Keccak256Batch for 32-byte inputs such as preimages.
+func Keccak256Batch256(inputs [][PreimageSize]byte) [][HashSize]byte {
+	out := make([][HashSize]byte, len(inputs))
+	h := newHasher()
+	for i := range inputs {
+		h.Reset()
+		h.Write(inputs[i][:])
+		h.Sum(out[i][:0])
+	}
+	return out
+}
+
 // Bytes serializes the public key to bytes.
 func (pk *PublicKey) Bytes() []byte {
 	out := make([]byte, PublicKeySize)
@@ -141,8 +220,18 @@ func (pk *PublicKey) Bytes() []byte {
 
 // Hash returns the keccak256 hash of the public key (PKH).
 // This is used on-chain to store a compact representation.
+//
+// The hashes are streamed into the hasher in Bytes() order, so the result
+// equals keccak256(pk.Bytes()) without allocating the 16 KB encoding.
 func (pk *PublicKey) Hash() [PublicKeyHashSize]byte {
-	return Keccak256(pk.Bytes())
+	h := newHasher()
+	for i := 0; i < KeyBits; i++ {
+		h.Write(pk.Hashes[i][0][:])
+		h.Write(pk.Hashes[i][1][:])
+	}
+	var result [PublicKeyHashSize]byte
+	h.Sum(result[:0])
+	return result
 }
 
 // FromBytes deserializes a public key from bytes.
@@ -157,6 +246,124 @@ func (pk *PublicKey) FromBytes(data []byte) error {
 	return nil
 }
 
+// FromBytesChecked is FromBytes for a key the caller expects to hash to
+// pkh, e.g. one registered on-chain. It returns ErrPKHMismatch if the
+// decoded key does not hash to pkh, which catches a private key (the same
+// length as a public key) or any other wrong buffer passed by mistake, and
+// ErrInvalidPublicKey if the key is degenerate (see IsDegenerate).
+//
+// Without a known PKH no structural check can tell a public key from a
+// private key: both are 512 random-looking 32-byte values. On error pk is
+// left unchanged.
+func (pk *PublicKey) FromBytesChecked(data []byte, pkh [32]byte) error {
+	var tmp PublicKey
+	if err := tmp.FromBytes(data); err != nil {
+		return err
+	}
+	if i := tmp.degeneratePosition(); i >= 0 {
+		return fmt.Errorf("%w: identical hashes at position %d", ErrInvalidPublicKey, i)
+	}
+	if !ConstantTimeEqualHash(tmp.Hash(), pkh) {
+		return ErrPKHMismatch
+	}
+	*pk = tmp
+	return nil
+}
+
+// IsDegenerate reports whether any position has Hashes[i][0] ==
+// Hashes[i][1]. At such a position both message bit values are satisfied by
+// the same preimage, so revealing it for one bit also signs the other. An
+// honestly generated key is never degenerate; one that is was malformed or
+// crafted and should be rejected before being registered on-chain.
+func (pk *PublicKey) IsDegenerate() bool {
+	return pk.degeneratePosition() >= 0
+}
+
+// degeneratePosition returns the first position with identical hashes, or -1.
+func (pk *PublicKey) degeneratePosition() int {
+	for i := 0; i < KeyBits; i++ {
+		if pk.Hashes[i][0] == pk.Hashes[i][1] {
+			return i
+		}
+	}
+	return -1
+}
+
+// Bytes serializes the private key preimages to bytes.
+// Layout matches PublicKey.Bytes: preimage[i][0] || preimage[i][1] for each i.
+// The Used flag is not included.
+func (priv *PrivateKey) Bytes() []byte {
+	out := make([]byte, PrivateKeySize)
+	for i := 0; i < KeyBits; i++ {
+		copy(out[i*64:i*64+32], priv.Preimages[i][0][:])
+		copy(out[i*64+32:i*64+64], priv.Preimages[i][1][:])
+	}
+	return out
+}
+
+// FromBytes deserializes private key preimages from bytes.
+func (priv *PrivateKey) FromBytes(data []byte) error {
+	if len(data) != PrivateKeySize {
+		return ErrInvalidPrivateKey
+	}
+	for i := 0; i < KeyBits; i++ {
+		copy(priv.Preimages[i][0][:], data[i*64:i*64+32])
+		copy(priv.Preimages[i][1][:], data[i*64+32:i*64+64])
+	}
+	return nil
+}
+
+// DerivePublic recomputes the public key from the private preimages, e.g.
+// after loading only a private key with FromBytes.
+func (priv *PrivateKey) DerivePublic() *PublicKey {
+	LockHashFunc()
+	preimages := make([][PreimageSize]byte, 0, 2*KeyBits)
+	for i := 0; i < KeyBits; i++ {
+		preimages = append(preimages, priv.Preimages[i][0], priv.Preimages[i][1])
+	}
+	hashes := Keccak256Batch256(preimages)
+
+	pub := &PublicKey{}
+	for i := 0; i < KeyBits; i++ {
+		pub.Hashes[i][0] = hashes[2*i]
+		pub.Hashes[i][1] = hashes[2*i+1]
+	}
+	return pub
+}
+
+// IsWellFormed reports whether the signature is not entirely zero.
+// An all-zero signature indicates an uninitialized or truncated buffer.
+// Individual zero preimages are allowed; they are valid random values.
+// A nil signature is not well-formed.
+func (sig *Signature) IsWellFormed() bool {
+	return sig != nil && wellFormed(&sig.Preimages)
+}
+
+// wellFormed reports whether any preimage is nonzero. Every verify path
+// runs it before hashing, whatever form the signature takes.
+func wellFormed(preimages *[KeyBits][PreimageSize]byte) bool {
+	for i := 0; i < KeyBits; i++ {
+		if preimages[i] != [PreimageSize]byte{} {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff returns, in ascending order, the bit positions at which sig and other
+// reveal different preimages. Comparing an aggregated signature against a
+// locally computed one pinpoints the positions aggregation got wrong, which
+// usually trace back to a bad share. A nil other differs everywhere.
+func (sig *Signature) Diff(other *Signature) []int {
+	var positions []int
+	for i := 0; i < KeyBits; i++ {
+		if other == nil || sig.Preimages[i] != other.Preimages[i] {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
 // Bytes serializes the signature to bytes.
 func (sig *Signature) Bytes() []byte {
 	out := make([]byte, SignatureSize)
@@ -177,6 +384,77 @@ func (sig *Signature) FromBytes(data []byte) error {
 	return nil
 }
 
+// FromBytesChecked is FromBytes that also rejects an all-zero signature
+// (see IsWellFormed), a sign of an uninitialized buffer. On error sig is
+// left unchanged.
+func (sig *Signature) FromBytesChecked(data []byte) error {
+	var tmp Signature
+	if err := tmp.FromBytes(data); err != nil {
+		return err
+	}
+	if !tmp.IsWellFormed() {
+		return fmt.Errorf("%w: all-zero signature", ErrInvalidSignature)
+	}
+	*sig = tmp
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder using the Bytes layout.
+func (pk *PublicKey) GobEncode() ([]byte, error) {
+	return pk.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder using the FromBytes layout.
+func (pk *PublicKey) GobDecode(data []byte) error {
+	return pk.FromBytes(data)
+}
+
+// GobEncode implements gob.GobEncoder using the Bytes layout.
+func (sig *Signature) GobEncode() ([]byte, error) {
+	return sig.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder using the FromBytes layout.
+func (sig *Signature) GobDecode(data []byte) error {
+	return sig.FromBytes(data)
+}
+
+// Base64 returns the standard (padded) base64 encoding of Bytes.
+func (pk *PublicKey) Base64() string {
+	return base64.StdEncoding.EncodeToString(pk.Bytes())
+}
+
+// PublicKeyFromBase64 decodes a public key produced by PublicKey.Base64.
+func PublicKeyFromBase64(s string) (*PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	pk := &PublicKey{}
+	if err := pk.FromBytes(data); err != nil {
+		return nil, err
+	}
+	return pk, nil
+}
+
+// Base64 returns the standard (padded) base64 encoding of Bytes.
+func (sig *Signature) Base64() string {
+	return base64.StdEncoding.EncodeToString(sig.Bytes())
+}
+
+// SignatureFromBase64 decodes a signature produced by Signature.Base64.
+func SignatureFromBase64(s string) (*Signature, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	sig := &Signature{}
+	if err := sig.FromBytes(data); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
 // ToCalldata converts the signature to Solidity-compatible calldata format.
 // Returns bytes[256] for use with verify_u256.
 func (sig *Signature) ToCalldata() [][]byte {
@@ -194,32 +472,124 @@ func (pk *PublicKey) ToCalldata() [KeyBits][2][HashSize]byte {
 	return pk.Hashes
 }
 
+// SignatureFromCalldata is the inverse of Signature.ToCalldata.
+// It requires exactly 256 elements of 32 bytes each.
+func SignatureFromCalldata(data [][]byte) (*Signature, error) {
+	if len(data) != KeyBits {
+		return nil, fmt.Errorf("%w: expected %d calldata elements, got %d", ErrInvalidSignature, KeyBits, len(data))
+	}
+	sig := &Signature{}
+	for i, preimage := range data {
+		if len(preimage) != PreimageSize {
+			return nil, fmt.Errorf("%w: calldata element %d is %d bytes, expected %d", ErrInvalidSignature, i, len(preimage), PreimageSize)
+		}
+		copy(sig.Preimages[i][:], preimage)
+	}
+	return sig, nil
+}
+
+// PublicKeyFromCalldata is the inverse of PublicKey.ToCalldata.
+func PublicKeyFromCalldata(data [KeyBits][2][HashSize]byte) *PublicKey {
+	return &PublicKey{Hashes: data}
+}
+
+// ChainIDFromUint64 zero-extends a uint64 chain ID to a big-endian uint256.
+func ChainIDFromUint64(chainID uint64) [32]byte {
+	var out [32]byte
+	binary.BigEndian.PutUint64(out[24:32], chainID)
+	return out
+}
+
 // ComputeDomainSeparator computes the domain separator for threshold signing.
+// It is keccak256(moduleAddress || uint256(chainID)), not an EIP-712 domain
+// separator; use ComputeEIP712DomainSeparator for EIP-712 typed data.
 func ComputeDomainSeparator(moduleAddress [20]byte, chainID uint64) [32]byte {
+	return ComputeDomainSeparatorU256(moduleAddress, ChainIDFromUint64(chainID))
+}
+
+// ComputeDomainSeparatorU256 is ComputeDomainSeparator for a full uint256
+// chain ID (big-endian), for chains whose block.chainid exceeds 64 bits.
+func ComputeDomainSeparatorU256(moduleAddress [20]byte, chainID [32]byte) [32]byte {
 	var buf [52]byte // 20 + 32 for chainID as uint256
 	copy(buf[:20], moduleAddress[:])
-	binary.BigEndian.PutUint64(buf[44:52], chainID) // Right-aligned like Solidity uint256
+	copy(buf[20:52], chainID[:])
 	return Keccak256(buf[:])
 }
 
 // ComputeThresholdMessage computes the final message for threshold signing.
 // This matches the Solidity: keccak256(abi.encodePacked(safeTxHash, nextPKH, address(this), block.chainid))
 func ComputeThresholdMessage(safeTxHash, nextPKH [32]byte, moduleAddress [20]byte, chainID uint64) [32]byte {
+	return NewMessageBuilder().
+		Add32(safeTxHash).
+		Add32(nextPKH).
+		Add20(moduleAddress).
+		AddUint256(chainID).
+		Hash()
+}
+
+// ComputeThresholdMessageU256 is ComputeThresholdMessage for a full uint256
+// chain ID (big-endian). The byte layout is identical; chain IDs that fit in
+// 64 bits produce the same message as ComputeThresholdMessage.
+func ComputeThresholdMessageU256(safeTxHash, nextPKH [32]byte, moduleAddress [20]byte, chainID [32]byte) [32]byte {
 	var buf [116]byte // 32 + 32 + 20 + 32 (chainid as uint256)
 	copy(buf[0:32], safeTxHash[:])
 	copy(buf[32:64], nextPKH[:])
 	copy(buf[64:84], moduleAddress[:])
-	binary.BigEndian.PutUint64(buf[108:116], chainID)
+	copy(buf[84:116], chainID[:])
+	return Keccak256(buf[:])
+}
+
+// Threshold message format versions.
+const (
+	// ThresholdMessageV1 is the untagged format of ComputeThresholdMessage (the default)
+	ThresholdMessageV1 byte = 1
+
+	// ThresholdMessageV2 prefixes the packed fields with a one-byte version tag
+	ThresholdMessageV2 byte = 2
+)
+
+// ComputeThresholdMessageV2 computes the version-tagged threshold message:
+//
+//	keccak256(abi.encodePacked(uint8(2), safeTxHash, nextPKH, address(this), block.chainid))
+//
+// The hashed buffer is 117 bytes: byte 0 is the version tag (0x02), followed
+// by the 116-byte v1 layout at offsets 1..116. A v1 buffer never has this
+// length, so v1 and v2 messages cannot collide.
+func ComputeThresholdMessageV2(safeTxHash, nextPKH [32]byte, moduleAddress [20]byte, chainID uint64) [32]byte {
+	var buf [117]byte // 1 + 32 + 32 + 20 + 32 (chainid as uint256)
+	buf[0] = ThresholdMessageV2
+	copy(buf[1:33], safeTxHash[:])
+	copy(buf[33:65], nextPKH[:])
+	copy(buf[65:85], moduleAddress[:])
+	chain := ChainIDFromUint64(chainID)
+	copy(buf[85:117], chain[:])
 	return Keccak256(buf[:])
 }
 
+// ComputeThresholdMessageVersion computes the threshold message in the given
+// format version. Returns ErrUnsupportedMessageVersion for unknown versions,
+// so verifiers can reject messages in a format they do not implement.
+func ComputeThresholdMessageVersion(version byte, safeTxHash, nextPKH [32]byte, moduleAddress [20]byte, chainID uint64) ([32]byte, error) {
+	switch version {
+	case ThresholdMessageV1:
+		return ComputeThresholdMessage(safeTxHash, nextPKH, moduleAddress, chainID), nil
+	case ThresholdMessageV2:
+		return ComputeThresholdMessageV2(safeTxHash, nextPKH, moduleAddress, chainID), nil
+	default:
+		return [32]byte{}, ErrUnsupportedMessageVersion
+	}
+}
+
 // GenerateKeyPair generates a new Lamport key pair using crypto/rand.
 func GenerateKeyPair() (*KeyPair, error) {
 	return GenerateKeyPairFromReader(rand.Reader)
 }
 
 // GenerateKeyPairFromReader generates a new Lamport key pair from the given random source.
+// Long-running key generators can pass a ReseedingReader to periodically
+// refresh their entropy.
 func GenerateKeyPairFromReader(random io.Reader) (*KeyPair, error) {
+	LockHashFunc()
 	priv := &PrivateKey{}
 	pub := &PublicKey{}
 
@@ -232,10 +602,57 @@ func GenerateKeyPairFromReader(random io.Reader) (*KeyPair, error) {
 			pub.Hashes[i][bit] = Keccak256(priv.Preimages[i][bit][:])
 		}
 	}
+	count(&counters.keysGenerated)
 
 	return &KeyPair{Private: priv, Public: pub}, nil
 }
 
+// GenerateKeyPairFromSeed deterministically derives a Lamport key pair from a 32-byte seed.
+//
+// Each preimage is keccak256(seed || uint16(i) || bit), so the same seed always
+// yields the same key pair. The seed must be kept as secret as the private key.
+func GenerateKeyPairFromSeed(seed [32]byte) (*KeyPair, error) {
+	LockHashFunc()
+	priv := &PrivateKey{}
+	pub := &PublicKey{}
+
+	for i := 0; i < KeyBits; i++ {
+		for bit := 0; bit < 2; bit++ {
+			priv.Preimages[i][bit] = seedPreimage(seed, i, bit)
+			pub.Hashes[i][bit] = Keccak256(priv.Preimages[i][bit][:])
+		}
+	}
+	count(&counters.keysGenerated)
+
+	return &KeyPair{Private: priv, Public: pub}, nil
+}
+
+// seedPreimage derives the preimage for bit position i and side bit from a seed.
+func seedPreimage(seed [32]byte, i, bit int) [PreimageSize]byte {
+	var buf [35]byte
+	copy(buf[:32], seed[:])
+	binary.BigEndian.PutUint16(buf[32:34], uint16(i))
+	buf[34] = byte(bit)
+	return Keccak256(buf[:])
+}
+
+// Verify checks that the public key is the one derived from the private
+// key, catching tampered or mismatched loads. It returns ErrKeyPairMismatch
+// on a mismatch, or ErrInvalidPrivateKey / ErrInvalidPublicKey if either
+// half is missing.
+func (kp *KeyPair) Verify() error {
+	if kp.Private == nil {
+		return ErrInvalidPrivateKey
+	}
+	if kp.Public == nil {
+		return ErrInvalidPublicKey
+	}
+	if *kp.Private.DerivePublic() != *kp.Public {
+		return ErrKeyPairMismatch
+	}
+	return nil
+}
+
 // NewKeyChain creates a new key chain with the specified number of keys.
 func NewKeyChain(numKeys int) (*KeyChain, error) {
 	if numKeys <= 0 {
@@ -259,16 +676,61 @@ func NewKeyChain(numKeys int) (*KeyChain, error) {
 	return chain, nil
 }
 
+// NewKeyChainFromSigners creates a key chain whose keys are held by external
+// signers. Only the public keys are kept in Keys; private keys stay with the signers.
+func NewKeyChainFromSigners(signers []Signer) (*KeyChain, error) {
+	if len(signers) == 0 {
+		return nil, errors.New("lamport: numKeys must be positive")
+	}
+
+	chain := &KeyChain{
+		Keys:    make([]*KeyPair, len(signers)),
+		Signers: signers,
+	}
+	for i, s := range signers {
+		chain.Keys[i] = &KeyPair{Public: s.PublicKey()}
+	}
+
+	return chain, nil
+}
+
 // Current returns the current (unused) key pair.
 func (kc *KeyChain) Current() (*KeyPair, error) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	return kc.current()
+}
+
+func (kc *KeyChain) current() (*KeyPair, error) {
 	if kc.CurrentIndex >= len(kc.Keys) {
 		return nil, ErrKeyChainExhausted
 	}
 	return kc.Keys[kc.CurrentIndex], nil
 }
 
+// Signer returns a Signer for the current (unused) key.
+func (kc *KeyChain) Signer() (Signer, error) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	return kc.signer()
+}
+
+func (kc *KeyChain) signer() (Signer, error) {
+	kp, err := kc.current()
+	if err != nil {
+		return nil, err
+	}
+	if kc.CurrentIndex < len(kc.Signers) && kc.Signers[kc.CurrentIndex] != nil {
+		return kc.Signers[kc.CurrentIndex], nil
+	}
+	return NewLocalSigner(kp.Private, kp.Public), nil
+}
+
 // NextPKH returns the hash of the next public key (for key rotation).
 func (kc *KeyChain) NextPKH() ([32]byte, error) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
 	nextIdx := kc.CurrentIndex + 1
 	if nextIdx >= len(kc.Keys) {
 		return [32]byte{}, errors.New("lamport: no next key available")
@@ -277,25 +739,150 @@ func (kc *KeyChain) NextPKH() ([32]byte, error) {
 }
 
 // Advance marks the current key as used and advances to the next.
+// If auto-extension is enabled (see SetAutoExtend), the chain is extended
+// when the remaining keys drop to the threshold; an extension error is
+// returned after the key has already been marked used. A low-keys callback
+// (see OnLowKeys) fires after any extension.
 func (kc *KeyChain) Advance() error {
+	kc.mu.Lock()
+	defer kc.unlockAndNotify()
+
 	if kc.CurrentIndex >= len(kc.Keys) {
 		return ErrKeyChainExhausted
 	}
-	kc.Keys[kc.CurrentIndex].Private.Used = true
+	if priv := kc.Keys[kc.CurrentIndex].Private; priv != nil {
+		priv.Used = true
+	}
+	return kc.step()
+}
+
+// step moves past the current key, then extends the chain and checks the
+// low-keys threshold. kc.mu must be held and the chain must not be exhausted.
+func (kc *KeyChain) step() error {
 	kc.CurrentIndex++
 	kc.UsedCount++
+	if err := kc.maybeExtend(); err != nil {
+		return err
+	}
+	kc.checkLowKeys()
 	return nil
 }
 
 // Remaining returns the number of unused keys remaining.
 func (kc *KeyChain) Remaining() int {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	return kc.remaining()
+}
+
+func (kc *KeyChain) remaining() int {
 	return len(kc.Keys) - kc.CurrentIndex
 }
 
+// PKHAt returns the public key hash of the i-th key in the chain.
+func (kc *KeyChain) PKHAt(i int) ([32]byte, error) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	if i < 0 || i >= len(kc.Keys) {
+		return [32]byte{}, ErrKeyIndexOutOfRange
+	}
+	return kc.Keys[i].Public.Hash(), nil
+}
+
+// FindByPKH returns the index of the key whose public key hash is pkh.
+// The PKH index is built on first use and rebuilt if keys are appended.
+func (kc *KeyChain) FindByPKH(pkh [32]byte) (int, bool) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	return kc.findByPKH(pkh)
+}
+
+// IsUsedPKH reports whether the key with public key hash pkh has signed,
+// i.e. its Private.Used flag (for a key without a private half, whether
+// the chain has advanced past it). Returns ErrUnknownPKH if pkh is not in
+// the chain.
+func (kc *KeyChain) IsUsedPKH(pkh [32]byte) (bool, error) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	i, ok := kc.findByPKH(pkh)
+	if !ok {
+		return false, ErrUnknownPKH
+	}
+	if priv := kc.Keys[i].Private; priv != nil {
+		return priv.Used, nil
+	}
+	return i < kc.CurrentIndex, nil
+}
+
+func (kc *KeyChain) findByPKH(pkh [32]byte) (int, bool) {
+	if kc.pkhIndex == nil || kc.pkhCount != len(kc.Keys) {
+		kc.pkhIndex = make(map[[32]byte]int, len(kc.Keys))
+		for i, kp := range kc.Keys {
+			kc.pkhIndex[kp.Public.Hash()] = i
+		}
+		kc.pkhCount = len(kc.Keys)
+	}
+	i, ok := kc.pkhIndex[pkh]
+	return i, ok
+}
+
+// PKHList returns the public key hash of every key in the chain, in order.
+// Publishing it lets verifiers follow rotations without the 16 KB public keys.
+func (kc *KeyChain) PKHList() [][32]byte {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	pkhs := make([][32]byte, len(kc.Keys))
+	for i, kp := range kc.Keys {
+		pkhs[i] = kp.Public.Hash()
+	}
+	return pkhs
+}
+
+// PKHListBytes serializes PKHList as the concatenation of 32-byte hashes.
+func (kc *KeyChain) PKHListBytes() []byte {
+	out := make([]byte, 0, len(kc.Keys)*32)
+	for _, pkh := range kc.PKHList() {
+		out = append(out, pkh[:]...)
+	}
+	return out
+}
+
+// PKHListFromBytes parses the output of PKHListBytes.
+func PKHListFromBytes(data []byte) ([][32]byte, error) {
+	if len(data)%32 != 0 {
+		return nil, ErrInvalidPKHList
+	}
+	pkhs := make([][32]byte, len(data)/32)
+	for i := range pkhs {
+		copy(pkhs[i][:], data[i*32:(i+1)*32])
+	}
+	return pkhs, nil
+}
+
 // GetBit returns the bit at position i (0-255) of a 32-byte message.
-// Bit 0 is the most significant bit of the first byte.
+// Bit 0 is the most significant bit of the first byte. i must be in
+// [0, KeyBits): larger or below -7 it panics, and -7 to -1 return a
+// meaningless bit. Use GetBitChecked for indices that are not known good.
 func GetBit(message [32]byte, i int) int {
 	byteIdx := i / 8
 	bitIdx := 7 - (i % 8)
 	return int((message[byteIdx] >> bitIdx) & 1)
 }
+
+// GetBitChecked is GetBit that returns ErrBitIndexOutOfRange instead of
+// panicking when i is outside [0, KeyBits).
+func GetBitChecked(message [32]byte, i int) (int, error) {
+	if i < 0 || i >= KeyBits {
+		return 0, fmt.Errorf("%w: %d", ErrBitIndexOutOfRange, i)
+	}
+	return GetBit(message, i), nil
+}
+
+// Bits returns every bit of message in signing order: entry i is
+// GetBit(message, i), i.e. MSB first within each byte.
+func Bits(message [32]byte) [KeyBits]byte {
+	return [KeyBits]byte(NewBitVector(message))
+}
@@ -1,5 +1,10 @@
 package primitives
 
+import (
+	"crypto/subtle"
+	"math/bits"
+)
+
 // Verify checks a Lamport signature against a public key and message.
 //
 // For each bit i of the message:
@@ -7,12 +12,21 @@ package primitives
 //   - If bit i is 1, check keccak256(sig[i]) == pub[i][1]
 //
 // Returns true if all 256 preimages hash to the correct public key values.
+// An all-zero signature (see IsWellFormed) is rejected before hashing.
 // NOTE: This function returns early on mismatch. For side-channel resistance,
 // use VerifyConstantTime instead.
 func Verify(pub *PublicKey, message [32]byte, sig *Signature) bool {
+	return countVerify(verify(pub, message, sig))
+}
+
+func verify(pub *PublicKey, message [32]byte, sig *Signature) bool {
+	if pub == nil || !sig.IsWellFormed() {
+		return false
+	}
+
+	bits := NewBitVector(message)
 	for i := 0; i < KeyBits; i++ {
-		bit := GetBit(message, i)
-		expectedHash := pub.Hashes[i][bit]
+		expectedHash := pub.Hashes[i][bits[i]]
 		actualHash := Keccak256(sig.Preimages[i][:])
 
 		if actualHash != expectedHash {
@@ -22,18 +36,69 @@ func Verify(pub *PublicKey, message [32]byte, sig *Signature) bool {
 	return true
 }
 
+// VerifyRange checks only bit positions [start, end) of a signature, so
+// several workers can each verify a slice and AND the results. With
+// start=0 and end=KeyBits it returns the same result as Verify. An invalid
+// range (start < 0, end > KeyBits, or start >= end) or a nil pub returns
+// false.
+//
+// The all-zero check of Verify applies to the whole signature, so each
+// worker rejects an unset signature regardless of its slice.
+func VerifyRange(pub *PublicKey, message [32]byte, sig *Signature, start, end int) bool {
+	if pub == nil || start < 0 || end > KeyBits || start >= end {
+		return false
+	}
+	if !sig.IsWellFormed() {
+		return false
+	}
+
+	bits := NewBitVector(message)
+	for i := start; i < end; i++ {
+		if Keccak256(sig.Preimages[i][:]) != pub.Hashes[i][bits[i]] {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyBit reports whether preimage is the secret for bit position i and
+// side bit, i.e. keccak256(preimage) == Hashes[i][bit]. This is the atomic
+// check inside Verify, for callers verifying partial or streamed reveals.
+// Out-of-range i or bit returns false.
+func (pk *PublicKey) VerifyBit(i, bit int, preimage [PreimageSize]byte) bool {
+	if i < 0 || i >= KeyBits || bit < 0 || bit > 1 {
+		return false
+	}
+	return Keccak256(preimage[:]) == pk.Hashes[i][bit]
+}
+
+// VerifyWithConvention is Verify with the message bits read under conv.
+// Signer and verifier must agree on the convention: a signature made under
+// one ordering fails under the other unless the message is a 256-bit
+// palindrome.
+func VerifyWithConvention(pub *PublicKey, message [32]byte, sig *Signature, conv BitOrder) bool {
+	return Verify(pub, conv.canonical(message), sig)
+}
+
 // VerifyConstantTime checks a Lamport signature in constant time.
 // Unlike Verify, this function always checks all 256 preimages regardless
 // of mismatches, preventing timing side-channel attacks.
 //
 // Use this when the verification result could be observed by an attacker
-// (e.g., through timing analysis).
+// (e.g., through timing analysis). An all-zero signature is rejected, as by
+// Verify.
 func VerifyConstantTime(pub *PublicKey, message [32]byte, sig *Signature) bool {
+	// Rejecting nil or all-zero signatures depends only on public input, so
+	// it leaks nothing about the key material
+	if pub == nil || !sig.IsWellFormed() {
+		return countVerify(false)
+	}
+
 	var mismatch byte // Accumulate mismatches without branching
+	bits := NewBitVector(message)
 
 	for i := 0; i < KeyBits; i++ {
-		bit := GetBit(message, i)
-		expectedHash := pub.Hashes[i][bit]
+		expectedHash := pub.Hashes[i][bits[i]]
 		actualHash := Keccak256(sig.Preimages[i][:])
 
 		// XOR each byte and OR into mismatch accumulator
@@ -43,7 +108,7 @@ func VerifyConstantTime(pub *PublicKey, message [32]byte, sig *Signature) bool {
 	}
 
 	// mismatch == 0 iff all hashes matched
-	return mismatch == 0
+	return countVerify(mismatch == 0)
 }
 
 // VerifyBytes verifies a signature against message bytes.
@@ -64,27 +129,99 @@ func VerifyBytes(pub *PublicKey, message []byte, sig *Signature) bool {
 //   - sig: Array of 256 preimages
 //   - pub: 256x2 array of public key hashes
 //
-// Returns true if signature is valid.
+// Returns true if signature is valid. An all-zero sig is rejected, as by
+// Verify.
 func VerifyU256(bits [32]byte, sig [KeyBits][PreimageSize]byte, pub [KeyBits][2][HashSize]byte) bool {
+	if !wellFormed(&sig) {
+		return false
+	}
+	bv := NewBitVector(bits)
 	for i := 0; i < KeyBits; i++ {
 		// Select pub[i][0] if bit is 0, pub[i][1] if bit is 1
 		// Bit ordering: bit 0 is MSB (position 255-i in Solidity's (1 << (255 - i)))
-		bit := GetBit(bits, i)
+		actualHash := Keccak256(sig[i][:])
+		if actualHash != pub[i][bv[i]] {
+			return false
+		}
+	}
+	return true
+}
+
+// BitOrder selects how position i of a signature maps to a bit of the
+// 256-bit message when it is read as a big-endian uint256.
+type BitOrder int
+
+const (
+	// MSBFirst maps position i to bit (255 - i): Solidity's (1 << (255 - i)).
+	// This is the ordering used by GetBit, Sign, and Verify.
+	MSBFirst BitOrder = iota
+
+	// LSBFirst maps position i to bit i: Solidity's (1 << i).
+	LSBFirst
+)
+
+// Bit returns the message bit for signature position i under this ordering.
+func (o BitOrder) Bit(message [32]byte, i int) int {
+	if o == LSBFirst {
+		return GetBitLSB(message, i)
+	}
+	return GetBit(message, i)
+}
+
+// GetBitLSB returns bit i (0-255) of message read as a big-endian uint256,
+// counting from the least significant bit: the LSBFirst ordering.
+func GetBitLSB(message [32]byte, i int) int {
+	return int((message[31-i/8] >> (i % 8)) & 1)
+}
+
+// canonical returns the message whose MSB-first bits are message's bits
+// under o, so the MSB-first Sign and Verify can serve either ordering.
+// For LSBFirst that is the full 256-bit reversal.
+func (o BitOrder) canonical(message [32]byte) [32]byte {
+	if o != LSBFirst {
+		return message
+	}
+	var out [32]byte
+	for k := range out {
+		out[k] = bits.Reverse8(message[31-k])
+	}
+	return out
+}
 
+// VerifyU256WithOrder is VerifyU256 with an explicit bit ordering, for
+// contracts that index bits LSB-first. VerifyU256 is equivalent to
+// VerifyU256WithOrder(bits, sig, pub, MSBFirst).
+func VerifyU256WithOrder(bits [32]byte, sig [KeyBits][PreimageSize]byte, pub [KeyBits][2][HashSize]byte, order BitOrder) bool {
+	if !wellFormed(&sig) {
+		return false
+	}
+	for i := 0; i < KeyBits; i++ {
 		actualHash := Keccak256(sig[i][:])
-		if actualHash != pub[i][bit] {
+		if actualHash != pub[i][order.Bit(bits, i)] {
 			return false
 		}
 	}
 	return true
 }
 
+// ConstantTimeEqualHash reports whether a and b are equal, examining every
+// byte regardless of where they first differ. Use it wherever a hash
+// comparison gates further verification, so the gate leaks no timing.
+func ConstantTimeEqualHash(a, b [32]byte) bool {
+	return subtle.ConstantTimeCompare(a[:], b[:]) == 1
+}
+
 // VerifyWithPKH verifies a signature and checks that the public key hashes to expectedPKH.
 // This is useful for on-chain verification where only the PKH is stored.
+// Degenerate public keys are accepted; use VerifyWithPKHStrict to reject them.
 func VerifyWithPKH(pub *PublicKey, message [32]byte, sig *Signature, expectedPKH [32]byte) bool {
+	if pub == nil {
+		return false
+	}
+
 	// First check PKH matches
 	actualPKH := pub.Hash()
-	if actualPKH != expectedPKH {
+	if !ConstantTimeEqualHash(actualPKH, expectedPKH) {
 		return false
 	}
 
@@ -92,7 +229,20 @@ func VerifyWithPKH(pub *PublicKey, message [32]byte, sig *Signature, expectedPKH
 	return Verify(pub, message, sig)
 }
 
+// VerifyWithPKHStrict is VerifyWithPKH that also rejects degenerate public
+// keys (see PublicKey.IsDegenerate): it returns ErrInvalidPublicKey, without
+// verifying, if pub is nil or degenerate. Otherwise it returns the
+// VerifyWithPKH result with a nil error.
+func VerifyWithPKHStrict(pub *PublicKey, message [32]byte, sig *Signature, expectedPKH [32]byte) (bool, error) {
+	if pub == nil || pub.IsDegenerate() {
+		return false, ErrInvalidPublicKey
+	}
+	return VerifyWithPKH(pub, message, sig, expectedPKH), nil
+}
+
 // VerifyThresholdMessage verifies a threshold Lamport signature with domain separation.
+// A zero moduleAddress or chainID is accepted for backward compatibility; use
+// VerifyThresholdMessageStrict to reject such weak domains.
 func VerifyThresholdMessage(
 	pub *PublicKey,
 	sig *Signature,
@@ -103,7 +253,7 @@ func VerifyThresholdMessage(
 	expectedPKH [32]byte,
 ) bool {
 	// Check PKH
-	if pub.Hash() != expectedPKH {
+	if pub == nil || !ConstantTimeEqualHash(pub.Hash(), expectedPKH) {
 		return false
 	}
 
@@ -114,6 +264,52 @@ func VerifyThresholdMessage(
 	return Verify(pub, message, sig)
 }
 
+// VerifyThresholdMessageStrict is VerifyThresholdMessage for verifiers that
+// must not accept cross-domain signatures: it returns ErrWeakDomain, without
+// verifying, if moduleAddress is all-zero or chainID is 0. Otherwise it
+// returns the VerifyThresholdMessage result with a nil error.
+func VerifyThresholdMessageStrict(
+	pub *PublicKey,
+	sig *Signature,
+	safeTxHash [32]byte,
+	nextPKH [32]byte,
+	moduleAddress [20]byte,
+	chainID uint64,
+	expectedPKH [32]byte,
+) (bool, error) {
+	if moduleAddress == ([20]byte{}) || chainID == 0 {
+		return false, ErrWeakDomain
+	}
+	return VerifyThresholdMessage(pub, sig, safeTxHash, nextPKH, moduleAddress, chainID, expectedPKH), nil
+}
+
+// BindNextKey proves that a rotation committed to nextPub: it recomputes the
+// threshold message currentPub signed for safeTxHash with nextPKH =
+// nextPub.Hash() and checks currentSig over it. On success it returns the
+// committed nextPKH and true. A signature committing to any other next key
+// (or a garbage PKH) does not verify, so ok is false and the PKH is zero.
+//
+// The domain (moduleAddress, chainID) is required because the signed
+// message is domain-separated; it cannot be recovered from the signature.
+func BindNextKey(
+	currentSig *Signature,
+	safeTxHash [32]byte,
+	currentPub *PublicKey,
+	nextPub *PublicKey,
+	moduleAddress [20]byte,
+	chainID uint64,
+) (committedNextPKH [32]byte, ok bool) {
+	if currentPub == nil || nextPub == nil {
+		return [32]byte{}, false
+	}
+	nextPKH := nextPub.Hash()
+	message := ComputeThresholdMessage(safeTxHash, nextPKH, moduleAddress, chainID)
+	if !Verify(currentPub, message, currentSig) {
+		return [32]byte{}, false
+	}
+	return nextPKH, true
+}
+
 // BatchVerify verifies multiple signatures in parallel.
 // Returns a slice of booleans indicating which signatures are valid.
 func BatchVerify(pubs []*PublicKey, messages [][32]byte, sigs []*Signature) []bool {
@@ -129,3 +325,123 @@ func BatchVerify(pubs []*PublicKey, messages [][32]byte, sigs []*Signature) []bo
 	}
 	return results
 }
+
+// VerifyAny returns the index of the first public key in pubs that sig
+// verifies against for message, or -1 and false if none does (nil entries
+// are skipped).
+//
+// The 256 preimages are hashed once up front, so each further candidate
+// costs only hash comparisons; that is far cheaper than spreading the
+// candidates across goroutines, so the checks run sequentially.
+func VerifyAny(pubs []*PublicKey, message [32]byte, sig *Signature) (index int, ok bool) {
+	if !sig.IsWellFormed() {
+		return -1, countVerify(false)
+	}
+
+	var hashes [KeyBits][HashSize]byte
+	for i := 0; i < KeyBits; i++ {
+		hashes[i] = Keccak256(sig.Preimages[i][:])
+	}
+	bits := NewBitVector(message)
+
+candidates:
+	for j, pub := range pubs {
+		if pub == nil {
+			continue
+		}
+		for i := 0; i < KeyBits; i++ {
+			if pub.Hashes[i][bits[i]] != hashes[i] {
+				continue candidates
+			}
+		}
+		return j, countVerify(true)
+	}
+	return -1, countVerify(false)
+}
+
+// BatchVerifyChain verifies signatures made with consecutive keys of a chain.
+// messages[j] and sigs[j] are checked against chain.Keys[startIndex+j].Public.
+// Entries whose key index falls outside the chain are reported invalid.
+func BatchVerifyChain(chain *KeyChain, startIndex int, messages [][32]byte, sigs []*Signature) []bool {
+	n := len(messages)
+	results := make([]bool, n)
+	if len(sigs) != n || startIndex < 0 {
+		return results // All false
+	}
+
+	for j := 0; j < n; j++ {
+		idx := startIndex + j
+		if idx >= len(chain.Keys) {
+			break
+		}
+		results[j] = Verify(chain.Keys[idx].Public, messages[j], sigs[j])
+	}
+	return results
+}
+
+// VerifyWithPKHList verifies a signature made with the index-th key of a
+// published PKH list: pub must hash to pkhs[index] and sig must verify.
+// See VerifyChainSignature for the reason a check failed.
+func VerifyWithPKHList(pkhs [][32]byte, index int, pub *PublicKey, message [32]byte, sig *Signature) bool {
+	ok, err := VerifyChainSignature(pkhs, index, pub, message, sig)
+	return err == nil && ok
+}
+
+// VerifyChainSignature verifies a signature produced by SignWithKeyChain
+// with the key at position index of the chain's published PKH list.
+//
+// It returns ErrKeyIndexOutOfRange if index is outside pkhList and
+// ErrPKHMismatch if pub (or a nil pub) does not hash to pkhList[index]. A
+// signature that fails to verify against the matching key returns false
+// with a nil error.
+func VerifyChainSignature(pkhList [][32]byte, index int, pub *PublicKey, message [32]byte, sig *Signature) (bool, error) {
+	if index < 0 || index >= len(pkhList) {
+		return false, ErrKeyIndexOutOfRange
+	}
+	if pub == nil || !ConstantTimeEqualHash(pub.Hash(), pkhList[index]) {
+		return false, ErrPKHMismatch
+	}
+	return Verify(pub, message, sig), nil
+}
+
+// VerifyAgainstMessages is a diagnostic for aggregation bugs. It determines,
+// for each bit position, which side of the public key the revealed preimage
+// opens, then scores every candidate message by how many positions agree.
+//
+// It returns the best-scoring candidate, the number of positions it matches,
+// and ok = true only if that candidate matches all 256 positions. A result
+// with 0 < matched < 256 for every candidate indicates preimages assembled
+// from different messages (or corrupted preimages, which match no side).
+// A nil pub, or a nil or all-zero sig, matches nothing.
+func VerifyAgainstMessages(pub *PublicKey, sig *Signature, candidates [][32]byte) (matched int, message [32]byte, ok bool) {
+	if pub == nil || !sig.IsWellFormed() {
+		return 0, message, false
+	}
+	// revealed[i] is the side opened at position i, or -1 if neither matches
+	var revealed [KeyBits]int
+	for i := 0; i < KeyBits; i++ {
+		h := Keccak256(sig.Preimages[i][:])
+		switch h {
+		case pub.Hashes[i][0]:
+			revealed[i] = 0
+		case pub.Hashes[i][1]:
+			revealed[i] = 1
+		default:
+			revealed[i] = -1
+		}
+	}
+
+	for c := range candidates {
+		bits := NewBitVector(candidates[c])
+		count := 0
+		for i := 0; i < KeyBits; i++ {
+			if revealed[i] == int(bits[i]) {
+				count++
+			}
+		}
+		if c == 0 || count > matched {
+			matched, message = count, candidates[c]
+		}
+	}
+	return matched, message, len(candidates) > 0 && matched == KeyBits
+}
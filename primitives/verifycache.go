@@ -0,0 +1,84 @@
+package primitives
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultVerifyCacheSize is the default number of results kept by a VerifyCache
+const DefaultVerifyCacheSize = 1024
+
+// VerifyCache memoizes Verify results for repeated (public key, message,
+// signature) triples, e.g. retried requests at a gateway. It keeps the most
+// recent results up to a fixed capacity (LRU) and is safe for concurrent use.
+//
+// Entries are keyed on keccak256(pkh || message || sig.Bytes()), so a cache
+// hit still hashes the public key and signature once but skips the 256
+// per-preimage hashes of Verify.
+type VerifyCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[[32]byte]*list.Element
+
+	// verify is the underlying verifier (replaceable in tests)
+	verify func(pub *PublicKey, message [32]byte, sig *Signature) bool
+}
+
+type verifyCacheEntry struct {
+	key   [32]byte
+	valid bool
+}
+
+// NewVerifyCache creates a cache holding up to capacity results.
+// A non-positive capacity uses DefaultVerifyCacheSize.
+func NewVerifyCache(capacity int) *VerifyCache {
+	if capacity <= 0 {
+		capacity = DefaultVerifyCacheSize
+	}
+	return &VerifyCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[[32]byte]*list.Element, capacity),
+		verify:   Verify,
+	}
+}
+
+// Verify returns the cached result for (pub, message, sig), computing and
+// storing it with Verify on a miss.
+func (c *VerifyCache) Verify(pub *PublicKey, message [32]byte, sig *Signature) bool {
+	pkh := pub.Hash()
+	key := Keccak256Multi(pkh[:], message[:], sig.Bytes())
+
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		valid := e.Value.(*verifyCacheEntry).valid
+		c.mu.Unlock()
+		return valid
+	}
+	c.mu.Unlock()
+
+	// Verify outside the lock; concurrent misses on the same key are harmless
+	valid := c.verify(pub, message, sig)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok {
+		c.entries[key] = c.order.PushFront(&verifyCacheEntry{key: key, valid: valid})
+		if c.order.Len() > c.capacity {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(*verifyCacheEntry).key)
+		}
+	}
+	return valid
+}
+
+// Len returns the number of cached results.
+func (c *VerifyCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
@@ -0,0 +1,325 @@
+// Package proto provides protobuf wire codecs for Lamport keys, signatures,
+// and threshold shares.
+//
+// The schema lives in lamport.proto next to this file. The codecs are written
+// directly against the protobuf wire format so that Rust and TypeScript MPC
+// nodes using generated code from the same schema produce and accept identical
+// bytes. Encoding follows proto3 rules: fields are emitted in field-number
+// order and zero-valued scalars are omitted. Unknown fields are skipped on
+// decode.
+//
+// Fixed-size arrays use the same flat layout as the primitives Bytes() methods.
+package proto
+
+import (
+	"errors"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/luxfi/lamport/primitives"
+	"github.com/luxfi/lamport/threshold"
+)
+
+const (
+	// ShareSize is the size of the flat preimage_shares field
+	ShareSize = primitives.PrivateKeySize
+
+	// PartialSize is the size of the flat preimage_partials field
+	PartialSize = primitives.SignatureSize
+)
+
+var (
+	// ErrInvalidLength indicates a bytes field has the wrong size for its type
+	ErrInvalidLength = errors.New("proto: invalid field length")
+
+	// ErrWireType indicates a known field was encoded with an unexpected wire type
+	ErrWireType = errors.New("proto: unexpected wire type")
+)
+
+// PublicKey is the wire form of primitives.PublicKey.
+type PublicKey struct {
+	Hashes []byte // field 1
+}
+
+// Signature is the wire form of primitives.Signature.
+type Signature struct {
+	Preimages []byte // field 1
+}
+
+// Share is the wire form of threshold.Share.
+type Share struct {
+	PartyID        string // field 1
+	Index          int64  // field 2
+	PreimageShares []byte // field 3
+}
+
+// PartialSignature is the wire form of threshold.PartialSignature.
+type PartialSignature struct {
+	PartyID          string // field 1
+	Index            int64  // field 2
+	PreimagePartials []byte // field 3
+	BitMask          []byte // field 4
+}
+
+// DigestCommitment is the wire form of threshold.DigestCommitment.
+type DigestCommitment struct {
+	PartyID    string // field 1
+	Commitment []byte // field 2
+}
+
+// Marshal encodes the message to protobuf wire format.
+func (m *PublicKey) Marshal() []byte {
+	return appendBytes(nil, 1, m.Hashes)
+}
+
+// Unmarshal decodes the message from protobuf wire format.
+func (m *PublicKey) Unmarshal(b []byte) error {
+	return unmarshalFields(b, map[protowire.Number]field{
+		1: {bytes: &m.Hashes},
+	})
+}
+
+// Marshal encodes the message to protobuf wire format.
+func (m *Signature) Marshal() []byte {
+	return appendBytes(nil, 1, m.Preimages)
+}
+
+// Unmarshal decodes the message from protobuf wire format.
+func (m *Signature) Unmarshal(b []byte) error {
+	return unmarshalFields(b, map[protowire.Number]field{
+		1: {bytes: &m.Preimages},
+	})
+}
+
+// Marshal encodes the message to protobuf wire format.
+func (m *Share) Marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, m.PartyID)
+	b = appendVarint(b, 2, m.Index)
+	b = appendBytes(b, 3, m.PreimageShares)
+	return b
+}
+
+// Unmarshal decodes the message from protobuf wire format.
+func (m *Share) Unmarshal(b []byte) error {
+	return unmarshalFields(b, map[protowire.Number]field{
+		1: {str: &m.PartyID},
+		2: {varint: &m.Index},
+		3: {bytes: &m.PreimageShares},
+	})
+}
+
+// Marshal encodes the message to protobuf wire format.
+func (m *PartialSignature) Marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, m.PartyID)
+	b = appendVarint(b, 2, m.Index)
+	b = appendBytes(b, 3, m.PreimagePartials)
+	b = appendBytes(b, 4, m.BitMask)
+	return b
+}
+
+// Unmarshal decodes the message from protobuf wire format.
+func (m *PartialSignature) Unmarshal(b []byte) error {
+	return unmarshalFields(b, map[protowire.Number]field{
+		1: {str: &m.PartyID},
+		2: {varint: &m.Index},
+		3: {bytes: &m.PreimagePartials},
+		4: {bytes: &m.BitMask},
+	})
+}
+
+// Marshal encodes the message to protobuf wire format.
+func (m *DigestCommitment) Marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, m.PartyID)
+	b = appendBytes(b, 2, m.Commitment)
+	return b
+}
+
+// Unmarshal decodes the message from protobuf wire format.
+func (m *DigestCommitment) Unmarshal(b []byte) error {
+	return unmarshalFields(b, map[protowire.Number]field{
+		1: {str: &m.PartyID},
+		2: {bytes: &m.Commitment},
+	})
+}
+
+// PublicKeyToProto converts a public key to its wire form.
+func PublicKeyToProto(pk *primitives.PublicKey) *PublicKey {
+	return &PublicKey{Hashes: pk.Bytes()}
+}
+
+// PublicKeyFromProto converts the wire form back to a public key.
+func PublicKeyFromProto(m *PublicKey) (*primitives.PublicKey, error) {
+	pk := &primitives.PublicKey{}
+	if err := pk.FromBytes(m.Hashes); err != nil {
+		return nil, err
+	}
+	return pk, nil
+}
+
+// SignatureToProto converts a signature to its wire form.
+func SignatureToProto(sig *primitives.Signature) *Signature {
+	return &Signature{Preimages: sig.Bytes()}
+}
+
+// SignatureFromProto converts the wire form back to a signature.
+func SignatureFromProto(m *Signature) (*primitives.Signature, error) {
+	sig := &primitives.Signature{}
+	if err := sig.FromBytes(m.Preimages); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// ShareToProto converts a threshold share to its wire form.
+func ShareToProto(s *threshold.Share) *Share {
+	return &Share{
+		PartyID:        s.PartyID,
+		Index:          int64(s.Index),
+		PreimageShares: s.Bytes(),
+	}
+}
+
+// ShareFromProto converts the wire form back to a threshold share.
+func ShareFromProto(m *Share) (*threshold.Share, error) {
+	s := &threshold.Share{
+		PartyID: m.PartyID,
+		Index:   int(m.Index),
+	}
+	if err := s.FromBytes(m.PreimageShares); err != nil {
+		return nil, ErrInvalidLength
+	}
+	return s, nil
+}
+
+// PartialSignatureToProto converts a partial signature to its wire form.
+func PartialSignatureToProto(p *threshold.PartialSignature) *PartialSignature {
+	out := make([]byte, PartialSize)
+	for i := 0; i < primitives.KeyBits; i++ {
+		copy(out[i*32:(i+1)*32], p.PreimagePartials[i][:])
+	}
+	return &PartialSignature{
+		PartyID:          p.PartyID,
+		Index:            int64(p.Index),
+		PreimagePartials: out,
+		BitMask:          append([]byte(nil), p.BitMask[:]...),
+	}
+}
+
+// PartialSignatureFromProto converts the wire form back to a partial signature.
+func PartialSignatureFromProto(m *PartialSignature) (*threshold.PartialSignature, error) {
+	if len(m.PreimagePartials) != PartialSize || len(m.BitMask) != 32 {
+		return nil, ErrInvalidLength
+	}
+	p := &threshold.PartialSignature{
+		PartyID: m.PartyID,
+		Index:   int(m.Index),
+	}
+	for i := 0; i < primitives.KeyBits; i++ {
+		copy(p.PreimagePartials[i][:], m.PreimagePartials[i*32:(i+1)*32])
+	}
+	copy(p.BitMask[:], m.BitMask)
+	return p, nil
+}
+
+// DigestCommitmentToProto converts a digest commitment to its wire form.
+func DigestCommitmentToProto(c threshold.DigestCommitment) *DigestCommitment {
+	return &DigestCommitment{
+		PartyID:    c.PartyID,
+		Commitment: append([]byte(nil), c.Commitment[:]...),
+	}
+}
+
+// DigestCommitmentFromProto converts the wire form back to a digest commitment.
+func DigestCommitmentFromProto(m *DigestCommitment) (threshold.DigestCommitment, error) {
+	if len(m.Commitment) != 32 {
+		return threshold.DigestCommitment{}, ErrInvalidLength
+	}
+	c := threshold.DigestCommitment{PartyID: m.PartyID}
+	copy(c.Commitment[:], m.Commitment)
+	return c, nil
+}
+
+// field points at the destination of a known field during decoding.
+// Exactly one pointer is set.
+type field struct {
+	bytes  *[]byte
+	str    *string
+	varint *int64
+}
+
+// unmarshalFields walks the wire-format buffer and stores known fields.
+// Unknown fields are skipped for forward compatibility.
+func unmarshalFields(b []byte, fields map[protowire.Number]field) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		f, ok := fields[num]
+		if !ok {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+
+		switch {
+		case f.varint != nil:
+			if typ != protowire.VarintType {
+				return ErrWireType
+			}
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			*f.varint = int64(v)
+			b = b[n:]
+		default:
+			if typ != protowire.BytesType {
+				return ErrWireType
+			}
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			if f.str != nil {
+				*f.str = string(v)
+			} else {
+				*f.bytes = append([]byte(nil), v...)
+			}
+			b = b[n:]
+		}
+	}
+	return nil
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendVarint(b []byte, num protowire.Number, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
@@ -0,0 +1,122 @@
+package proto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/luxfi/lamport/internal/codectest"
+	"github.com/luxfi/lamport/primitives"
+	"github.com/luxfi/lamport/threshold"
+)
+
+func fixtureObjects() (*primitives.PublicKey, *primitives.Signature, *threshold.Share, *threshold.PartialSignature, threshold.DigestCommitment) {
+	o := codectest.NewObjects()
+	return o.PublicKey, o.Signature, o.Share, o.Partial, o.Commitment
+}
+
+func TestRoundTrip(t *testing.T) {
+	pk, sig, share, partial, dc := fixtureObjects()
+
+	var pkMsg PublicKey
+	if err := pkMsg.Unmarshal(PublicKeyToProto(pk).Marshal()); err != nil {
+		t.Fatalf("PublicKey Unmarshal failed: %v", err)
+	}
+	pk2, err := PublicKeyFromProto(&pkMsg)
+	if err != nil {
+		t.Fatalf("PublicKeyFromProto failed: %v", err)
+	}
+	if *pk2 != *pk {
+		t.Error("PublicKey round trip mismatch")
+	}
+
+	var sigMsg Signature
+	if err := sigMsg.Unmarshal(SignatureToProto(sig).Marshal()); err != nil {
+		t.Fatalf("Signature Unmarshal failed: %v", err)
+	}
+	sig2, err := SignatureFromProto(&sigMsg)
+	if err != nil {
+		t.Fatalf("SignatureFromProto failed: %v", err)
+	}
+	if *sig2 != *sig {
+		t.Error("Signature round trip mismatch")
+	}
+
+	var shareMsg Share
+	if err := shareMsg.Unmarshal(ShareToProto(share).Marshal()); err != nil {
+		t.Fatalf("Share Unmarshal failed: %v", err)
+	}
+	share2, err := ShareFromProto(&shareMsg)
+	if err != nil {
+		t.Fatalf("ShareFromProto failed: %v", err)
+	}
+	if *share2 != *share {
+		t.Error("Share round trip mismatch")
+	}
+
+	var partialMsg PartialSignature
+	if err := partialMsg.Unmarshal(PartialSignatureToProto(partial).Marshal()); err != nil {
+		t.Fatalf("PartialSignature Unmarshal failed: %v", err)
+	}
+	partial2, err := PartialSignatureFromProto(&partialMsg)
+	if err != nil {
+		t.Fatalf("PartialSignatureFromProto failed: %v", err)
+	}
+	if *partial2 != *partial {
+		t.Error("PartialSignature round trip mismatch")
+	}
+
+	var dcMsg DigestCommitment
+	if err := dcMsg.Unmarshal(DigestCommitmentToProto(dc).Marshal()); err != nil {
+		t.Fatalf("DigestCommitment Unmarshal failed: %v", err)
+	}
+	dc2, err := DigestCommitmentFromProto(&dcMsg)
+	if err != nil {
+		t.Fatalf("DigestCommitmentFromProto failed: %v", err)
+	}
+	if dc2 != dc {
+		t.Error("DigestCommitment round trip mismatch")
+	}
+}
+
+func TestUnknownFieldsSkipped(t *testing.T) {
+	_, _, _, _, dc := fixtureObjects()
+	data := DigestCommitmentToProto(dc).Marshal()
+
+	// Append field 15 (varint) which this version does not know about
+	data = append(data, 15<<3, 0x2a)
+
+	var m DigestCommitment
+	if err := m.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal with unknown field failed: %v", err)
+	}
+	if _, err := DigestCommitmentFromProto(&m); err != nil {
+		t.Fatalf("DigestCommitmentFromProto failed: %v", err)
+	}
+}
+
+func TestInvalidLength(t *testing.T) {
+	if _, err := ShareFromProto(&Share{PreimageShares: make([]byte, 10)}); err != ErrInvalidLength {
+		t.Errorf("Expected ErrInvalidLength, got %v", err)
+	}
+	if _, err := PartialSignatureFromProto(&PartialSignature{PreimagePartials: make([]byte, PartialSize)}); err != ErrInvalidLength {
+		t.Errorf("Expected ErrInvalidLength, got %v", err)
+	}
+	if _, err := PublicKeyFromProto(&PublicKey{}); err != primitives.ErrInvalidPublicKey {
+		t.Errorf("Expected ErrInvalidPublicKey, got %v", err)
+	}
+}
+
+// TestFixture checks encodings against testdata/vectors.json, which other
+// language implementations use to validate their codecs.
+func TestFixture(t *testing.T) {
+	pk, sig, share, partial, dc := fixtureObjects()
+	got := map[string]string{
+		"public_key":        hex.EncodeToString(PublicKeyToProto(pk).Marshal()),
+		"signature":         hex.EncodeToString(SignatureToProto(sig).Marshal()),
+		"share":             hex.EncodeToString(ShareToProto(share).Marshal()),
+		"partial_signature": hex.EncodeToString(PartialSignatureToProto(partial).Marshal()),
+		"digest_commitment": hex.EncodeToString(DigestCommitmentToProto(dc).Marshal()),
+	}
+
+	codectest.CheckVectors(t, got)
+}
@@ -0,0 +1,176 @@
+// Package testvectors generates canonical Lamport test vectors for teams
+// porting the verifier to Solidity, Rust, and other languages.
+//
+// All output is derived from a single 32-byte seed, so the same seed always
+// produces byte-identical vectors. Byte fields are 0x-prefixed lowercase hex
+// using the primitives Bytes() layouts.
+package testvectors
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/luxfi/lamport/primitives"
+	"github.com/luxfi/lamport/threshold"
+)
+
+// Vector is one single-key signing example.
+type Vector struct {
+	Seed          string `json:"seed"` // keccak256(seed || uint32(index)), input to GenerateKeyPairFromSeed
+	PrivateKeyHex string `json:"privateKeyHex"`
+	PublicKeyHex  string `json:"publicKeyHex"`
+	PKH           string `json:"pkh"`
+	Message       string `json:"message"`
+	SignatureHex  string `json:"signatureHex"`
+}
+
+// ThresholdVector is an n-of-n additive threshold signing example over a
+// domain-separated threshold message.
+type ThresholdVector struct {
+	Seed          string   `json:"seed"` // input to threshold.GenerateSharesFromSeed
+	Parties       int      `json:"parties"`
+	ModuleAddress string   `json:"moduleAddress"`
+	ChainID       uint64   `json:"chainId"`
+	SafeTxHash    string   `json:"safeTxHash"`
+	NextPKH       string   `json:"nextPKH"`
+	Message       string   `json:"message"` // ComputeThresholdMessage(safeTxHash, nextPKH, moduleAddress, chainId)
+	SharesHex     []string `json:"sharesHex"`
+	PartialsHex   []string `json:"partialsHex"` // revealed share preimages, Signature.Bytes() layout
+	PublicKeyHex  string   `json:"publicKeyHex"`
+	PKH           string   `json:"pkh"`
+	SignatureHex  string   `json:"signatureHex"` // XOR of the partials
+}
+
+// File is the JSON document written by WriteJSON.
+type File struct {
+	Seed      string          `json:"seed"`
+	Vectors   []Vector        `json:"vectors"`
+	Threshold ThresholdVector `json:"threshold"`
+}
+
+// thresholdParties is the party count of the threshold example
+const thresholdParties = 3
+
+// GenerateTestVectors derives count single-key vectors from seed. Vector 0
+// signs the all-zero message and vector 1 the all-ones message, so both
+// sides of every bit position are exercised; later messages are
+// keccak256(vector seed || "message").
+func GenerateTestVectors(seed [32]byte, count int) []Vector {
+	vectors := make([]Vector, count)
+	for i := range vectors {
+		vecSeed := indexSeed(seed, i)
+		// Seeded generation never fails
+		kp, _ := primitives.GenerateKeyPairFromSeed(vecSeed)
+
+		var message [32]byte
+		switch i {
+		case 0:
+		case 1:
+			for j := range message {
+				message[j] = 0xff
+			}
+		default:
+			message = primitives.Keccak256Multi(vecSeed[:], []byte("message"))
+		}
+
+		pkh := kp.Public.Hash()
+		sig, _ := primitives.Sign(kp.Private, message)
+		vectors[i] = Vector{
+			Seed:          hexString(vecSeed[:]),
+			PrivateKeyHex: hexString(kp.Private.Bytes()),
+			PublicKeyHex:  hexString(kp.Public.Bytes()),
+			PKH:           hexString(pkh[:]),
+			Message:       hexString(message[:]),
+			SignatureHex:  hexString(sig.Bytes()),
+		}
+	}
+	return vectors
+}
+
+// GenerateThresholdVector derives a 3-of-3 threshold example from seed.
+func GenerateThresholdVector(seed [32]byte) (ThresholdVector, error) {
+	shareSeed := primitives.Keccak256Multi(seed[:], []byte("threshold"))
+	shares, pub, err := threshold.GenerateSharesFromSeed(thresholdParties, shareSeed)
+	if err != nil {
+		return ThresholdVector{}, err
+	}
+
+	var moduleAddr [20]byte
+	moduleHash := primitives.Keccak256Multi(shareSeed[:], []byte("module"))
+	copy(moduleAddr[:], moduleHash[:20])
+	safeTxHash := primitives.Keccak256Multi(shareSeed[:], []byte("safeTxHash"))
+	nextPKH := primitives.Keccak256Multi(shareSeed[:], []byte("nextPKH"))
+	const chainID = 96369
+
+	config, err := threshold.NewConfig(thresholdParties, thresholdParties, "vectors", chainID, moduleAddr)
+	if err != nil {
+		return ThresholdVector{}, err
+	}
+	message := config.ComputeMessage(safeTxHash, nextPKH)
+
+	v := ThresholdVector{
+		Seed:          hexString(shareSeed[:]),
+		Parties:       thresholdParties,
+		ModuleAddress: primitives.AddressChecksum(moduleAddr),
+		ChainID:       chainID,
+		SafeTxHash:    hexString(safeTxHash[:]),
+		NextPKH:       hexString(nextPKH[:]),
+		Message:       hexString(message[:]),
+		PublicKeyHex:  hexString(pub.Bytes()),
+	}
+	partials := make([]*threshold.PartialSignature, len(shares))
+	for j, share := range shares {
+		partials[j] = threshold.CreatePartialSignature(share, message)
+		partialSig := primitives.Signature{Preimages: partials[j].PreimagePartials}
+		v.SharesHex = append(v.SharesHex, hexString(share.Bytes()))
+		v.PartialsHex = append(v.PartialsHex, hexString(partialSig.Bytes()))
+	}
+
+	sig, err := threshold.AggregateAndVerify(partials, pub, message)
+	if err != nil {
+		return ThresholdVector{}, err
+	}
+	pkh := pub.Hash()
+	v.PKH = hexString(pkh[:])
+	v.SignatureHex = hexString(sig.Bytes())
+	return v, nil
+}
+
+// WriteJSON writes count single-key vectors and the threshold example for
+// seed to w as indented JSON.
+func WriteJSON(w io.Writer, seed [32]byte, count int) error {
+	th, err := GenerateThresholdVector(seed)
+	if err != nil {
+		return err
+	}
+	file := File{
+		Seed:      hexString(seed[:]),
+		Vectors:   GenerateTestVectors(seed, count),
+		Threshold: th,
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(file)
+}
+
+// indexSeed returns keccak256(seed || uint32(i)).
+func indexSeed(seed [32]byte, i int) [32]byte {
+	var idx [4]byte
+	binary.BigEndian.PutUint32(idx[:], uint32(i))
+	return primitives.Keccak256Multi(seed[:], idx[:])
+}
+
+func hexString(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
+
+// ParseHex decodes a 0x-prefixed hex field from a vector.
+func ParseHex(s string) ([]byte, error) {
+	if len(s) < 2 || s[:2] != "0x" {
+		return nil, fmt.Errorf("testvectors: missing 0x prefix in %q", s)
+	}
+	return hex.DecodeString(s[2:])
+}
@@ -0,0 +1,115 @@
+package testvectors
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/luxfi/lamport/primitives"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := ParseHex(s)
+	if err != nil {
+		t.Fatalf("ParseHex(%q...) failed: %v", s[:min(len(s), 10)], err)
+	}
+	return b
+}
+
+func TestVectorsSelfConsistent(t *testing.T) {
+	seed := primitives.Keccak256([]byte("vectors"))
+	vectors := GenerateTestVectors(seed, 4)
+	if len(vectors) != 4 {
+		t.Fatalf("Expected 4 vectors, got %d", len(vectors))
+	}
+
+	for i, v := range vectors {
+		var priv primitives.PrivateKey
+		var pub primitives.PublicKey
+		var sig primitives.Signature
+		if err := priv.FromBytes(mustHex(t, v.PrivateKeyHex)); err != nil {
+			t.Fatalf("vector %d: private key: %v", i, err)
+		}
+		if err := pub.FromBytes(mustHex(t, v.PublicKeyHex)); err != nil {
+			t.Fatalf("vector %d: public key: %v", i, err)
+		}
+		if err := sig.FromBytes(mustHex(t, v.SignatureHex)); err != nil {
+			t.Fatalf("vector %d: signature: %v", i, err)
+		}
+		var message [32]byte
+		copy(message[:], mustHex(t, v.Message))
+
+		if *priv.DerivePublic() != pub {
+			t.Errorf("vector %d: public key does not match private key", i)
+		}
+		pkh := pub.Hash()
+		if !bytes.Equal(pkh[:], mustHex(t, v.PKH)) {
+			t.Errorf("vector %d: PKH does not match public key", i)
+		}
+		if !primitives.Verify(&pub, message, &sig) {
+			t.Errorf("vector %d: signature does not verify", i)
+		}
+	}
+
+	// The same seed always yields the same vectors
+	again := GenerateTestVectors(seed, 4)
+	for i := range vectors {
+		if vectors[i] != again[i] {
+			t.Errorf("vector %d is not deterministic", i)
+		}
+	}
+}
+
+func TestThresholdVectorSelfConsistent(t *testing.T) {
+	v, err := GenerateThresholdVector(primitives.Keccak256([]byte("vectors")))
+	if err != nil {
+		t.Fatalf("GenerateThresholdVector failed: %v", err)
+	}
+
+	var pub primitives.PublicKey
+	var sig primitives.Signature
+	if err := pub.FromBytes(mustHex(t, v.PublicKeyHex)); err != nil {
+		t.Fatalf("public key: %v", err)
+	}
+	if err := sig.FromBytes(mustHex(t, v.SignatureHex)); err != nil {
+		t.Fatalf("signature: %v", err)
+	}
+	moduleAddr, err := primitives.ParseAddress(v.ModuleAddress)
+	if err != nil {
+		t.Fatalf("module address: %v", err)
+	}
+	var safeTxHash, nextPKH, pkh [32]byte
+	copy(safeTxHash[:], mustHex(t, v.SafeTxHash))
+	copy(nextPKH[:], mustHex(t, v.NextPKH))
+	copy(pkh[:], mustHex(t, v.PKH))
+
+	if !primitives.VerifyThresholdMessage(&pub, &sig, safeTxHash, nextPKH, moduleAddr, v.ChainID, pkh) {
+		t.Error("Threshold signature does not verify")
+	}
+
+	// The signature is the XOR of the partials
+	combined := make([]byte, primitives.SignatureSize)
+	for _, p := range v.PartialsHex {
+		for k, c := range mustHex(t, p) {
+			combined[k] ^= c
+		}
+	}
+	if !bytes.Equal(combined, sig.Bytes()) {
+		t.Error("Signature is not the XOR of the partials")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, [32]byte{}, 2); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var f File
+	if err := json.Unmarshal(buf.Bytes(), &f); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if len(f.Vectors) != 2 || f.Threshold.Parties != thresholdParties {
+		t.Errorf("Unexpected file contents: %d vectors, %d parties", len(f.Vectors), f.Threshold.Parties)
+	}
+}
@@ -1,7 +1,11 @@
 package threshold
 
 import (
+	"cmp"
 	"errors"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/luxfi/lamport/primitives"
 )
@@ -11,25 +15,43 @@ import (
 // For additive secret sharing:
 //   finalPreimage[i] = XOR(partial[0].preimage[i], partial[1].preimage[i], ...)
 //
+// Partials are processed in Index order, so the result and any error do not
+// depend on the order they arrived in. Every partial must carry the same
+// BitMask (ErrDigestMismatch otherwise), and since additive sharing needs
+// every share, the indices must be exactly 1..len(partials); a gap or
+// duplicate returns ErrInvalidShareIndex.
+//
 // SECURITY: All partials must be for the same message.
 func Aggregate(partials []*PartialSignature) (*primitives.Signature, error) {
 	if len(partials) == 0 {
 		return nil, ErrNotEnoughParties
 	}
+	if slices.Contains(partials, nil) {
+		return nil, ErrInvalidPartial
+	}
+
+	sorted := slices.Clone(partials)
+	slices.SortStableFunc(sorted, func(a, b *PartialSignature) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
 
 	// Verify all partials are for the same message
-	expectedMask := partials[0].BitMask
-	for _, p := range partials[1:] {
-		if p.BitMask != expectedMask {
+	for _, p := range sorted[1:] {
+		if p.BitMask != sorted[0].BitMask {
 			return nil, ErrDigestMismatch
 		}
 	}
+	for j, p := range sorted {
+		if p.Index != j+1 {
+			return nil, ErrInvalidShareIndex
+		}
+	}
 
 	sig := &primitives.Signature{}
 
 	// Combine partials using XOR (additive sharing)
 	for i := 0; i < primitives.KeyBits; i++ {
-		for _, partial := range partials {
+		for _, partial := range sorted {
 			for k := 0; k < primitives.PreimageSize; k++ {
 				sig.Preimages[i][k] ^= partial.PreimagePartials[i][k]
 			}
@@ -39,6 +61,95 @@ func Aggregate(partials []*PartialSignature) (*primitives.Signature, error) {
 	return sig, nil
 }
 
+// AggregateShamir combines Shamir partial signatures into a complete Lamport signature.
+//
+// Each revealed preimage byte is recovered by Lagrange interpolation at x = 0
+// over GF(2^8), using each partial's Index as its x-coordinate. At least t
+// partials from GenerateSharesShamir are required; fewer produce a signature
+// that will not verify.
+//
+// SECURITY: All partials must be for the same message.
+func AggregateShamir(partials []*PartialSignature) (*primitives.Signature, error) {
+	if len(partials) == 0 {
+		return nil, ErrNotEnoughParties
+	}
+	if slices.Contains(partials, nil) {
+		return nil, ErrInvalidPartial
+	}
+
+	expectedMask := partials[0].BitMask
+	indices := make([]int, len(partials))
+	for j, p := range partials {
+		if p.BitMask != expectedMask {
+			return nil, ErrDigestMismatch
+		}
+		indices[j] = p.Index
+	}
+
+	coeffs, err := lagrangeAtZero(indices)
+	if err != nil {
+		return nil, err
+	}
+
+	sig := &primitives.Signature{}
+	for i := 0; i < primitives.KeyBits; i++ {
+		for j, partial := range partials {
+			for k := 0; k < primitives.PreimageSize; k++ {
+				sig.Preimages[i][k] ^= gfMul(coeffs[j], partial.PreimagePartials[i][k])
+			}
+		}
+	}
+
+	return sig, nil
+}
+
+// AggregateShamirVerified combines Shamir partials for message, tolerating
+// surplus and bad partials. Each partial is checked against the public
+// shares registered for its PartyID (see PublicSharesByParty); partials for
+// another message, from an unknown or repeated party or Index, or failing
+// PartyPublicShares.VerifyPartial are dropped. The first t valid partials
+// are interpolated and the rest ignored. Returns ErrNotEnoughParties if
+// fewer than t valid partials remain.
+func AggregateShamirVerified(
+	t int,
+	message [32]byte,
+	partials []*PartialSignature,
+	publicShares map[string]*PartyPublicShares,
+) (*primitives.Signature, error) {
+	if t < 1 {
+		return nil, ErrInvalidThreshold
+	}
+
+	selected := make([]*PartialSignature, 0, t)
+	seenParty := make(map[string]struct{}, t)
+	seenIndex := make(map[int]struct{}, t)
+	for _, p := range partials {
+		if len(selected) == t {
+			break
+		}
+		if p == nil || p.BitMask != message {
+			continue
+		}
+		_, dupParty := seenParty[p.PartyID]
+		_, dupIndex := seenIndex[p.Index]
+		if dupParty || dupIndex {
+			continue
+		}
+		shares, ok := publicShares[p.PartyID]
+		if !ok || !shares.VerifyPartial(p) {
+			continue
+		}
+		seenParty[p.PartyID] = struct{}{}
+		seenIndex[p.Index] = struct{}{}
+		selected = append(selected, p)
+	}
+	if len(selected) < t {
+		return nil, ErrNotEnoughParties
+	}
+
+	return AggregateShamir(selected)
+}
+
 // AggregateAndVerify combines partials and verifies against the public key.
 func AggregateAndVerify(
 	partials []*PartialSignature,
@@ -57,6 +168,95 @@ func AggregateAndVerify(
 	return sig, nil
 }
 
+// AggregateAndLocate is AggregateAndVerify for debugging additive rounds:
+// if the aggregate does not verify, it checks every bit position and
+// returns the failing ones in ascending order with ErrInvalidPartial. Since
+// each position XORs every party's partial, a corrupted partial shows up
+// exactly at the positions it corrupted; compare each party's partial at
+// those positions (e.g. with PartyPublicShares.VerifyPartial) to find it.
+//
+// A valid aggregate costs the same as AggregateAndVerify, as the per-bit
+// pass only runs after Verify fails.
+func AggregateAndLocate(
+	partials []*PartialSignature,
+	pub *primitives.PublicKey,
+	message [32]byte,
+) (*primitives.Signature, []int, error) {
+	if pub == nil {
+		return nil, nil, ErrInvalidPartial
+	}
+	sig, err := Aggregate(partials)
+	if err != nil {
+		return nil, nil, err
+	}
+	if primitives.Verify(pub, message, sig) {
+		return sig, nil, nil
+	}
+
+	var failed []int
+	for i := 0; i < primitives.KeyBits; i++ {
+		if !pub.VerifyBit(i, primitives.GetBit(message, i), sig.Preimages[i]) {
+			failed = append(failed, i)
+		}
+	}
+	return nil, failed, ErrInvalidPartial
+}
+
+// AggregateBestEffort aggregates whatever valid partials are available
+// instead of aborting the round on one bad node. A partial is dropped if it
+// is for another message, repeats a PartyID, has no entry in pubShares, or
+// fails that party's PartyPublicShares.VerifyPartial. The PartyIDs of
+// dropped partials are returned in input order, alongside any error.
+//
+// If fewer than threshold partials survive, it returns ErrNotEnoughParties.
+// Otherwise the survivors are combined additively; if that does not verify
+// against pub (e.g. Shamir shares), it retries with Lagrange interpolation
+// over the first threshold survivors, and returns ErrInvalidPartial if
+// neither verifies.
+func AggregateBestEffort(
+	partials []*PartialSignature,
+	pubShares map[string]*PartyPublicShares,
+	pub *primitives.PublicKey,
+	message [32]byte,
+	threshold int,
+) (*primitives.Signature, []string, error) {
+	if threshold < 1 {
+		return nil, nil, ErrInvalidThreshold
+	}
+
+	var dropped []string
+	valid := make([]*PartialSignature, 0, len(partials))
+	seen := make(map[string]struct{}, len(partials))
+	for _, p := range partials {
+		if p == nil {
+			continue
+		}
+		_, dup := seen[p.PartyID]
+		shares, ok := pubShares[p.PartyID]
+		if dup || !ok || p.BitMask != message || !shares.VerifyPartial(p) {
+			dropped = append(dropped, p.PartyID)
+			continue
+		}
+		seen[p.PartyID] = struct{}{}
+		valid = append(valid, p)
+	}
+	if len(valid) < threshold {
+		return nil, dropped, ErrNotEnoughParties
+	}
+
+	if sig, err := AggregateAndVerify(valid, pub, message); err == nil {
+		return sig, dropped, nil
+	}
+	sig, err := AggregateShamir(valid[:threshold])
+	if err != nil {
+		return nil, dropped, err
+	}
+	if !primitives.Verify(pub, message, sig) {
+		return nil, dropped, ErrInvalidPartial
+	}
+	return sig, dropped, nil
+}
+
 // AggregateThreshold performs full threshold aggregation with verification.
 //
 // This is the coordinator's workflow:
@@ -64,6 +264,8 @@ func AggregateAndVerify(
 //  2. Verify all parties agreed on the same message (via BitMask)
 //  3. Aggregate partials into complete signature
 //  4. Verify signature against public key
+//
+// If config.ReplayGuard is set, a message that already completed returns ErrReplay.
 func AggregateThreshold(
 	config *Config,
 	partials []*PartialSignature,
@@ -77,6 +279,9 @@ func AggregateThreshold(
 
 	// Compute expected message
 	message := config.ComputeMessage(safeTxHash, nextPKH)
+	if config.ReplayGuard != nil && config.ReplayGuard.Seen(message) {
+		return nil, ErrReplay
+	}
 
 	// Verify all partials are for the correct message
 	for _, p := range partials {
@@ -85,19 +290,43 @@ func AggregateThreshold(
 		}
 	}
 
-	return AggregateAndVerify(partials, pub, message)
+	sig, err := AggregateAndVerify(partials, pub, message)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the completed message; a concurrent round may have won the race
+	if config.ReplayGuard != nil {
+		if err := config.ReplayGuard.Check(message); err != nil {
+			return nil, err
+		}
+	}
+
+	return sig, nil
 }
 
 // Coordinator manages the threshold signing protocol.
+// It is safe for concurrent use: commitments and partials may arrive from
+// many goroutines, and the completed signature is returned to exactly one caller.
 type Coordinator struct {
+	mu sync.Mutex
+
 	config   *Config
 	partials []*PartialSignature
 	pub      *primitives.PublicKey
 	message  [32]byte
+	mode     AggregationMode
+
+	// publicShares maps PartyID to its dealer commitment
+	publicShares map[string]*PartyPublicShares
 
 	// Phase tracking
 	commitments []DigestCommitment
-	phase       int // 0: collecting commitments, 1: collecting partials, 2: done
+	disagreeing []string // parties whose commitments did not match
+	phase       int      // 0: collecting commitments, 1: collecting partials, 2: done
+	timings     PhaseTimings
+
+	onThreshold func(sig *primitives.Signature)
 }
 
 // NewCoordinator creates a new signing coordinator.
@@ -109,19 +338,68 @@ func NewCoordinator(config *Config, pub *primitives.PublicKey, safeTxHash, nextP
 		commitments: make([]DigestCommitment, 0, config.TotalParties),
 		partials:    make([]*PartialSignature, 0, config.Threshold),
 		phase:       0,
+		timings:     PhaseTimings{Started: time.Now()},
 	}
 }
 
+// SetPublicShares registers each party's public share commitment, keyed
+// by PartyID (see PublicSharesByParty). Once set, AddPartial checks every
+// partial with PartyPublicShares.VerifyPartial and rejects bad or unknown
+// parties with a *PartyError wrapping ErrInvalidPartial.
+func (c *Coordinator) SetPublicShares(shares map[string]*PartyPublicShares) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.publicShares = shares
+}
+
+// SetAggregationMode selects how AddPartial combines partials: ModeAdditive
+// (the default) for GenerateShares, where the threshold must equal the
+// number of shares, or ModeShamir for GenerateSharesShamir, where any
+// Threshold parties complete the round.
+func (c *Coordinator) SetAggregationMode(mode AggregationMode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.mode = mode
+}
+
+// OnThresholdReached registers fn to be called once, with the completed
+// signature, when AddPartial aggregates a valid signature. fn runs on its
+// own goroutine so it never delays AddPartial's return, and receives its
+// own copy of the signature. Registering again replaces fn.
+func (c *Coordinator) OnThresholdReached(fn func(sig *primitives.Signature)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onThreshold = fn
+}
+
 // AddCommitment adds a digest commitment (phase 1).
-// Returns true if we have enough commitments to proceed.
+// Returns true if we have enough commitments to proceed. A commitment for
+// a different safeTxHash returns a *CommitmentMismatchError (wrapping
+// ErrDigestMismatch) and the party is listed by DisagreeingParties.
 func (c *Coordinator) AddCommitment(commitment DigestCommitment, safeTxHash [32]byte) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.phase != 0 {
 		return false, errors.New("threshold: not in commitment phase")
 	}
+	if c.config.ReplayGuard != nil && c.config.ReplayGuard.Seen(c.message) {
+		return false, ErrReplay
+	}
 
-	// Verify commitment
-	if !VerifyDigestCommitment(commitment, safeTxHash) {
-		return false, ErrDigestMismatch
+	// Verify commitment, remembering who disagreed
+	if expected := digestCommitment(safeTxHash, commitment.PartyID); commitment.Commitment != expected {
+		if !slices.Contains(c.disagreeing, commitment.PartyID) {
+			c.disagreeing = append(c.disagreeing, commitment.PartyID)
+		}
+		return false, &CommitmentMismatchError{
+			PartyID:  commitment.PartyID,
+			Expected: expected,
+			Received: commitment.Commitment,
+		}
 	}
 
 	c.commitments = append(c.commitments, commitment)
@@ -129,6 +407,7 @@ func (c *Coordinator) AddCommitment(commitment DigestCommitment, safeTxHash [32]
 	// Need at least threshold commitments to proceed
 	if len(c.commitments) >= c.config.Threshold {
 		c.phase = 1
+		c.timings.CommitmentsDone = time.Now()
 		return true, nil
 	}
 
@@ -137,31 +416,128 @@ func (c *Coordinator) AddCommitment(commitment DigestCommitment, safeTxHash [32]
 
 // AddPartial adds a partial signature (phase 2).
 // Returns the completed signature if we have enough, nil otherwise.
+// The partial's PartyID must have submitted a digest commitment in phase 1,
+// otherwise ErrNoCommitment is returned. Resubmitting an identical partial
+// (at-least-once delivery) is a no-op; a different partial with the same
+// PartyID or Index returns ErrDuplicateParty. If public shares were set, a
+// partial that fails VerifyPartial is rejected without affecting the round.
+// A nil partial returns ErrInvalidPartial.
 func (c *Coordinator) AddPartial(partial *PartialSignature) (*primitives.Signature, error) {
+	if partial == nil {
+		return nil, ErrInvalidPartial
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.phase != 1 {
 		return nil, errors.New("threshold: not in partial collection phase")
 	}
 
+	// Tie each signer to its prior commitment
+	if !c.hasCommitment(partial.PartyID) {
+		return nil, ErrNoCommitment
+	}
+
 	// Verify partial is for correct message
 	if partial.BitMask != c.message {
 		return nil, ErrDigestMismatch
 	}
 
+	// Catch a bad party now rather than failing aggregation for everyone
+	if c.publicShares != nil {
+		shares, ok := c.publicShares[partial.PartyID]
+		if !ok || !shares.VerifyPartial(partial) {
+			return nil, &PartyError{PartyID: partial.PartyID, Err: ErrInvalidPartial}
+		}
+	}
+
+	// Redelivery of an identical partial is a no-op; anything else reusing
+	// the party's ID or index is a conflict
+	for _, existing := range c.partials {
+		if existing.PartyID != partial.PartyID && existing.Index != partial.Index {
+			continue
+		}
+		if *existing == *partial {
+			return nil, nil
+		}
+		return nil, ErrDuplicateParty
+	}
+
 	c.partials = append(c.partials, partial)
 
 	// Check if we have enough partials
 	if len(c.partials) >= c.config.Threshold {
-		sig, err := AggregateAndVerify(c.partials, c.pub, c.message)
+		c.timings.PartialsDone = time.Now()
+		sig, err := c.aggregate()
 		if err != nil {
 			return nil, err
 		}
+		if c.config.ReplayGuard != nil {
+			if err := c.config.ReplayGuard.Check(c.message); err != nil {
+				return nil, err
+			}
+		}
 		c.phase = 2
+		c.timings.Completed = time.Now()
+		if fn := c.onThreshold; fn != nil {
+			notified := *sig
+			go fn(&notified)
+		}
 		return sig, nil
 	}
 
 	return nil, nil
 }
 
+// aggregate combines the collected partials under the coordinator's mode
+// and verifies the result against its public key. c.mu must be held.
+func (c *Coordinator) aggregate() (*primitives.Signature, error) {
+	if c.mode != ModeShamir {
+		return AggregateAndVerify(c.partials, c.pub, c.message)
+	}
+	sig, err := AggregateShamir(c.partials)
+	if err != nil {
+		return nil, err
+	}
+	if !primitives.Verify(c.pub, c.message, sig) {
+		return nil, ErrInvalidPartial
+	}
+	return sig, nil
+}
+
+// ZeroPartials zeroes every collected partial (see PartialSignature.Zero)
+// and discards them. Call it after AddPartial returns the final signature,
+// which is unaffected. The partials are the ones passed to AddPartial, so
+// callers' copies are wiped too.
+func (c *Coordinator) ZeroPartials() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, partial := range c.partials {
+		partial.Zero()
+	}
+	c.partials = nil
+}
+
+// hasCommitment reports whether partyID submitted a digest commitment.
+func (c *Coordinator) hasCommitment(partyID string) bool {
+	for _, commitment := range c.commitments {
+		if commitment.PartyID == partyID {
+			return true
+		}
+	}
+	return false
+}
+
+// DisagreeingParties returns the parties whose digest commitments did not
+// match, in the order they were first rejected.
+func (c *Coordinator) DisagreeingParties() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return slices.Clone(c.disagreeing)
+}
+
 // Message returns the expected message hash.
 func (c *Coordinator) Message() [32]byte {
 	return c.message
@@ -169,5 +545,8 @@ func (c *Coordinator) Message() [32]byte {
 
 // Phase returns the current protocol phase.
 func (c *Coordinator) Phase() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	return c.phase
 }
@@ -0,0 +1,84 @@
+package threshold
+
+import (
+	"errors"
+
+	"github.com/luxfi/lamport/primitives"
+)
+
+// AggregationMode selects how partial signatures are combined.
+type AggregationMode int
+
+const (
+	// ModeAdditive XORs partials from GenerateShares (all n parties required)
+	ModeAdditive AggregationMode = iota
+
+	// ModeShamir interpolates partials from GenerateSharesShamir (any t parties)
+	ModeShamir
+)
+
+// ErrDuplicatePartial indicates a second partial with an index already added
+var ErrDuplicatePartial = errors.New("threshold: duplicate partial for party index")
+
+// Aggregator incrementally collects partial signatures and combines them,
+// without the commitment phase and message derivation of Coordinator.
+type Aggregator struct {
+	mode      AggregationMode
+	threshold int
+
+	bitMask  [32]byte
+	partials []*PartialSignature
+	indices  map[int]struct{}
+}
+
+// NewAggregator creates an aggregator that needs threshold partials before
+// Finalize. For ModeAdditive, threshold should be the total number of shares.
+func NewAggregator(mode AggregationMode, threshold int) *Aggregator {
+	return &Aggregator{
+		mode:      mode,
+		threshold: threshold,
+		indices:   make(map[int]struct{}),
+	}
+}
+
+// Add adds a partial signature. The first partial fixes the message; later
+// partials with a different BitMask return ErrDigestMismatch and a repeated
+// Index returns ErrDuplicatePartial. Rejected partials are not stored.
+func (a *Aggregator) Add(partial *PartialSignature) error {
+	if len(a.partials) > 0 && partial.BitMask != a.bitMask {
+		return ErrDigestMismatch
+	}
+	if _, dup := a.indices[partial.Index]; dup {
+		return ErrDuplicatePartial
+	}
+
+	if len(a.partials) == 0 {
+		a.bitMask = partial.BitMask
+	}
+	a.indices[partial.Index] = struct{}{}
+	a.partials = append(a.partials, partial)
+	return nil
+}
+
+// Count returns the number of partials added so far.
+func (a *Aggregator) Count() int {
+	return len(a.partials)
+}
+
+// Message returns the message fixed by the first partial (zero if none yet).
+func (a *Aggregator) Message() [32]byte {
+	return a.bitMask
+}
+
+// Finalize combines the collected partials into a complete signature.
+// Returns ErrNotEnoughParties if fewer than threshold partials were added.
+func (a *Aggregator) Finalize() (*primitives.Signature, error) {
+	if len(a.partials) < a.threshold || len(a.partials) == 0 {
+		return nil, ErrNotEnoughParties
+	}
+
+	if a.mode == ModeShamir {
+		return AggregateShamir(a.partials)
+	}
+	return Aggregate(a.partials)
+}
@@ -0,0 +1,72 @@
+package threshold
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/luxfi/lamport/primitives"
+)
+
+var (
+	// ErrBatchSize indicates a batch without exactly one share per message
+	ErrBatchSize = errors.New("threshold: batch needs exactly one share per message")
+
+	// ErrBatchKeyReuse indicates a batch that signs two messages with the same shared key
+	ErrBatchKeyReuse = errors.New("threshold: batch reuses a shared key (each message needs its own key)")
+)
+
+// CreatePartialBatch creates this party's partials for k messages in one round.
+//
+// SECURITY: Lamport keys are one-time, so a batch of k messages needs k
+// independent shared keys. shares[j] is this party's share of the j-th key
+// and signs messages[j]. Passing the same share twice (or two identical
+// shares) returns ErrBatchKeyReuse; a length mismatch returns ErrBatchSize;
+// a nil share returns ErrInvalidShare. These are checked before any share
+// signs. A share that already signed a different message fails with the
+// error from its Signer, annotated with its index.
+func CreatePartialBatch(shares []*Share, messages [][32]byte) ([]*PartialSignature, error) {
+	if len(shares) == 0 || len(shares) != len(messages) {
+		return nil, ErrBatchSize
+	}
+	for j := range shares {
+		if shares[j] == nil {
+			return nil, fmt.Errorf("%w (batch share %d)", ErrInvalidShare, j)
+		}
+		for _, earlier := range shares[:j] {
+			if shares[j] == earlier || shares[j].PreimageShares == earlier.PreimageShares {
+				return nil, ErrBatchKeyReuse
+			}
+		}
+	}
+
+	partials := make([]*PartialSignature, len(messages))
+	for j, message := range messages {
+		share := shares[j]
+		partial, err := CreatePartialWithSigner(share.Signer(), share.PartyID, share.Index, message)
+		if err != nil {
+			return nil, fmt.Errorf("%w (batch share %d)", err, j)
+		}
+		partials[j] = partial
+	}
+	return partials, nil
+}
+
+// AggregateBatch aggregates a batch round: partialsPerMessage[j] holds every
+// party's partial for the j-th message (and j-th shared key). The result has
+// one signature per message, each of which must be verified against its own
+// key. The first failing message's error is returned, annotated with its index.
+func AggregateBatch(partialsPerMessage [][]*PartialSignature) ([]*primitives.Signature, error) {
+	if len(partialsPerMessage) == 0 {
+		return nil, ErrBatchSize
+	}
+
+	sigs := make([]*primitives.Signature, len(partialsPerMessage))
+	for j, partials := range partialsPerMessage {
+		sig, err := Aggregate(partials)
+		if err != nil {
+			return nil, fmt.Errorf("%w (batch message %d)", err, j)
+		}
+		sigs[j] = sig
+	}
+	return sigs, nil
+}
@@ -0,0 +1,70 @@
+package threshold
+
+import (
+	"crypto/rand"
+	"time"
+)
+
+// benchmarkChainID is the chain ID used for benchmark messages (Lux mainnet C-Chain)
+const benchmarkChainID = 96369
+
+// BenchmarkResult holds per-operation timings of a t-of-n threshold signature.
+type BenchmarkResult struct {
+	// Iterations is the number of signatures averaged
+	Iterations int
+
+	// Threshold and Parties are t and n
+	Threshold int
+	Parties   int
+
+	// Partials is the time for all t parties to create their partials,
+	// Aggregate the time to combine them, and Total the sum
+	Partials  time.Duration
+	Aggregate time.Duration
+	Total     time.Duration
+}
+
+// RunBenchmarks times t-of-n threshold signing with Shamir shares, averaging
+// over iterations signatures (minimum 1). The first t parties sign.
+func RunBenchmarks(iterations, t, n int) (BenchmarkResult, error) {
+	if iterations < 1 {
+		iterations = 1
+	}
+	res := BenchmarkResult{Iterations: iterations, Threshold: t, Parties: n}
+
+	shares, _, err := GenerateSharesShamir(t, n)
+	if err != nil {
+		return res, err
+	}
+	var moduleAddr [20]byte
+	if _, err := rand.Read(moduleAddr[:]); err != nil {
+		return res, err
+	}
+	config, err := NewValidatedConfig(t, n, "bench", benchmarkChainID, moduleAddr)
+	if err != nil {
+		return res, err
+	}
+	var safeTxHash, nextPKH [32]byte
+	msg := config.ComputeMessage(safeTxHash, nextPKH)
+
+	var partialTime, aggregateTime time.Duration
+	partials := make([]*PartialSignature, t)
+	for i := 0; i < iterations; i++ {
+		start := time.Now()
+		for j := 0; j < t; j++ {
+			partials[j] = CreatePartialSignature(shares[j], msg)
+		}
+		partialTime += time.Since(start)
+
+		start = time.Now()
+		if _, err := AggregateShamir(partials); err != nil {
+			return res, err
+		}
+		aggregateTime += time.Since(start)
+	}
+
+	res.Partials = partialTime / time.Duration(iterations)
+	res.Aggregate = aggregateTime / time.Duration(iterations)
+	res.Total = res.Partials + res.Aggregate
+	return res, nil
+}
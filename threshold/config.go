@@ -20,13 +20,20 @@ package threshold
 
 import (
 	"crypto/rand"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
 
 	"github.com/luxfi/lamport/primitives"
 )
 
 // Config holds configuration for threshold MPC Lamport signing.
+// A Config must not be copied after first use; share it by pointer.
 type Config struct {
 	// Threshold is the minimum number of parties needed to sign (t in t-of-n)
 	Threshold int
@@ -42,6 +49,25 @@ type Config struct {
 
 	// ModuleAddress for domain separation (prevents cross-contract replay)
 	ModuleAddress [20]byte
+
+	// ReplayGuard, if set, rejects signing rounds for already-completed messages
+	ReplayGuard *ReplayGuard
+
+	// domain caches the encoded domain for ModuleAddress and ChainID
+	domain atomic.Pointer[domainCache]
+}
+
+// domainCache holds values derived from (ModuleAddress, ChainID) that are
+// fixed for the lifetime of a Config.
+type domainCache struct {
+	moduleAddress [20]byte
+	chainID       uint64
+
+	// packed is abi.encodePacked(address, uint256), the message suffix
+	packed [52]byte
+
+	// separator is ComputeDomainSeparator(moduleAddress, chainID)
+	separator [32]byte
 }
 
 // Share represents a party's share of a Lamport private key.
@@ -56,6 +82,11 @@ type Share struct {
 
 	// Index is this party's index (1 to n)
 	Index int
+
+	// signed and signedMessage record the message this share revealed
+	// preimages for, so it is never used for a second one
+	signed        bool
+	signedMessage [32]byte
 }
 
 // PartialSignature is a party's contribution to the threshold signature.
@@ -93,43 +124,198 @@ var (
 
 	// ErrInvalidPartial indicates a partial signature failed verification
 	ErrInvalidPartial = errors.New("threshold: invalid partial signature")
+
+	// ErrNoCommitment indicates a partial from a party that submitted no digest commitment
+	ErrNoCommitment = errors.New("threshold: partial from party without a digest commitment")
+
+	// ErrDuplicateParty indicates a party submitted a partial conflicting with its earlier one
+	ErrDuplicateParty = errors.New("threshold: conflicting partial from the same party")
+
+	// ErrInvalidShare indicates the share format is invalid
+	ErrInvalidShare = errors.New("threshold: invalid share")
+
+	// ErrZeroModuleAddress warns that a zero module address disables cross-contract replay protection
+	ErrZeroModuleAddress = errors.New("threshold: zero module address weakens domain separation")
+
+	// ErrZeroChainID warns that a zero chain ID disables cross-chain replay protection
+	ErrZeroChainID = errors.New("threshold: zero chain ID weakens domain separation")
 )
 
+// ConfigWarning is returned by Validate for a Config that is usable but has
+// weak domain separation. Callers that deliberately use such a Config (e.g.
+// tests and benchmarks) can detect it with errors.As and proceed.
+type ConfigWarning struct {
+	// Err holds ErrZeroModuleAddress and/or ErrZeroChainID
+	Err error
+}
+
+func (w *ConfigWarning) Error() string {
+	return w.Err.Error()
+}
+
+func (w *ConfigWarning) Unwrap() error {
+	return w.Err
+}
+
+// IsConfigWarning reports whether err is a ConfigWarning rather than a hard error.
+func IsConfigWarning(err error) bool {
+	var w *ConfigWarning
+	return errors.As(err, &w)
+}
+
+// PartyError attributes a protocol error to the party that caused it.
+// errors.Is sees through it to the underlying sentinel (e.g. ErrInvalidPartial).
+type PartyError struct {
+	PartyID string
+	Err     error
+}
+
+func (e *PartyError) Error() string {
+	return fmt.Sprintf("%v (party %q)", e.Err, e.PartyID)
+}
+
+func (e *PartyError) Unwrap() error {
+	return e.Err
+}
+
+// CommitmentMismatchError reports a digest commitment that does not match
+// the coordinator's safeTxHash. It unwraps to ErrDigestMismatch.
+type CommitmentMismatchError struct {
+	PartyID  string
+	Expected [32]byte // H(safeTxHash || PartyID) for the coordinator's safeTxHash
+	Received [32]byte
+}
+
+func (e *CommitmentMismatchError) Error() string {
+	return fmt.Sprintf("%v (party %q: expected %x, received %x)", ErrDigestMismatch, e.PartyID, e.Expected, e.Received)
+}
+
+func (e *CommitmentMismatchError) Unwrap() error {
+	return ErrDigestMismatch
+}
+
 // NewConfig creates a new threshold configuration.
+// Weak domain parameters are accepted; use NewValidatedConfig to reject them.
 func NewConfig(threshold, totalParties int, partyID string, chainID uint64, moduleAddr [20]byte) (*Config, error) {
-	if threshold < 1 || threshold > totalParties {
-		return nil, ErrInvalidThreshold
-	}
-	return &Config{
+	c := &Config{
 		Threshold:     threshold,
 		TotalParties:  totalParties,
 		PartyID:       partyID,
 		ChainID:       chainID,
 		ModuleAddress: moduleAddr,
-	}, nil
+	}
+	if err := c.Validate(); err != nil && !IsConfigWarning(err) {
+		return nil, err
+	}
+	c.cacheDomain()
+	return c, nil
+}
+
+// NewValidatedConfig is NewConfig that also fails on Validate warnings
+// (zero module address or zero chain ID).
+func NewValidatedConfig(threshold, totalParties int, partyID string, chainID uint64, moduleAddr [20]byte) (*Config, error) {
+	c, err := NewConfig(threshold, totalParties, partyID, chainID, moduleAddr)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate checks the configuration.
+//
+// It returns ErrInvalidThreshold unless 1 <= Threshold <= TotalParties. If the
+// parameters are valid but ModuleAddress or ChainID is zero, it returns a
+// *ConfigWarning wrapping ErrZeroModuleAddress and/or ErrZeroChainID.
+func (c *Config) Validate() error {
+	if c.Threshold < 1 || c.Threshold > c.TotalParties {
+		return ErrInvalidThreshold
+	}
+
+	var warnings []error
+	if c.ModuleAddress == ([20]byte{}) {
+		warnings = append(warnings, ErrZeroModuleAddress)
+	}
+	if c.ChainID == 0 {
+		warnings = append(warnings, ErrZeroChainID)
+	}
+	if len(warnings) > 0 {
+		return &ConfigWarning{Err: errors.Join(warnings...)}
+	}
+	return nil
+}
+
+// domainCache returns the cached domain, computing and storing it if the
+// cache is unset (e.g. a Config built as a struct literal) or ModuleAddress
+// or ChainID changed since it was built. The cache is an atomic pointer, so
+// a Config is safe to share between goroutines; the keccak runs once per
+// domain rather than per call.
+func (c *Config) domainCache() *domainCache {
+	if d := c.domain.Load(); d != nil && d.moduleAddress == c.ModuleAddress && d.chainID == c.ChainID {
+		return d
+	}
+	d := newDomainCache(c.ModuleAddress, c.ChainID)
+	c.domain.Store(d)
+	return d
+}
+
+// cacheDomain populates the domain cache eagerly. Constructors (NewConfig,
+// UnmarshalJSON) call it so the first signing round pays nothing.
+func (c *Config) cacheDomain() {
+	c.domain.Store(newDomainCache(c.ModuleAddress, c.ChainID))
+}
+
+// newDomainCache encodes the domain for moduleAddress and chainID.
+func newDomainCache(moduleAddress [20]byte, chainID uint64) *domainCache {
+	d := &domainCache{
+		moduleAddress: moduleAddress,
+		chainID:       chainID,
+		separator:     primitives.ComputeDomainSeparator(moduleAddress, chainID),
+	}
+	copy(d.packed[:20], moduleAddress[:])
+	encoded := primitives.ChainIDFromUint64(chainID)
+	copy(d.packed[20:], encoded[:])
+	return d
+}
+
+// DomainSeparator returns the cached ComputeDomainSeparator(ModuleAddress, ChainID).
+func (c *Config) DomainSeparator() [32]byte {
+	return c.domainCache().separator
 }
 
 // ComputeMessage computes the domain-separated message for threshold signing.
 // This MUST be computed locally by each party - never accept from coordinator!
+//
+// The result is identical to primitives.ComputeThresholdMessage; the encoded
+// module address and chain ID are cached rather than re-encoded per call.
 func (c *Config) ComputeMessage(safeTxHash, nextPKH [32]byte) [32]byte {
-	return primitives.ComputeThresholdMessage(safeTxHash, nextPKH, c.ModuleAddress, c.ChainID)
+	d := c.domainCache()
+	var buf [116]byte
+	copy(buf[0:32], safeTxHash[:])
+	copy(buf[32:64], nextPKH[:])
+	copy(buf[64:116], d.packed[:])
+	return primitives.Keccak256(buf[:])
 }
 
 // CreateDigestCommitment creates a commitment to the safeTxHash.
 // This is broadcast in round 1 before any signing material is revealed.
 func (c *Config) CreateDigestCommitment(safeTxHash [32]byte) DigestCommitment {
-	// Commitment = H(safeTxHash || partyID)
-	h := primitives.Keccak256Multi(safeTxHash[:], []byte(c.PartyID))
 	return DigestCommitment{
 		PartyID:    c.PartyID,
-		Commitment: h,
+		Commitment: digestCommitment(safeTxHash, c.PartyID),
 	}
 }
 
 // VerifyDigestCommitment verifies another party's commitment matches the expected digest.
 func VerifyDigestCommitment(commitment DigestCommitment, safeTxHash [32]byte) bool {
-	expected := primitives.Keccak256Multi(safeTxHash[:], []byte(commitment.PartyID))
-	return commitment.Commitment == expected
+	return commitment.Commitment == digestCommitment(safeTxHash, commitment.PartyID)
+}
+
+// digestCommitment computes H(safeTxHash || partyID).
+func digestCommitment(safeTxHash [32]byte, partyID string) [32]byte {
+	return primitives.Keccak256Multi(safeTxHash[:], []byte(partyID))
 }
 
 // GenerateShares generates n shares of a Lamport private key for threshold signing.
@@ -140,53 +326,240 @@ func GenerateShares(n int) ([]*Share, *primitives.PublicKey, error) {
 	return GenerateSharesFromReader(n, rand.Reader)
 }
 
-// GenerateSharesFromReader generates shares using a specific random source.
+// GenerateSharesWithCommitments is GenerateShares that also returns each
+// party's public share commitment, so partials can be verified individually.
+// XORing the shares still yields preimages hashing to pub.
+func GenerateSharesWithCommitments(n int) ([]*Share, *primitives.PublicKey, []*PartyPublicShares, error) {
+	shares, pub, err := GenerateShares(n)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return shares, pub, PublicShares(shares), nil
+}
+
+// GenerateSharesFromSeed deterministically generates n shares from a 32-byte seed.
+// The same seed and n always produce identical shares and public key, which
+// makes DKG tests reproducible and allows re-deriving shares for recovery.
+// The seed is equivalent to the full private key and must be protected as such.
+func GenerateSharesFromSeed(n int, seed [32]byte) ([]*Share, *primitives.PublicKey, error) {
+	return GenerateSharesFromReader(n, primitives.NewSeededReader(seed))
+}
+
+// GenerateSharesFromReader generates shares using a specific random source,
+// such as a primitives.ReseedingReader for long-running dealers. n < 1
+// returns ErrInvalidThreshold.
 func GenerateSharesFromReader(n int, random io.Reader) ([]*Share, *primitives.PublicKey, error) {
-	shares := make([]*Share, n)
+	if n < 1 {
+		return nil, nil, ErrInvalidThreshold
+	}
+	primitives.LockHashFunc()
+	shares := newShares(n)
 	pub := &primitives.PublicKey{}
 
 	// For each bit position
 	for i := 0; i < primitives.KeyBits; i++ {
-		for bit := 0; bit < 2; bit++ {
-			// Generate n-1 random shares
-			var actualPreimage [primitives.PreimageSize]byte
-			if _, err := io.ReadFull(random, actualPreimage[:]); err != nil {
-				return nil, nil, err
-			}
+		if err := generateSharePosition(shares, pub, i, random); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return shares, pub, nil
+}
+
+// GenerateSharesParallel deterministically generates n additive shares from a
+// seed, spreading the 256 bit positions across workers (<= 0 means GOMAXPROCS).
+//
+// Position i draws from its own stream NewSeededReader(keccak256(seed ||
+// uint16(i))), so the output depends only on seed and n, never on the number
+// of workers. It differs from GenerateSharesFromSeed, which uses one stream.
+func GenerateSharesParallel(n int, seed [32]byte, workers int) ([]*Share, *primitives.PublicKey, error) {
+	if n < 1 {
+		return nil, nil, ErrInvalidThreshold
+	}
+	primitives.LockHashFunc()
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > primitives.KeyBits {
+		workers = primitives.KeyBits
+	}
+
+	shares := newShares(n)
+	pub := &primitives.PublicKey{}
 
-			// Compute public key hash
-			pub.Hashes[i][bit] = primitives.Keccak256(actualPreimage[:])
-
-			// Create shares: n-1 random, last one = preimage - sum(others)
-			var sum [primitives.PreimageSize]byte
-			for j := 0; j < n-1; j++ {
-				if shares[j] == nil {
-					shares[j] = &Share{Index: j + 1}
-				}
-				if _, err := io.ReadFull(random, shares[j].PreimageShares[i][bit][:]); err != nil {
-					return nil, nil, err
-				}
-				// Add to sum
-				for k := 0; k < primitives.PreimageSize; k++ {
-					sum[k] ^= shares[j].PreimageShares[i][bit][k]
-				}
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < primitives.KeyBits; i += workers {
+				var idx [2]byte
+				binary.BigEndian.PutUint16(idx[:], uint16(i))
+				random := primitives.NewSeededReader(primitives.Keccak256Multi(seed[:], idx[:]))
+				// Seeded readers never fail
+				_ = generateSharePosition(shares, pub, i, random)
 			}
+		}(w)
+	}
+	wg.Wait()
+
+	return shares, pub, nil
+}
+
+// newShares allocates n shares with indices 1..n.
+func newShares(n int) []*Share {
+	shares := make([]*Share, n)
+	for j := range shares {
+		shares[j] = &Share{Index: j + 1}
+	}
+	return shares
+}
 
-			// Last share = preimage XOR sum(others)
-			if shares[n-1] == nil {
-				shares[n-1] = &Share{Index: n}
+// generateSharePosition fills both sides of bit position i for every share
+// and the public key. It writes only position i, so distinct positions may
+// be generated concurrently.
+func generateSharePosition(shares []*Share, pub *primitives.PublicKey, i int, random io.Reader) error {
+	n := len(shares)
+	for bit := 0; bit < 2; bit++ {
+		// Generate n-1 random shares
+		var actualPreimage [primitives.PreimageSize]byte
+		if _, err := io.ReadFull(random, actualPreimage[:]); err != nil {
+			return err
+		}
+
+		// Compute public key hash
+		pub.Hashes[i][bit] = primitives.Keccak256(actualPreimage[:])
+
+		// Create shares: n-1 random, last one = preimage - sum(others)
+		var sum [primitives.PreimageSize]byte
+		for j := 0; j < n-1; j++ {
+			if _, err := io.ReadFull(random, shares[j].PreimageShares[i][bit][:]); err != nil {
+				return err
 			}
+			// Add to sum
 			for k := 0; k < primitives.PreimageSize; k++ {
-				shares[n-1].PreimageShares[i][bit][k] = actualPreimage[k] ^ sum[k]
+				sum[k] ^= shares[j].PreimageShares[i][bit][k]
 			}
 		}
+
+		// Last share = preimage XOR sum(others)
+		for k := 0; k < primitives.PreimageSize; k++ {
+			shares[n-1].PreimageShares[i][bit][k] = actualPreimage[k] ^ sum[k]
+		}
 	}
+	return nil
+}
 
-	return shares, pub, nil
+// Bytes serializes the share preimages to bytes.
+// Layout matches PrivateKey.Bytes: share[i][0] || share[i][1] for each i.
+// PartyID and Index are not included.
+func (s *Share) Bytes() []byte {
+	out := make([]byte, primitives.PrivateKeySize)
+	for i := 0; i < primitives.KeyBits; i++ {
+		copy(out[i*64:i*64+32], s.PreimageShares[i][0][:])
+		copy(out[i*64+32:i*64+64], s.PreimageShares[i][1][:])
+	}
+	return out
+}
+
+// FromBytes deserializes share preimages from bytes.
+func (s *Share) FromBytes(data []byte) error {
+	if len(data) != primitives.PrivateKeySize {
+		return ErrInvalidShare
+	}
+	for i := 0; i < primitives.KeyBits; i++ {
+		copy(s.PreimageShares[i][0][:], data[i*64:i*64+32])
+		copy(s.PreimageShares[i][1][:], data[i*64+32:i*64+64])
+	}
+	return nil
+}
+
+// shareGobVersion tags the GobEncode layout of a Share.
+const shareGobVersion = 1
+
+// shareGobHeader is the version byte, 8-byte Index, and 2-byte PartyID
+// length that precede the PartyID and Bytes in the GobEncode layout.
+const shareGobHeader = 1 + 8 + 2
+
+// GobEncode implements gob.GobEncoder with a flat layout:
+//
+//	version (1) || Index (int64, big-endian) || len(PartyID) (uint16, big-endian) || PartyID || Bytes()
+//
+// A PartyID longer than 65535 bytes returns ErrInvalidShare.
+func (s *Share) GobEncode() ([]byte, error) {
+	if len(s.PartyID) > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: party ID too long", ErrInvalidShare)
+	}
+	out := make([]byte, shareGobHeader, shareGobHeader+len(s.PartyID)+primitives.PrivateKeySize)
+	out[0] = shareGobVersion
+	binary.BigEndian.PutUint64(out[1:9], uint64(int64(s.Index)))
+	binary.BigEndian.PutUint16(out[9:11], uint16(len(s.PartyID)))
+	out = append(out, s.PartyID...)
+	return append(out, s.Bytes()...), nil
+}
+
+// GobDecode implements gob.GobDecoder for the GobEncode layout. Malformed
+// data or an unknown version returns ErrInvalidShare and leaves s unchanged.
+func (s *Share) GobDecode(data []byte) error {
+	if len(data) < shareGobHeader || data[0] != shareGobVersion {
+		return ErrInvalidShare
+	}
+	index := int64(binary.BigEndian.Uint64(data[1:9]))
+	if int64(int(index)) != index {
+		return ErrInvalidShare
+	}
+	idLen := int(binary.BigEndian.Uint16(data[9:11]))
+	rest := data[shareGobHeader:]
+	if len(rest) != idLen+primitives.PrivateKeySize {
+		return ErrInvalidShare
+	}
+	if err := s.FromBytes(rest[idLen:]); err != nil {
+		return err
+	}
+	s.PartyID = string(rest[:idLen])
+	s.Index = int(index)
+	return nil
+}
+
+// ReconstructPreimageAdditive is ReconstructPreimage with validation: the
+// shares must be exactly the full additive set, indices 1..n each once.
+// Duplicate or out-of-range indices return ErrInvalidShareIndex; a missing
+// share returns ErrNotEnoughParties.
+func ReconstructPreimageAdditive(shares []*Share, n, bitIndex, bitValue int) ([primitives.PreimageSize]byte, error) {
+	if err := checkPosition(bitIndex, bitValue); err != nil {
+		return [primitives.PreimageSize]byte{}, err
+	}
+	seen := make(map[int]struct{}, len(shares))
+	for _, share := range shares {
+		if share == nil {
+			return [primitives.PreimageSize]byte{}, ErrInvalidShare
+		}
+		if _, dup := seen[share.Index]; dup || share.Index < 1 || share.Index > n {
+			return [primitives.PreimageSize]byte{}, ErrInvalidShareIndex
+		}
+		seen[share.Index] = struct{}{}
+	}
+	if len(seen) != n {
+		return [primitives.PreimageSize]byte{}, ErrNotEnoughParties
+	}
+	return ReconstructPreimage(shares, bitIndex, bitValue), nil
+}
+
+// checkPosition returns ErrInvalidShare unless (bitIndex, bitValue) names a
+// preimage of the key.
+func checkPosition(bitIndex, bitValue int) error {
+	if bitIndex < 0 || bitIndex >= primitives.KeyBits || bitValue < 0 || bitValue > 1 {
+		return ErrInvalidShare
+	}
+	return nil
 }
 
 // ReconstructPreimage reconstructs a preimage from shares (for the needed bits only).
 // In the MPC protocol, this happens in the aggregation phase.
+//
+// It XORs whatever shares it is given without checking their indices, so a
+// repeated or missing share silently yields a wrong preimage. Use
+// ReconstructPreimageAdditive or ReconstructPreimageShamir for validation.
 func ReconstructPreimage(shares []*Share, bitIndex int, bitValue int) [primitives.PreimageSize]byte {
 	var result [primitives.PreimageSize]byte
 	for _, share := range shares {
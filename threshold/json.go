@@ -0,0 +1,114 @@
+package threshold
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// configJSON is the JSON wire form of a Config. ChainID is a decimal string
+// so values above 2^53 survive JavaScript clients; ModuleAddress is 0x hex.
+type configJSON struct {
+	Threshold     int    `json:"threshold"`
+	TotalParties  int    `json:"totalParties"`
+	PartyID       string `json:"partyId"`
+	ChainID       string `json:"chainId"`
+	ModuleAddress string `json:"moduleAddress"`
+}
+
+// MarshalJSON implements json.Marshaler. ReplayGuard is not serialized.
+func (c *Config) MarshalJSON() ([]byte, error) {
+	return json.Marshal(configJSON{
+		Threshold:     c.Threshold,
+		TotalParties:  c.TotalParties,
+		PartyID:       c.PartyID,
+		ChainID:       strconv.FormatUint(c.ChainID, 10),
+		ModuleAddress: "0x" + hex.EncodeToString(c.ModuleAddress[:]),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The decoded Config must pass
+// Validate; as with NewConfig, a *ConfigWarning is accepted. On error the
+// receiver is left unchanged.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	var j configJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	chainID, err := strconv.ParseUint(j.ChainID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("threshold: invalid chainId %q: %w", j.ChainID, err)
+	}
+	addr, err := parseAddress(j.ModuleAddress)
+	if err != nil {
+		return err
+	}
+
+	decoded := Config{
+		Threshold:     j.Threshold,
+		TotalParties:  j.TotalParties,
+		PartyID:       j.PartyID,
+		ChainID:       chainID,
+		ModuleAddress: addr,
+	}
+	if err := decoded.Validate(); err != nil && !IsConfigWarning(err) {
+		return err
+	}
+
+	c.Threshold = decoded.Threshold
+	c.TotalParties = decoded.TotalParties
+	c.PartyID = decoded.PartyID
+	c.ChainID = decoded.ChainID
+	c.ModuleAddress = decoded.ModuleAddress
+	c.cacheDomain()
+	return nil
+}
+
+// parseAddress decodes a 0x-prefixed 20-byte hex address.
+func parseAddress(s string) ([20]byte, error) {
+	var addr [20]byte
+	raw, ok := strings.CutPrefix(s, "0x")
+	if !ok {
+		return addr, fmt.Errorf("threshold: moduleAddress %q missing 0x prefix", s)
+	}
+	b, err := hex.DecodeString(raw)
+	if err != nil {
+		return addr, fmt.Errorf("threshold: invalid moduleAddress %q: %w", s, err)
+	}
+	if len(b) != len(addr) {
+		return addr, fmt.Errorf("threshold: moduleAddress %q is %d bytes, want 20", s, len(b))
+	}
+	copy(addr[:], b)
+	return addr, nil
+}
+
+// CoordinatorStatus is a read-only, JSON-serializable view of a signing
+// round, suitable for dashboards. It carries no signing material.
+type CoordinatorStatus struct {
+	Phase        int    `json:"phase"` // 0: commitments, 1: partials, 2: done
+	Threshold    int    `json:"threshold"`
+	TotalParties int    `json:"totalParties"`
+	Commitments  int    `json:"commitments"`
+	Partials     int    `json:"partials"`
+	Disagreeing  int    `json:"disagreeing"`
+	Message      string `json:"message"` // 0x hex of the domain-separated message
+}
+
+// Status returns a snapshot of the coordinator's progress.
+func (c *Coordinator) Status() CoordinatorStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CoordinatorStatus{
+		Phase:        c.phase,
+		Threshold:    c.config.Threshold,
+		TotalParties: c.config.TotalParties,
+		Commitments:  len(c.commitments),
+		Partials:     len(c.partials),
+		Disagreeing:  len(c.disagreeing),
+		Message:      "0x" + hex.EncodeToString(c.message[:]),
+	}
+}
@@ -11,18 +11,18 @@ import (
 // Each party reveals their share of the preimage for bits corresponding to the message:
 //   - If message bit i is 0, reveal share of preimage[i][0]
 //   - If message bit i is 1, reveal share of preimage[i][1]
+//
+// It signs through share.Signer, so a share that already signed a different
+// message is refused. A nil or refused share yields a nil partial, which
+// aggregation rejects with ErrInvalidPartial.
 func CreatePartialSignature(share *Share, message [32]byte) *PartialSignature {
-	partial := &PartialSignature{
-		PartyID: share.PartyID,
-		Index:   share.Index,
-		BitMask: message,
+	if share == nil {
+		return nil
 	}
-
-	for i := 0; i < primitives.KeyBits; i++ {
-		bit := primitives.GetBit(message, i)
-		partial.PreimagePartials[i] = share.PreimageShares[i][bit]
+	partial, err := CreatePartialWithSigner(share.Signer(), share.PartyID, share.Index, message)
+	if err != nil {
+		return nil
 	}
-
 	return partial
 }
 
@@ -38,6 +38,100 @@ func CreatePartialForThreshold(
 	return CreatePartialSignature(share, message)
 }
 
+// CreatePartialForThresholdWithSigner is CreatePartialForThreshold for a share
+// held by an external signer. The party identity comes from config.
+func CreatePartialForThresholdWithSigner(
+	config *Config,
+	signer primitives.Signer,
+	index int,
+	safeTxHash [32]byte,
+	nextPKH [32]byte,
+) (*PartialSignature, error) {
+	message := config.ComputeMessage(safeTxHash, nextPKH)
+	return CreatePartialWithSigner(signer, config.PartyID, index, message)
+}
+
+// CreatePartialWithSigner creates a partial signature using a signer that holds
+// this party's share, e.g. Share.Signer or an HSM that never exposes share
+// material.
+//
+// A share has the same shape as a Lamport private key, so signing the message
+// with it reveals exactly the share preimages selected by the message bits.
+func CreatePartialWithSigner(signer primitives.Signer, partyID string, index int, message [32]byte) (*PartialSignature, error) {
+	sig, err := signer.Sign(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PartialSignature{
+		PartyID:          partyID,
+		Index:            index,
+		PreimagePartials: sig.Preimages,
+		BitMask:          message,
+	}, nil
+}
+
+// shareSigner is the primitives.Signer over a Share's preimages. Use is
+// recorded on the Share, so every signer for a share sees the same state.
+type shareSigner struct {
+	share *Share
+}
+
+// Signer returns a primitives.Signer over this share's preimages. Its public
+// key is the keccak256 of each share preimage (not the group key).
+//
+// The share records the first message it signs. Signing that message again
+// reveals nothing new and is allowed; any other message returns
+// primitives.ErrKeyAlreadyUsed, whichever signer for the share is used. Like
+// primitives.PrivateKey, a Share is not safe for concurrent signing.
+func (s *Share) Signer() primitives.Signer {
+	return shareSigner{share: s}
+}
+
+// PublicKey returns the share's public commitment, as PublicShare.
+func (s shareSigner) PublicKey() *primitives.PublicKey {
+	return s.share.PublicShare()
+}
+
+// Sign reveals the share preimages selected by message.
+func (s shareSigner) Sign(message [32]byte) (*primitives.Signature, error) {
+	share := s.share
+	if share.signed && share.signedMessage != message {
+		return nil, primitives.ErrKeyAlreadyUsed
+	}
+	share.signed = true
+	share.signedMessage = message
+
+	sig := &primitives.Signature{}
+	bits := primitives.NewBitVector(message)
+	for i := 0; i < primitives.KeyBits; i++ {
+		sig.Preimages[i] = share.PreimageShares[i][bits[i]]
+	}
+	return sig, nil
+}
+
+// PublicShare returns this share's public commitment: keccak256 of every
+// preimage share, in PublicKey layout. It equals Signer().PublicKey(). The
+// dealer publishes one per party so partials can be checked individually.
+func (s *Share) PublicShare() *primitives.PublicKey {
+	pub := &primitives.PublicKey{}
+	for i := 0; i < primitives.KeyBits; i++ {
+		for bit := 0; bit < 2; bit++ {
+			pub.Hashes[i][bit] = primitives.Keccak256(s.PreimageShares[i][bit][:])
+		}
+	}
+	return pub
+}
+
+// VerifyPartial checks a partial's revealed preimage shares against the
+// party's public share for the partial's BitMask. Unlike
+// VerifyPartialCommitment, this detects a single party's bad material
+// without waiting for the aggregate signature to fail.
+func VerifyPartial(partial *PartialSignature, publicShare *primitives.PublicKey) bool {
+	sig := &primitives.Signature{Preimages: partial.PreimagePartials}
+	return primitives.Verify(publicShare, partial.BitMask, sig)
+}
+
 // VerifyPartialCommitment verifies a partial signature's structure.
 // This doesn't verify cryptographic correctness (that requires aggregation).
 func VerifyPartialCommitment(partial *PartialSignature, expectedMessage [32]byte) bool {
@@ -53,3 +147,59 @@ func (p *PartialSignature) RevealedBits() [32]byte {
 func (p *PartialSignature) GetPartialForBit(i int) [primitives.PreimageSize]byte {
 	return p.PreimagePartials[i]
 }
+
+// PartyPublicShares is the dealer's published commitment to one party's
+// share: keccak256 of every PreimageShares[i][bit], keyed by party.
+type PartyPublicShares struct {
+	PartyID string
+	Index   int
+
+	// Hashes holds keccak256(PreimageShares[i][bit]) in PublicKey layout
+	Hashes *primitives.PublicKey
+}
+
+// PublicShares computes the commitment for each share, in the same order.
+func PublicShares(shares []*Share) []*PartyPublicShares {
+	out := make([]*PartyPublicShares, len(shares))
+	for j, share := range shares {
+		out[j] = &PartyPublicShares{
+			PartyID: share.PartyID,
+			Index:   share.Index,
+			Hashes:  share.PublicShare(),
+		}
+	}
+	return out
+}
+
+// PublicSharesByParty keys commitments by PartyID, the form taken by
+// Coordinator.SetPublicShares, AggregateShamirVerified, and
+// AggregateBestEffort.
+func PublicSharesByParty(commitments []*PartyPublicShares) map[string]*PartyPublicShares {
+	out := make(map[string]*PartyPublicShares, len(commitments))
+	for _, c := range commitments {
+		out[c.PartyID] = c
+	}
+	return out
+}
+
+// VerifyPartial checks that partial comes from this party (matching Index)
+// and that its revealed preimage shares match the committed hashes.
+func (p *PartyPublicShares) VerifyPartial(partial *PartialSignature) bool {
+	return partial.Index == p.Index && VerifyPartial(partial, p.Hashes)
+}
+
+// Zero overwrites the share's preimage shares. Call it once the share is no
+// longer needed so the material does not linger in memory.
+func (s *Share) Zero() {
+	s.PreimageShares = [primitives.KeyBits][2][primitives.PreimageSize]byte{}
+}
+
+// Zero overwrites the partial's revealed preimage shares.
+//
+// SECURITY: a partial reveals this party's shares for half of the key's
+// preimages; together with other parties' partials (or one partial for a
+// different message) it erodes the one-time key. Discard partials promptly
+// once the signature is aggregated.
+func (p *PartialSignature) Zero() {
+	p.PreimagePartials = [primitives.KeyBits][primitives.PreimageSize]byte{}
+}
@@ -0,0 +1,75 @@
+package threshold
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+)
+
+// DefaultReplayGuardSize is the default number of completed messages remembered
+const DefaultReplayGuardSize = 4096
+
+// ErrReplay indicates a signing round for an already-completed message
+var ErrReplay = errors.New("threshold: message already signed (replay)")
+
+// ReplayGuard remembers recently completed threshold messages so the same
+// (safeTxHash, nextPKH) cannot drive a second signing round. It keeps the
+// most recent entries up to a fixed capacity (LRU) and is safe for
+// concurrent use.
+//
+// Set Config.ReplayGuard to have AggregateThreshold and Coordinator consult it.
+type ReplayGuard struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[[32]byte]*list.Element
+}
+
+// NewReplayGuard creates a guard remembering up to capacity messages.
+// A non-positive capacity uses DefaultReplayGuardSize.
+func NewReplayGuard(capacity int) *ReplayGuard {
+	if capacity <= 0 {
+		capacity = DefaultReplayGuardSize
+	}
+	return &ReplayGuard{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[[32]byte]*list.Element, capacity),
+	}
+}
+
+// Check records message and returns ErrReplay if it was already recorded.
+func (g *ReplayGuard) Check(message [32]byte) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if e, ok := g.entries[message]; ok {
+		g.order.MoveToFront(e)
+		return ErrReplay
+	}
+
+	g.entries[message] = g.order.PushFront(message)
+	if g.order.Len() > g.capacity {
+		oldest := g.order.Back()
+		g.order.Remove(oldest)
+		delete(g.entries, oldest.Value.([32]byte))
+	}
+	return nil
+}
+
+// Seen reports whether message is recorded, without recording it.
+func (g *ReplayGuard) Seen(message [32]byte) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	_, ok := g.entries[message]
+	return ok
+}
+
+// Len returns the number of remembered messages.
+func (g *ReplayGuard) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.order.Len()
+}
@@ -0,0 +1,242 @@
+package threshold
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/luxfi/lamport/primitives"
+)
+
+// DefaultMaxSessions is the default number of concurrent sessions a
+// SessionManager holds
+const DefaultMaxSessions = 256
+
+var (
+	// ErrSessionExists indicates a session ID, safeTxHash, or message that
+	// is already in use by an active session
+	ErrSessionExists = errors.New("threshold: session already exists")
+
+	// ErrTooManySessions indicates the manager is at capacity
+	ErrTooManySessions = errors.New("threshold: too many active sessions")
+
+	// ErrUnknownSession indicates a commitment or partial matching no active session
+	ErrUnknownSession = errors.New("threshold: no active session for message")
+
+	// ErrResultsFull indicates a completed session whose result could not
+	// be sent on SessionManager.Results because its buffer was full
+	ErrResultsFull = errors.New("threshold: results channel full")
+)
+
+// SessionResult is a completed signing session, delivered on
+// SessionManager.Results.
+type SessionResult struct {
+	SessionID string
+	Message   [32]byte
+	Signature *primitives.Signature
+}
+
+// SessionManager runs many concurrent signing rounds, one Coordinator per
+// session. Commitments are routed by safeTxHash and partials by message
+// (PartialSignature.BitMask), so parties need not know session IDs.
+//
+// It is safe for concurrent use. Memory is bounded: at most maxSessions
+// sessions are active and a session is removed when it completes. Sessions
+// expire timeout after creation; expiry is checked on access, so an expired
+// session is never routed to, NewSession and Len sweep every expired
+// session, and Expire sweeps on demand.
+type SessionManager struct {
+	mu sync.Mutex
+
+	config      *Config
+	timeout     time.Duration
+	maxSessions int
+
+	sessions  map[string]*session
+	byTxHash  map[[32]byte]*session
+	byMessage map[[32]byte]*session
+	results   chan SessionResult
+
+	// now is the clock (replaceable in tests)
+	now func() time.Time
+}
+
+type session struct {
+	id         string
+	safeTxHash [32]byte
+	message    [32]byte
+	created    time.Time
+	coord      *Coordinator
+}
+
+// NewSessionManager creates a manager whose sessions share config and
+// expire timeout after creation. A non-positive maxSessions uses
+// DefaultMaxSessions.
+func NewSessionManager(config *Config, timeout time.Duration, maxSessions int) *SessionManager {
+	if maxSessions <= 0 {
+		maxSessions = DefaultMaxSessions
+	}
+	return &SessionManager{
+		config:      config,
+		timeout:     timeout,
+		maxSessions: maxSessions,
+		sessions:    make(map[string]*session),
+		byTxHash:    make(map[[32]byte]*session),
+		byMessage:   make(map[[32]byte]*session),
+		results:     make(chan SessionResult, maxSessions),
+		now:         time.Now,
+	}
+}
+
+// Results returns the channel completed sessions are delivered on. It is
+// buffered to maxSessions; once that many results are pending, AddPartial
+// returns ErrResultsFull for further completions instead of blocking. The
+// signature is still returned by AddPartial either way.
+func (m *SessionManager) Results() <-chan SessionResult {
+	return m.results
+}
+
+// NewSession starts a signing round for (safeTxHash, nextPKH) under the
+// one-time key pub. Expired sessions are removed first. It returns
+// ErrSessionExists if id, safeTxHash, or the derived message is already
+// active, and ErrTooManySessions if the manager is full.
+func (m *SessionManager) NewSession(id string, pub *primitives.PublicKey, safeTxHash, nextPKH [32]byte) (*Coordinator, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expire()
+	coord := NewCoordinator(m.config, pub, safeTxHash, nextPKH)
+	message := coord.Message()
+	if _, ok := m.sessions[id]; ok {
+		return nil, ErrSessionExists
+	}
+	if _, ok := m.byTxHash[safeTxHash]; ok {
+		return nil, ErrSessionExists
+	}
+	if _, ok := m.byMessage[message]; ok {
+		return nil, ErrSessionExists
+	}
+	if len(m.sessions) >= m.maxSessions {
+		return nil, ErrTooManySessions
+	}
+
+	s := &session{
+		id:         id,
+		safeTxHash: safeTxHash,
+		message:    message,
+		created:    m.now(),
+		coord:      coord,
+	}
+	m.sessions[id] = s
+	m.byTxHash[safeTxHash] = s
+	m.byMessage[message] = s
+	return coord, nil
+}
+
+// AddCommitment routes a digest commitment for safeTxHash to its session
+// (see Coordinator.AddCommitment). It returns ErrUnknownSession if no
+// active, unexpired session signs safeTxHash.
+func (m *SessionManager) AddCommitment(commitment DigestCommitment, safeTxHash [32]byte) (bool, error) {
+	m.mu.Lock()
+	s, ok := m.live(m.byTxHash[safeTxHash])
+	m.mu.Unlock()
+	if !ok {
+		return false, ErrUnknownSession
+	}
+	return s.coord.AddCommitment(commitment, safeTxHash)
+}
+
+// AddPartial routes a partial to the session for its message (see
+// Coordinator.AddPartial). When the partial completes the session, the
+// session is removed, its result is sent on Results, and the signature is
+// also returned; if Results is full the signature is returned with
+// ErrResultsFull. It returns ErrUnknownSession if no active, unexpired
+// session signs the partial's message.
+func (m *SessionManager) AddPartial(partial *PartialSignature) (*primitives.Signature, error) {
+	if partial == nil {
+		return nil, ErrInvalidPartial
+	}
+	m.mu.Lock()
+	s, ok := m.live(m.byMessage[partial.BitMask])
+	m.mu.Unlock()
+	if !ok {
+		return nil, ErrUnknownSession
+	}
+
+	sig, err := s.coord.AddPartial(partial)
+	if err != nil || sig == nil {
+		return sig, err
+	}
+
+	m.mu.Lock()
+	m.remove(s)
+	m.mu.Unlock()
+
+	result := *sig
+	select {
+	case m.results <- SessionResult{SessionID: s.id, Message: s.message, Signature: &result}:
+	default:
+		return sig, ErrResultsFull
+	}
+	return sig, nil
+}
+
+// Expire removes sessions created more than the timeout ago and returns
+// their IDs. A non-positive timeout never expires sessions.
+func (m *SessionManager) Expire() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.expire()
+}
+
+func (m *SessionManager) expire() []string {
+	if m.timeout <= 0 {
+		return nil
+	}
+	var expired []string
+	now := m.now()
+	for id, s := range m.sessions {
+		if m.expired(s, now) {
+			m.remove(s)
+			expired = append(expired, id)
+		}
+	}
+	return expired
+}
+
+func (m *SessionManager) expired(s *session, now time.Time) bool {
+	return m.timeout > 0 && now.Sub(s.created) > m.timeout
+}
+
+// live returns s and true if it is an active session, removing it if it
+// has expired. m.mu must be held.
+func (m *SessionManager) live(s *session) (*session, bool) {
+	if s == nil {
+		return nil, false
+	}
+	if m.expired(s, m.now()) {
+		m.remove(s)
+		return nil, false
+	}
+	return s, true
+}
+
+// remove drops s from every index. It is a no-op if s was already removed.
+func (m *SessionManager) remove(s *session) {
+	if m.sessions[s.id] != s {
+		return
+	}
+	delete(m.sessions, s.id)
+	delete(m.byTxHash, s.safeTxHash)
+	delete(m.byMessage, s.message)
+}
+
+// Len returns the number of active sessions, after removing expired ones.
+func (m *SessionManager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expire()
+	return len(m.sessions)
+}
@@ -0,0 +1,182 @@
+package threshold
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/luxfi/lamport/primitives"
+)
+
+// MaxShamirParties is the largest n for Shamir sharing (x-coordinates 1..255 in GF(2^8))
+const MaxShamirParties = 255
+
+// ErrInvalidShareIndex indicates a Shamir share index is outside 1..255 or repeated
+var ErrInvalidShareIndex = errors.New("threshold: invalid or duplicate share index")
+
+// GenerateSharesShamir generates n Shamir shares of a Lamport private key such
+// that any t of them reconstruct every preimage.
+//
+// Each preimage byte is split independently with a random degree t-1
+// polynomial over GF(2^8); share j holds the polynomial evaluated at x = j.
+// Unlike additive sharing, only t of the n parties need to be online to sign.
+func GenerateSharesShamir(t, n int) ([]*Share, *primitives.PublicKey, error) {
+	return GenerateSharesShamirFromReader(t, n, rand.Reader)
+}
+
+// GenerateSharesShamirWithCommitments is GenerateSharesShamir that also
+// returns each party's public share commitment.
+func GenerateSharesShamirWithCommitments(t, n int) ([]*Share, *primitives.PublicKey, []*PartyPublicShares, error) {
+	shares, pub, err := GenerateSharesShamir(t, n)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return shares, pub, PublicShares(shares), nil
+}
+
+// GenerateSharesShamirFromReader generates Shamir shares using a specific random source.
+func GenerateSharesShamirFromReader(t, n int, random io.Reader) ([]*Share, *primitives.PublicKey, error) {
+	if t < 1 || t > n || n > MaxShamirParties {
+		return nil, nil, ErrInvalidThreshold
+	}
+	primitives.LockHashFunc()
+
+	shares := make([]*Share, n)
+	for j := range shares {
+		shares[j] = &Share{Index: j + 1}
+	}
+	pub := &primitives.PublicKey{}
+
+	// coeffs[c] holds coefficient c (1..t-1) for every byte of one preimage
+	coeffs := make([][primitives.PreimageSize]byte, t)
+
+	for i := 0; i < primitives.KeyBits; i++ {
+		for bit := 0; bit < 2; bit++ {
+			// Coefficient 0 is the secret preimage
+			for c := range coeffs {
+				if _, err := io.ReadFull(random, coeffs[c][:]); err != nil {
+					return nil, nil, err
+				}
+			}
+			pub.Hashes[i][bit] = primitives.Keccak256(coeffs[0][:])
+
+			for _, share := range shares {
+				x := byte(share.Index)
+				for k := 0; k < primitives.PreimageSize; k++ {
+					// Horner evaluation from the highest coefficient down
+					var y byte
+					for c := t - 1; c >= 0; c-- {
+						y = gfMul(y, x) ^ coeffs[c][k]
+					}
+					share.PreimageShares[i][bit][k] = y
+				}
+			}
+		}
+	}
+
+	return shares, pub, nil
+}
+
+// ReconstructPreimageShamir reconstructs a preimage from at least t Shamir
+// shares with distinct indices by Lagrange interpolation at x = 0. Fewer
+// than t shares return ErrNotEnoughParties; duplicate or out-of-range
+// indices return ErrInvalidShareIndex.
+func ReconstructPreimageShamir(shares []*Share, t, bitIndex, bitValue int) ([primitives.PreimageSize]byte, error) {
+	var result [primitives.PreimageSize]byte
+	if err := checkPosition(bitIndex, bitValue); err != nil {
+		return result, err
+	}
+	if t < 1 {
+		return result, ErrInvalidThreshold
+	}
+	if slices.Contains(shares, nil) {
+		return result, ErrInvalidShare
+	}
+	if len(shares) < t {
+		return result, ErrNotEnoughParties
+	}
+
+	indices := make([]int, len(shares))
+	for j, share := range shares {
+		indices[j] = share.Index
+	}
+	coeffs, err := lagrangeAtZero(indices)
+	if err != nil {
+		return result, err
+	}
+	for j, share := range shares {
+		for k := 0; k < primitives.PreimageSize; k++ {
+			result[k] ^= gfMul(coeffs[j], share.PreimageShares[bitIndex][bitValue][k])
+		}
+	}
+	return result, nil
+}
+
+// lagrangeAtZero returns the Lagrange basis coefficients at x = 0 for the
+// given x-coordinates, or ErrInvalidShareIndex if any index is out of range
+// or repeated.
+func lagrangeAtZero(indices []int) ([]byte, error) {
+	seen := make(map[int]struct{}, len(indices))
+	for _, idx := range indices {
+		if idx < 1 || idx > MaxShamirParties {
+			return nil, ErrInvalidShareIndex
+		}
+		if _, dup := seen[idx]; dup {
+			return nil, ErrInvalidShareIndex
+		}
+		seen[idx] = struct{}{}
+	}
+
+	coeffs := make([]byte, len(indices))
+	for j, xj := range indices {
+		num, den := byte(1), byte(1)
+		for m, xm := range indices {
+			if m == j {
+				continue
+			}
+			// In GF(2^8), subtraction is XOR: (0 - xm) / (xj - xm) = xm / (xj ^ xm)
+			num = gfMul(num, byte(xm))
+			den = gfMul(den, byte(xj)^byte(xm))
+		}
+		coeffs[j] = gfDiv(num, den)
+	}
+	return coeffs, nil
+}
+
+// GF(2^8) arithmetic with the AES polynomial x^8 + x^4 + x^3 + x + 1.
+// Table-based; lookups are indexed by share bytes and are not constant time.
+var gfExp, gfLog = func() ([512]byte, [256]byte) {
+	var exp [512]byte
+	var log [256]byte
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		log[x] = byte(i)
+		// Multiply by the generator 3
+		hi := x & 0x80
+		x2 := x << 1
+		if hi != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+	for i := 255; i < 512; i++ {
+		exp[i] = exp[i-255]
+	}
+	return exp, log
+}()
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
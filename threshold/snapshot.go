@@ -0,0 +1,112 @@
+package threshold
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+
+	"github.com/luxfi/lamport/primitives"
+)
+
+// ErrInvalidSnapshot indicates coordinator snapshot data is malformed
+var ErrInvalidSnapshot = errors.New("threshold: invalid coordinator snapshot")
+
+// coordinatorSnapshot is the gob wire form of a Coordinator.
+type coordinatorSnapshot struct {
+	Threshold     int
+	TotalParties  int
+	PartyID       string
+	ChainID       uint64
+	ModuleAddress [20]byte
+
+	PublicKey   []byte
+	Message     [32]byte
+	Commitments []DigestCommitment
+	Disagreeing []string
+	Partials    []*PartialSignature
+	Phase       int
+	Timings     PhaseTimings
+	Mode        AggregationMode
+
+	PublicShares map[string]*PartyPublicShares
+}
+
+// Snapshot serializes the coordinator's round state (config, public key,
+// message, commitments, partials, public shares, phase, timings, and
+// aggregation mode) so
+// another process can resume it with RestoreCoordinator. The config's
+// ReplayGuard is process-local and is not included.
+func (c *Coordinator) Snapshot() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(coordinatorSnapshot{
+		Threshold:     c.config.Threshold,
+		TotalParties:  c.config.TotalParties,
+		PartyID:       c.config.PartyID,
+		ChainID:       c.config.ChainID,
+		ModuleAddress: c.config.ModuleAddress,
+		PublicKey:     c.pub.Bytes(),
+		Message:       c.message,
+		Commitments:   c.commitments,
+		Disagreeing:   c.disagreeing,
+		Partials:      c.partials,
+		Phase:         c.phase,
+		Timings:       c.timings,
+		Mode:          c.mode,
+		PublicShares:  c.publicShares,
+	})
+	return buf.Bytes(), err
+}
+
+// RestoreCoordinator recreates a coordinator from Snapshot output. The
+// restored coordinator continues the round where the snapshot left off.
+// Set Config().ReplayGuard again if the original used one.
+func RestoreCoordinator(data []byte) (*Coordinator, error) {
+	var snap coordinatorSnapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snap); err != nil {
+		return nil, ErrInvalidSnapshot
+	}
+	if snap.Phase < 0 || snap.Phase > 2 || (snap.Mode != ModeAdditive && snap.Mode != ModeShamir) {
+		return nil, ErrInvalidSnapshot
+	}
+
+	config, err := NewConfig(snap.Threshold, snap.TotalParties, snap.PartyID, snap.ChainID, snap.ModuleAddress)
+	if err != nil {
+		return nil, err
+	}
+	pub := &primitives.PublicKey{}
+	if err := pub.FromBytes(snap.PublicKey); err != nil {
+		return nil, ErrInvalidSnapshot
+	}
+	for _, p := range snap.Partials {
+		if p == nil {
+			return nil, ErrInvalidSnapshot
+		}
+	}
+	for _, shares := range snap.PublicShares {
+		if shares == nil || shares.Hashes == nil {
+			return nil, ErrInvalidSnapshot
+		}
+	}
+
+	return &Coordinator{
+		config:      config,
+		pub:         pub,
+		message:     snap.Message,
+		commitments: snap.Commitments,
+		disagreeing: snap.Disagreeing,
+		partials:    snap.Partials,
+		phase:       snap.Phase,
+		timings:     snap.Timings,
+		mode:        snap.Mode,
+
+		publicShares: snap.PublicShares,
+	}, nil
+}
+
+// Config returns the coordinator's configuration.
+func (c *Coordinator) Config() *Config {
+	return c.config
+}
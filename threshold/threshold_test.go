@@ -0,0 +1,1649 @@
+package threshold
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/lamport/primitives"
+)
+
+func testModuleAddress() [20]byte {
+	var addr [20]byte
+	for i := range addr {
+		addr[i] = byte(i + 64)
+	}
+	return addr
+}
+
+func TestConfigComputeMessage(t *testing.T) {
+	config, err := NewConfig(2, 3, "party-0", 96369, testModuleAddress())
+	if err != nil {
+		t.Fatalf("NewConfig failed: %v", err)
+	}
+
+	var safeTxHash, nextPKH [32]byte
+	for i := range safeTxHash {
+		safeTxHash[i] = byte(i)
+		nextPKH[i] = byte(i + 32)
+	}
+
+	want := primitives.ComputeThresholdMessage(safeTxHash, nextPKH, config.ModuleAddress, config.ChainID)
+	if got := config.ComputeMessage(safeTxHash, nextPKH); got != want {
+		t.Error("Cached ComputeMessage should match ComputeThresholdMessage")
+	}
+	if config.DomainSeparator() != primitives.ComputeDomainSeparator(config.ModuleAddress, config.ChainID) {
+		t.Error("Cached DomainSeparator should match ComputeDomainSeparator")
+	}
+
+	// Mutating the domain fields invalidates the cache
+	config.ChainID = 1
+	want = primitives.ComputeThresholdMessage(safeTxHash, nextPKH, config.ModuleAddress, 1)
+	if got := config.ComputeMessage(safeTxHash, nextPKH); got != want {
+		t.Error("ComputeMessage should follow a changed ChainID")
+	}
+
+	// Configs built without NewConfig still work
+	literal := &Config{Threshold: 1, TotalParties: 1, ChainID: 7}
+	want = primitives.ComputeThresholdMessage(safeTxHash, nextPKH, [20]byte{}, 7)
+	if got := literal.ComputeMessage(safeTxHash, nextPKH); got != want {
+		t.Error("ComputeMessage should work on a Config literal")
+	}
+
+	// A literal caches its domain on first use and can be shared (run with
+	// -race)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := literal.ComputeMessage(safeTxHash, nextPKH); got != want {
+				t.Error("Concurrent ComputeMessage mismatch")
+			}
+		}()
+	}
+	wg.Wait()
+	if d := literal.domain.Load(); d == nil || d.chainID != 7 {
+		t.Error("ComputeMessage should cache the domain of a Config literal")
+	}
+}
+
+func BenchmarkComputeMessage(b *testing.B) {
+	config, _ := NewConfig(2, 3, "bench", 96369, testModuleAddress())
+	var safeTxHash, nextPKH [32]byte
+
+	b.Run("Cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = config.ComputeMessage(safeTxHash, nextPKH)
+		}
+	})
+	b.Run("Uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = primitives.ComputeThresholdMessage(safeTxHash, nextPKH, config.ModuleAddress, config.ChainID)
+		}
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	module := testModuleAddress()
+
+	// Invalid thresholds are hard errors
+	for _, p := range [][2]int{{0, 3}, {4, 3}, {1, 0}} {
+		if _, err := NewConfig(p[0], p[1], "p", 1, module); err != ErrInvalidThreshold {
+			t.Errorf("NewConfig(%d, %d): expected ErrInvalidThreshold, got %v", p[0], p[1], err)
+		}
+	}
+
+	// Valid config
+	config, err := NewValidatedConfig(2, 3, "p", 96369, module)
+	if err != nil {
+		t.Fatalf("NewValidatedConfig failed: %v", err)
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+
+	// Zero module address is a warning
+	err = (&Config{Threshold: 1, TotalParties: 1, ChainID: 1}).Validate()
+	if !IsConfigWarning(err) || !errors.Is(err, ErrZeroModuleAddress) || errors.Is(err, ErrZeroChainID) {
+		t.Errorf("Expected ErrZeroModuleAddress warning, got %v", err)
+	}
+
+	// Zero chain ID is a warning
+	err = (&Config{Threshold: 1, TotalParties: 1, ModuleAddress: module}).Validate()
+	if !IsConfigWarning(err) || !errors.Is(err, ErrZeroChainID) || errors.Is(err, ErrZeroModuleAddress) {
+		t.Errorf("Expected ErrZeroChainID warning, got %v", err)
+	}
+
+	// Both at once
+	err = (&Config{Threshold: 1, TotalParties: 1}).Validate()
+	if !errors.Is(err, ErrZeroChainID) || !errors.Is(err, ErrZeroModuleAddress) {
+		t.Errorf("Expected both warnings, got %v", err)
+	}
+
+	// NewConfig accepts warnings, NewValidatedConfig rejects them
+	if _, err := NewConfig(1, 1, "p", 0, [20]byte{}); err != nil {
+		t.Errorf("NewConfig should accept weak domain, got %v", err)
+	}
+	if _, err := NewValidatedConfig(1, 1, "p", 0, module); !errors.Is(err, ErrZeroChainID) {
+		t.Errorf("NewValidatedConfig should reject zero chain ID, got %v", err)
+	}
+}
+
+func TestGenerateSharesFromSeed(t *testing.T) {
+	seed := primitives.Keccak256([]byte("dkg seed"))
+
+	shares1, pub1, err := GenerateSharesFromSeed(3, seed)
+	if err != nil {
+		t.Fatalf("GenerateSharesFromSeed failed: %v", err)
+	}
+	shares2, pub2, _ := GenerateSharesFromSeed(3, seed)
+
+	if pub1.Hash() != pub2.Hash() {
+		t.Error("Same seed should produce the same public key")
+	}
+	for i := range shares1 {
+		if !bytes.Equal(shares1[i].Bytes(), shares2[i].Bytes()) {
+			t.Errorf("Share %d differs between calls with the same seed", i)
+		}
+	}
+
+	other := seed
+	other[0] ^= 1
+	_, pub3, _ := GenerateSharesFromSeed(3, other)
+	if pub3.Hash() == pub1.Hash() {
+		t.Error("Different seeds should produce different public keys")
+	}
+
+	// Seeded shares still aggregate to a valid signature
+	message := primitives.Keccak256([]byte("seeded"))
+	partials := make([]*PartialSignature, len(shares1))
+	for i, share := range shares1 {
+		partials[i] = CreatePartialSignature(share, message)
+	}
+	if _, err := AggregateAndVerify(partials, pub1, message); err != nil {
+		t.Errorf("Seeded shares should aggregate to a valid signature: %v", err)
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	message := primitives.Keccak256([]byte("aggregator"))
+
+	// Additive: all n partials required
+	shares, pub, err := GenerateShares(3)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	agg := NewAggregator(ModeAdditive, 3)
+	for i, share := range shares {
+		if _, err := agg.Finalize(); err != ErrNotEnoughParties {
+			t.Errorf("Expected ErrNotEnoughParties with %d partials, got %v", i, err)
+		}
+		if err := agg.Add(CreatePartialSignature(share, message)); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		if agg.Count() != i+1 {
+			t.Errorf("Expected count %d, got %d", i+1, agg.Count())
+		}
+	}
+	sig, err := agg.Finalize()
+	if err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+	if !primitives.Verify(pub, message, sig) {
+		t.Error("Additive aggregate should verify")
+	}
+
+	// Duplicate index and mismatched mask are rejected
+	if err := agg.Add(CreatePartialSignature(shares[0], message)); err != ErrDuplicatePartial {
+		t.Errorf("Expected ErrDuplicatePartial, got %v", err)
+	}
+	other := primitives.Keccak256([]byte("other"))
+	stray := CreatePartialSignature(&Share{Index: 99}, other)
+	if err := agg.Add(stray); err != ErrDigestMismatch {
+		t.Errorf("Expected ErrDigestMismatch, got %v", err)
+	}
+	if agg.Count() != 3 {
+		t.Errorf("Rejected partials should not be counted, got %d", agg.Count())
+	}
+
+	// Shamir: any t of n
+	shamirShares, shamirPub, err := GenerateSharesShamir(3, 5)
+	if err != nil {
+		t.Fatalf("GenerateSharesShamir failed: %v", err)
+	}
+	agg = NewAggregator(ModeShamir, 3)
+	for _, j := range []int{4, 1, 2} {
+		if err := agg.Add(CreatePartialSignature(shamirShares[j], message)); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	sig, err = agg.Finalize()
+	if err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+	if !primitives.Verify(shamirPub, message, sig) {
+		t.Error("Shamir aggregate of 3-of-5 should verify")
+	}
+
+	// Two Shamir partials are not enough
+	partials := []*PartialSignature{
+		CreatePartialSignature(shamirShares[0], message),
+		CreatePartialSignature(shamirShares[1], message),
+	}
+	if sig, _ := AggregateShamir(partials); primitives.Verify(shamirPub, message, sig) {
+		t.Error("Shamir aggregate below threshold should not verify")
+	}
+}
+
+func TestAggregateShamirVerified(t *testing.T) {
+	shares, pub, err := GenerateSharesShamir(3, 5)
+	if err != nil {
+		t.Fatalf("GenerateSharesShamir failed: %v", err)
+	}
+	for j, share := range shares {
+		share.PartyID = fmt.Sprintf("party-%d", j)
+	}
+	publicShares := PublicSharesByParty(PublicShares(shares))
+	message := primitives.Keccak256([]byte("surplus"))
+
+	// t+2 partials, the first of which is corrupted
+	partials := make([]*PartialSignature, len(shares))
+	for j, share := range shares {
+		partials[j] = CreatePartialSignature(share, message)
+	}
+	partials[0].PreimagePartials[7][0] ^= 0xff
+
+	sig, err := AggregateShamirVerified(3, message, partials, publicShares)
+	if err != nil {
+		t.Fatalf("AggregateShamirVerified failed: %v", err)
+	}
+	if !primitives.Verify(pub, message, sig) {
+		t.Error("Aggregate of valid partials should verify despite a bad one")
+	}
+
+	// Naive interpolation over all partials is poisoned by the bad one
+	if sig, _ := AggregateShamir(partials); primitives.Verify(pub, message, sig) {
+		t.Error("Unfiltered aggregate including a bad partial should not verify")
+	}
+
+	// Only two valid partials left after filtering
+	_, err = AggregateShamirVerified(3, message, partials[:3], publicShares)
+	if err != ErrNotEnoughParties {
+		t.Errorf("Expected ErrNotEnoughParties, got %v", err)
+	}
+
+	// Partials for another message and unknown indices are ignored
+	// A misbehaving party bypasses its share's use tracking with a copy
+	reused := &Share{PartyID: shares[1].PartyID, Index: shares[1].Index, PreimageShares: shares[1].PreimageShares}
+	stray := CreatePartialSignature(reused, primitives.Keccak256([]byte("other")))
+	unknown := CreatePartialSignature(&Share{PartyID: "mallory", Index: 42}, message)
+	_, err = AggregateShamirVerified(3, message, []*PartialSignature{stray, unknown, partials[2], partials[3]}, publicShares)
+	if err != ErrNotEnoughParties {
+		t.Errorf("Expected ErrNotEnoughParties, got %v", err)
+	}
+}
+
+func TestReplayGuard(t *testing.T) {
+	shares, pub, err := GenerateShares(2)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	config, _ := NewConfig(2, 2, "coordinator", 96369, testModuleAddress())
+	config.ReplayGuard = NewReplayGuard(8)
+
+	var safeTxHash, nextPKH [32]byte
+	safeTxHash[0] = 1
+
+	runRound := func() error {
+		c := NewCoordinator(config, pub, safeTxHash, nextPKH)
+		for i := range shares {
+			shares[i].PartyID = string(rune('a' + i))
+			partyConfig, _ := NewConfig(2, 2, shares[i].PartyID, 96369, testModuleAddress())
+			if _, err := c.AddCommitment(partyConfig.CreateDigestCommitment(safeTxHash), safeTxHash); err != nil {
+				return err
+			}
+		}
+		for _, share := range shares {
+			if _, err := c.AddPartial(CreatePartialSignature(share, c.Message())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := runRound(); err != nil {
+		t.Fatalf("First round failed: %v", err)
+	}
+	if err := runRound(); err != ErrReplay {
+		t.Errorf("Expected ErrReplay for replayed round, got %v", err)
+	}
+
+	// AggregateThreshold consults the same guard
+	message := config.ComputeMessage(safeTxHash, nextPKH)
+	partials := []*PartialSignature{
+		CreatePartialSignature(shares[0], message),
+		CreatePartialSignature(shares[1], message),
+	}
+	if _, err := AggregateThreshold(config, partials, pub, safeTxHash, nextPKH); err != ErrReplay {
+		t.Errorf("Expected ErrReplay from AggregateThreshold, got %v", err)
+	}
+
+	// Bounded: the oldest entry is evicted
+	guard := NewReplayGuard(2)
+	for i := byte(0); i < 3; i++ {
+		if err := guard.Check([32]byte{i}); err != nil {
+			t.Fatalf("Check(%d) failed: %v", i, err)
+		}
+	}
+	if guard.Len() != 2 || guard.Seen([32]byte{0}) || !guard.Seen([32]byte{2}) {
+		t.Error("ReplayGuard should evict the least recently used message")
+	}
+}
+
+func TestCoordinatorRequiresCommitment(t *testing.T) {
+	shares, pub, err := GenerateShares(3)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	config, _ := NewConfig(2, 3, "coordinator", 96369, testModuleAddress())
+
+	var safeTxHash, nextPKH [32]byte
+	safeTxHash[0] = 2
+	c := NewCoordinator(config, pub, safeTxHash, nextPKH)
+
+	// Parties a and b commit; c never does
+	for i := range shares {
+		shares[i].PartyID = string(rune('a' + i))
+	}
+	for _, share := range shares[:2] {
+		partyConfig, _ := NewConfig(2, 3, share.PartyID, 96369, testModuleAddress())
+		if _, err := c.AddCommitment(partyConfig.CreateDigestCommitment(safeTxHash), safeTxHash); err != nil {
+			t.Fatalf("AddCommitment failed: %v", err)
+		}
+	}
+
+	if _, err := c.AddPartial(nil); err != ErrInvalidPartial {
+		t.Errorf("AddPartial(nil): expected ErrInvalidPartial, got %v", err)
+	}
+
+	// Uncommitted party is rejected
+	if _, err := c.AddPartial(CreatePartialSignature(shares[2], c.Message())); err != ErrNoCommitment {
+		t.Errorf("Expected ErrNoCommitment, got %v", err)
+	}
+
+	// Committed parties are accepted
+	if _, err := c.AddPartial(CreatePartialSignature(shares[0], c.Message())); err != nil {
+		t.Errorf("AddPartial from committed party failed: %v", err)
+	}
+}
+
+func TestCoordinatorConcurrent(t *testing.T) {
+	const n = 16
+	shares, pub, err := GenerateShares(n)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	config, _ := NewConfig(n, n, "coordinator", 96369, testModuleAddress())
+
+	var safeTxHash, nextPKH [32]byte
+	safeTxHash[0] = 3
+	c := NewCoordinator(config, pub, safeTxHash, nextPKH)
+
+	var wg sync.WaitGroup
+	for i := range shares {
+		shares[i].PartyID = fmt.Sprintf("party-%d", i)
+		wg.Add(1)
+		go func(share *Share) {
+			defer wg.Done()
+			partyConfig, _ := NewConfig(n, n, share.PartyID, 96369, testModuleAddress())
+			if _, err := c.AddCommitment(partyConfig.CreateDigestCommitment(safeTxHash), safeTxHash); err != nil {
+				t.Errorf("AddCommitment failed: %v", err)
+			}
+		}(shares[i])
+	}
+	wg.Wait()
+
+	sigs := make(chan *primitives.Signature, n)
+	for _, share := range shares {
+		wg.Add(1)
+		go func(share *Share) {
+			defer wg.Done()
+			sig, err := c.AddPartial(CreatePartialSignature(share, c.Message()))
+			if err != nil {
+				t.Errorf("AddPartial failed: %v", err)
+			}
+			if sig != nil {
+				sigs <- sig
+			}
+		}(share)
+	}
+	wg.Wait()
+	close(sigs)
+
+	count := 0
+	for sig := range sigs {
+		count++
+		if !primitives.Verify(pub, c.Message(), sig) {
+			t.Error("Concurrently aggregated signature should verify")
+		}
+	}
+	if count != 1 {
+		t.Errorf("Expected exactly one caller to receive the signature, got %d", count)
+	}
+	if c.Phase() != 2 {
+		t.Errorf("Expected phase 2, got %d", c.Phase())
+	}
+}
+
+func TestShareGob(t *testing.T) {
+	shares, _, err := GenerateShares(2)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	shares[0].PartyID = "party-0"
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(shares); err != nil {
+		t.Fatalf("gob Encode failed: %v", err)
+	}
+	var got []*Share
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatalf("gob Decode failed: %v", err)
+	}
+	for i := range shares {
+		if *got[i] != *shares[i] {
+			t.Errorf("Share %d did not round-trip", i)
+		}
+	}
+
+	// The layout is flat: version, Index, PartyID length, PartyID, Bytes
+	data, err := shares[0].GobEncode()
+	if err != nil {
+		t.Fatalf("GobEncode failed: %v", err)
+	}
+	want := append([]byte{1, 0, 0, 0, 0, 0, 0, 0, 1, 0, 7}, "party-0"...)
+	want = append(want, shares[0].Bytes()...)
+	if !bytes.Equal(data, want) {
+		t.Error("GobEncode layout mismatch")
+	}
+
+	// Unknown versions and bad lengths are rejected without touching the share
+	before := *got[0]
+	bad := [][]byte{nil, data[:10], data[:len(data)-1], append(slices.Clone(data), 0)}
+	bumped := slices.Clone(data)
+	bumped[0] = 2
+	bad = append(bad, bumped)
+	for i, b := range bad {
+		if err := got[0].GobDecode(b); err != ErrInvalidShare {
+			t.Errorf("Malformed %d: expected ErrInvalidShare, got %v", i, err)
+		}
+	}
+	if *got[0] != before {
+		t.Error("Failed GobDecode should leave the share unchanged")
+	}
+}
+
+func TestRunBenchmarks(t *testing.T) {
+	res, err := RunBenchmarks(2, 3, 5)
+	if err != nil {
+		t.Fatalf("RunBenchmarks failed: %v", err)
+	}
+	if res.Threshold != 3 || res.Parties != 5 || res.Iterations != 2 {
+		t.Errorf("Unexpected parameters: %+v", res)
+	}
+	if res.Partials <= 0 || res.Aggregate <= 0 || res.Total != res.Partials+res.Aggregate {
+		t.Errorf("Unexpected timings: %+v", res)
+	}
+
+	if _, err := RunBenchmarks(1, 6, 5); err == nil {
+		t.Error("Expected error for t > n")
+	}
+}
+
+func TestGenerateSharesParallel(t *testing.T) {
+	seed := primitives.Keccak256([]byte("parallel shares"))
+	const n = 4
+
+	// Sequential reference: every position from its documented stream, in order
+	seqShares := newShares(n)
+	seqPub := &primitives.PublicKey{}
+	for i := 0; i < primitives.KeyBits; i++ {
+		var idx [2]byte
+		binary.BigEndian.PutUint16(idx[:], uint16(i))
+		random := primitives.NewSeededReader(primitives.Keccak256Multi(seed[:], idx[:]))
+		if err := generateSharePosition(seqShares, seqPub, i, random); err != nil {
+			t.Fatalf("generateSharePosition failed: %v", err)
+		}
+	}
+
+	var parShares []*Share
+	var parPub *primitives.PublicKey
+	for _, workers := range []int{1, 3, 8, 0} {
+		shares, pub, err := GenerateSharesParallel(n, seed, workers)
+		if err != nil {
+			t.Fatalf("GenerateSharesParallel failed: %v", err)
+		}
+		if !bytes.Equal(pub.Bytes(), seqPub.Bytes()) {
+			t.Errorf("workers=%d: public key differs from the sequential output", workers)
+		}
+		for j := range seqShares {
+			if !bytes.Equal(shares[j].Bytes(), seqShares[j].Bytes()) || shares[j].Index != seqShares[j].Index {
+				t.Errorf("workers=%d: share %d differs from the sequential output", workers, j)
+			}
+		}
+		parShares, parPub = shares, pub
+	}
+
+	message := primitives.Keccak256([]byte("parallel"))
+	partials := make([]*PartialSignature, n)
+	for j, share := range parShares {
+		partials[j] = CreatePartialSignature(share, message)
+	}
+	if _, err := AggregateAndVerify(partials, parPub, message); err != nil {
+		t.Errorf("Parallel shares should aggregate to a valid signature: %v", err)
+	}
+
+	if _, _, err := GenerateSharesParallel(0, seed, 1); err != ErrInvalidThreshold {
+		t.Errorf("Expected ErrInvalidThreshold for n=0, got %v", err)
+	}
+	for _, bad := range []int{0, -1} {
+		if _, _, err := GenerateSharesFromReader(bad, rand.Reader); err != ErrInvalidThreshold {
+			t.Errorf("GenerateSharesFromReader(%d): expected ErrInvalidThreshold, got %v", bad, err)
+		}
+	}
+}
+
+func BenchmarkGenerateSharesFromSeed(b *testing.B) {
+	seed := primitives.Keccak256([]byte("bench"))
+	for i := 0; i < b.N; i++ {
+		_, _, _ = GenerateSharesFromSeed(16, seed)
+	}
+}
+
+func BenchmarkGenerateSharesParallel(b *testing.B) {
+	seed := primitives.Keccak256([]byte("bench"))
+	for i := 0; i < b.N; i++ {
+		_, _, _ = GenerateSharesParallel(16, seed, 0)
+	}
+}
+
+func TestCoordinatorDuplicatePartial(t *testing.T) {
+	shares, pub, err := GenerateShares(3)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	config, _ := NewConfig(3, 3, "coordinator", 96369, testModuleAddress())
+
+	var safeTxHash, nextPKH [32]byte
+	safeTxHash[0] = 3
+	c := NewCoordinator(config, pub, safeTxHash, nextPKH)
+	for i, share := range shares {
+		share.PartyID = fmt.Sprintf("party-%d", i)
+		partyConfig, _ := NewConfig(3, 3, share.PartyID, 96369, testModuleAddress())
+		if _, err := c.AddCommitment(partyConfig.CreateDigestCommitment(safeTxHash), safeTxHash); err != nil {
+			t.Fatalf("AddCommitment failed: %v", err)
+		}
+	}
+
+	partial := CreatePartialSignature(shares[0], c.Message())
+	if _, err := c.AddPartial(partial); err != nil {
+		t.Fatalf("AddPartial failed: %v", err)
+	}
+
+	// Exact redelivery is a no-op and does not count twice
+	redelivered := *partial
+	for i := 0; i < 2; i++ {
+		sig, err := c.AddPartial(&redelivered)
+		if err != nil || sig != nil {
+			t.Fatalf("Redelivered partial should be a no-op, got %v, %v", sig, err)
+		}
+	}
+
+	// Conflicting partial from the same party is rejected
+	conflicting := *partial
+	conflicting.PreimagePartials[0][0] ^= 1
+	if _, err := c.AddPartial(&conflicting); err != ErrDuplicateParty {
+		t.Errorf("Expected ErrDuplicateParty, got %v", err)
+	}
+
+	// Remaining parties still complete a valid signature
+	var sig *primitives.Signature
+	for _, share := range shares[1:] {
+		sig, err = c.AddPartial(CreatePartialSignature(share, c.Message()))
+		if err != nil {
+			t.Fatalf("AddPartial failed: %v", err)
+		}
+	}
+	if sig == nil || !primitives.Verify(pub, c.Message(), sig) {
+		t.Error("Expected a valid signature after all distinct partials")
+	}
+}
+
+func TestCoordinatorShamir(t *testing.T) {
+	shares, pub, err := GenerateSharesShamir(2, 3)
+	if err != nil {
+		t.Fatalf("GenerateSharesShamir failed: %v", err)
+	}
+	config, _ := NewConfig(2, 3, "coordinator", 96369, testModuleAddress())
+
+	var safeTxHash, nextPKH [32]byte
+	safeTxHash[0] = 6
+	c := NewCoordinator(config, pub, safeTxHash, nextPKH)
+	c.SetAggregationMode(ModeShamir)
+	signers := []*Share{shares[0], shares[2]}
+	for i, share := range signers {
+		share.PartyID = fmt.Sprintf("party-%d", i)
+		partyConfig, _ := NewConfig(2, 3, share.PartyID, 96369, testModuleAddress())
+		if _, err := c.AddCommitment(partyConfig.CreateDigestCommitment(safeTxHash), safeTxHash); err != nil {
+			t.Fatalf("AddCommitment failed: %v", err)
+		}
+	}
+	if _, err := c.AddPartial(CreatePartialSignature(signers[0], c.Message())); err != nil {
+		t.Fatalf("AddPartial failed: %v", err)
+	}
+
+	// The mode survives a snapshot
+	data, err := c.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	restored, err := RestoreCoordinator(data)
+	if err != nil {
+		t.Fatalf("RestoreCoordinator failed: %v", err)
+	}
+
+	// Any two of the three parties complete the round
+	for _, coord := range []*Coordinator{c, restored} {
+		sig, err := coord.AddPartial(CreatePartialSignature(signers[1], c.Message()))
+		if err != nil {
+			t.Fatalf("AddPartial failed: %v", err)
+		}
+		if sig == nil || !primitives.Verify(pub, c.Message(), sig) {
+			t.Error("Expected a valid signature from two Shamir partials")
+		}
+	}
+}
+
+func TestCoordinatorVerifiesPartials(t *testing.T) {
+	shares, pub, err := GenerateShares(3)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	config, _ := NewConfig(3, 3, "coordinator", 96369, testModuleAddress())
+
+	var safeTxHash, nextPKH [32]byte
+	safeTxHash[0] = 4
+	c := NewCoordinator(config, pub, safeTxHash, nextPKH)
+	for i, share := range shares {
+		share.PartyID = fmt.Sprintf("party-%d", i)
+		partyConfig, _ := NewConfig(3, 3, share.PartyID, 96369, testModuleAddress())
+		if _, err := c.AddCommitment(partyConfig.CreateDigestCommitment(safeTxHash), safeTxHash); err != nil {
+			t.Fatalf("AddCommitment failed: %v", err)
+		}
+	}
+	publicShares := PublicSharesByParty(PublicShares(shares))
+	c.SetPublicShares(publicShares)
+
+	// Right message and party, wrong preimage material
+	bad := CreatePartialSignature(shares[1], c.Message())
+	bad.PreimagePartials[10][0] ^= 1
+	if publicShares["party-1"].VerifyPartial(bad) {
+		t.Fatal("Tampered partial should fail VerifyPartial")
+	}
+	_, err = c.AddPartial(bad)
+	var partyErr *PartyError
+	if !errors.Is(err, ErrInvalidPartial) || !errors.As(err, &partyErr) || partyErr.PartyID != "party-1" {
+		t.Fatalf("Expected ErrInvalidPartial naming party-1, got %v", err)
+	}
+
+	// The rejection is targeted: the round still completes with honest partials
+	var sig *primitives.Signature
+	for _, share := range shares {
+		sig, err = c.AddPartial(CreatePartialSignature(share, c.Message()))
+		if err != nil {
+			t.Fatalf("AddPartial failed: %v", err)
+		}
+	}
+	if sig == nil || !primitives.Verify(pub, c.Message(), sig) {
+		t.Error("Expected a valid signature from honest partials")
+	}
+}
+
+func TestCoordinatorDisagreeingParties(t *testing.T) {
+	_, pub, err := GenerateShares(3)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	config, _ := NewConfig(3, 3, "coordinator", 96369, testModuleAddress())
+
+	var safeTxHash, otherTxHash, nextPKH [32]byte
+	safeTxHash[0] = 5
+	otherTxHash[0] = 6
+	c := NewCoordinator(config, pub, safeTxHash, nextPKH)
+
+	for i := 0; i < 3; i++ {
+		partyConfig, _ := NewConfig(3, 3, fmt.Sprintf("party-%d", i), 96369, testModuleAddress())
+		digest := safeTxHash
+		if i == 1 {
+			digest = otherTxHash
+		}
+		commitment := partyConfig.CreateDigestCommitment(digest)
+		_, err := c.AddCommitment(commitment, safeTxHash)
+		if i != 1 {
+			if err != nil {
+				t.Fatalf("AddCommitment failed: %v", err)
+			}
+			continue
+		}
+
+		var mismatch *CommitmentMismatchError
+		if !errors.Is(err, ErrDigestMismatch) || !errors.As(err, &mismatch) {
+			t.Fatalf("Expected CommitmentMismatchError, got %v", err)
+		}
+		if mismatch.PartyID != "party-1" || mismatch.Received != commitment.Commitment ||
+			mismatch.Expected != partyConfig.CreateDigestCommitment(safeTxHash).Commitment {
+			t.Errorf("Mismatch details wrong: %+v", mismatch)
+		}
+	}
+
+	got := c.DisagreeingParties()
+	if len(got) != 1 || got[0] != "party-1" {
+		t.Errorf("Expected [party-1], got %v", got)
+	}
+}
+
+func TestCoordinatorTimings(t *testing.T) {
+	shares, pub, err := GenerateShares(2)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	config, _ := NewConfig(2, 2, "coordinator", 96369, testModuleAddress())
+
+	var safeTxHash, nextPKH [32]byte
+	safeTxHash[0] = 7
+	c := NewCoordinator(config, pub, safeTxHash, nextPKH)
+	if tm := c.Timings(); tm.Started.IsZero() || !tm.CommitmentsDone.IsZero() {
+		t.Fatalf("Only Started should be set on a new coordinator: %+v", tm)
+	}
+
+	for i, share := range shares {
+		share.PartyID = fmt.Sprintf("party-%d", i)
+		partyConfig, _ := NewConfig(2, 2, share.PartyID, 96369, testModuleAddress())
+		if _, err := c.AddCommitment(partyConfig.CreateDigestCommitment(safeTxHash), safeTxHash); err != nil {
+			t.Fatalf("AddCommitment failed: %v", err)
+		}
+	}
+	for _, share := range shares {
+		if _, err := c.AddPartial(CreatePartialSignature(share, c.Message())); err != nil {
+			t.Fatalf("AddPartial failed: %v", err)
+		}
+	}
+
+	tm := c.Timings()
+	marks := []time.Time{tm.Started, tm.CommitmentsDone, tm.PartialsDone, tm.Completed}
+	for i, m := range marks {
+		if m.IsZero() {
+			t.Fatalf("Timing mark %d not populated: %+v", i, tm)
+		}
+		if i > 0 && m.Before(marks[i-1]) {
+			t.Errorf("Timing mark %d precedes mark %d: %+v", i, i-1, tm)
+		}
+	}
+	if tm.Commitment() < 0 || tm.Partial() < 0 || tm.Aggregation() <= 0 {
+		t.Errorf("Unexpected phase durations: %v, %v, %v", tm.Commitment(), tm.Partial(), tm.Aggregation())
+	}
+}
+
+func TestBatchSigning(t *testing.T) {
+	const parties, k = 3, 3
+
+	// keyShares[j][p] is party p's share of shared key j
+	keyShares := make([][]*Share, k)
+	pubs := make([]*primitives.PublicKey, k)
+	messages := make([][32]byte, k)
+	for j := 0; j < k; j++ {
+		shares, pub, err := GenerateShares(parties)
+		if err != nil {
+			t.Fatalf("GenerateShares failed: %v", err)
+		}
+		keyShares[j], pubs[j] = shares, pub
+		messages[j] = primitives.Keccak256([]byte{byte(j)})
+	}
+
+	partialsPerMessage := make([][]*PartialSignature, k)
+	for p := 0; p < parties; p++ {
+		mine := make([]*Share, k)
+		for j := range mine {
+			mine[j] = keyShares[j][p]
+		}
+		partials, err := CreatePartialBatch(mine, messages)
+		if err != nil {
+			t.Fatalf("CreatePartialBatch failed: %v", err)
+		}
+		for j, partial := range partials {
+			partialsPerMessage[j] = append(partialsPerMessage[j], partial)
+		}
+	}
+
+	sigs, err := AggregateBatch(partialsPerMessage)
+	if err != nil {
+		t.Fatalf("AggregateBatch failed: %v", err)
+	}
+	for j, sig := range sigs {
+		if !primitives.Verify(pubs[j], messages[j], sig) {
+			t.Errorf("Batch signature %d should verify against key %d", j, j)
+		}
+	}
+
+	// A nil share is an error at any position, not a panic
+	for j := 0; j < k; j++ {
+		withNil := []*Share{keyShares[0][1], keyShares[1][1], keyShares[2][1]}
+		withNil[j] = nil
+		if _, err := CreatePartialBatch(withNil, messages); !errors.Is(err, ErrInvalidShare) {
+			t.Errorf("Nil share at %d: expected ErrInvalidShare, got %v", j, err)
+		}
+	}
+
+	// A share that already signed another message is refused
+	shifted := []*Share{keyShares[1][0], keyShares[2][0], keyShares[0][0]}
+	if _, err := CreatePartialBatch(shifted, messages); !errors.Is(err, primitives.ErrKeyAlreadyUsed) {
+		t.Errorf("Used share: expected ErrKeyAlreadyUsed, got %v", err)
+	}
+
+	reused := []*Share{keyShares[0][0], keyShares[1][0], keyShares[0][0]}
+	if _, err := CreatePartialBatch(reused, messages); err != ErrBatchKeyReuse {
+		t.Errorf("Expected ErrBatchKeyReuse, got %v", err)
+	}
+	if _, err := CreatePartialBatch(reused[:2], messages); err != ErrBatchSize {
+		t.Errorf("Expected ErrBatchSize, got %v", err)
+	}
+}
+
+func TestCoordinatorSnapshot(t *testing.T) {
+	shares, pub, err := GenerateShares(3)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	config, _ := NewConfig(3, 3, "coordinator", 96369, testModuleAddress())
+
+	var safeTxHash, nextPKH [32]byte
+	safeTxHash[0] = 4
+	c := NewCoordinator(config, pub, safeTxHash, nextPKH)
+	for i, share := range shares {
+		share.PartyID = fmt.Sprintf("party-%d", i)
+		partyConfig, _ := NewConfig(3, 3, share.PartyID, 96369, testModuleAddress())
+		if _, err := c.AddCommitment(partyConfig.CreateDigestCommitment(safeTxHash), safeTxHash); err != nil {
+			t.Fatalf("AddCommitment failed: %v", err)
+		}
+	}
+	c.SetPublicShares(PublicSharesByParty(PublicShares(shares)))
+	if _, err := c.AddPartial(CreatePartialSignature(shares[0], c.Message())); err != nil {
+		t.Fatalf("AddPartial failed: %v", err)
+	}
+
+	data, err := c.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	restored, err := RestoreCoordinator(data)
+	if err != nil {
+		t.Fatalf("RestoreCoordinator failed: %v", err)
+	}
+	if restored.Phase() != 1 || restored.Message() != c.Message() {
+		t.Fatalf("Restored state mismatch: phase %d", restored.Phase())
+	}
+	if restored.Config().ModuleAddress != testModuleAddress() {
+		t.Error("Restored config mismatch")
+	}
+
+	// Public shares survive the snapshot: a tampered partial is still caught
+	bad := CreatePartialSignature(shares[1], c.Message())
+	bad.PreimagePartials[3][1] ^= 1
+	if _, err := restored.AddPartial(bad); !errors.Is(err, ErrInvalidPartial) {
+		t.Errorf("Restored coordinator should reject a tampered partial, got %v", err)
+	}
+
+	// Both coordinators complete with the same signature
+	var sigOrig, sigRestored *primitives.Signature
+	for _, share := range shares[1:] {
+		partial := CreatePartialSignature(share, c.Message())
+		if sigOrig, err = c.AddPartial(partial); err != nil {
+			t.Fatalf("AddPartial failed: %v", err)
+		}
+		if sigRestored, err = restored.AddPartial(partial); err != nil {
+			t.Fatalf("AddPartial on restored coordinator failed: %v", err)
+		}
+	}
+	if sigRestored == nil || *sigRestored != *sigOrig {
+		t.Error("Restored coordinator should produce the same signature")
+	}
+	if !primitives.Verify(pub, c.Message(), sigRestored) {
+		t.Error("Restored signature should verify")
+	}
+
+	if _, err := RestoreCoordinator([]byte("garbage")); err != ErrInvalidSnapshot {
+		t.Errorf("Expected ErrInvalidSnapshot, got %v", err)
+	}
+}
+
+func TestConfigJSON(t *testing.T) {
+	config, err := NewConfig(2, 3, "party-1", 1<<63+7, testModuleAddress())
+	if err != nil {
+		t.Fatalf("NewConfig failed: %v", err)
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded Config
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.Threshold != 2 || decoded.TotalParties != 3 || decoded.PartyID != "party-1" ||
+		decoded.ChainID != config.ChainID || decoded.ModuleAddress != config.ModuleAddress {
+		t.Errorf("Round trip mismatch: got %+v", &decoded)
+	}
+	safeTxHash := primitives.Keccak256([]byte("tx"))
+	if decoded.ComputeMessage(safeTxHash, [32]byte{}) != config.ComputeMessage(safeTxHash, [32]byte{}) {
+		t.Error("Decoded config should compute the same message")
+	}
+
+	// Invalid fields are rejected and leave the receiver unchanged
+	for _, bad := range []string{
+		`{"threshold":2,"totalParties":3,"chainId":"1","moduleAddress":"0x1234"}`,
+		`{"threshold":2,"totalParties":3,"chainId":"1","moduleAddress":"0xzz00000000000000000000000000000000000000"}`,
+		`{"threshold":2,"totalParties":3,"chainId":"1","moduleAddress":"4040404040404040404040404040404040404040"}`,
+		`{"threshold":2,"totalParties":3,"chainId":"-1","moduleAddress":"0x4040404040404040404040404040404040404040"}`,
+		`{"threshold":4,"totalParties":3,"chainId":"1","moduleAddress":"0x4040404040404040404040404040404040404040"}`,
+	} {
+		if err := json.Unmarshal([]byte(bad), &decoded); err == nil {
+			t.Errorf("Unmarshal(%s) should fail", bad)
+		}
+	}
+	if decoded.ChainID != config.ChainID || decoded.ModuleAddress != config.ModuleAddress {
+		t.Error("Failed Unmarshal should not modify the config")
+	}
+}
+
+func TestCoordinatorStatusJSON(t *testing.T) {
+	shares, pub, err := GenerateShares(2)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	config, err := NewConfig(2, 2, "coordinator", 96369, testModuleAddress())
+	if err != nil {
+		t.Fatalf("NewConfig failed: %v", err)
+	}
+	safeTxHash := primitives.Keccak256([]byte("status"))
+	c := NewCoordinator(config, pub, safeTxHash, [32]byte{})
+	for j, share := range shares {
+		share.PartyID = fmt.Sprintf("party-%d", j)
+		if _, err := c.AddCommitment(DigestCommitment{PartyID: share.PartyID, Commitment: digestCommitment(safeTxHash, share.PartyID)}, safeTxHash); err != nil {
+			t.Fatalf("AddCommitment failed: %v", err)
+		}
+	}
+	message := c.Message()
+	if _, err := c.AddPartial(CreatePartialSignature(shares[0], message)); err != nil {
+		t.Fatalf("AddPartial failed: %v", err)
+	}
+
+	data, err := json.Marshal(c.Status())
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var status CoordinatorStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	want := CoordinatorStatus{
+		Phase:        1,
+		Threshold:    2,
+		TotalParties: 2,
+		Commitments:  2,
+		Partials:     1,
+		Message:      fmt.Sprintf("0x%x", message),
+	}
+	if status != want {
+		t.Errorf("Status = %+v, want %+v", status, want)
+	}
+}
+
+func TestGenerateSharesWithCommitments(t *testing.T) {
+	message := primitives.Keccak256([]byte("commitments"))
+
+	shares, pub, commitments, err := GenerateSharesWithCommitments(3)
+	if err != nil {
+		t.Fatalf("GenerateSharesWithCommitments failed: %v", err)
+	}
+	shamirShares, shamirPub, shamirCommitments, err := GenerateSharesShamirWithCommitments(2, 3)
+	if err != nil {
+		t.Fatalf("GenerateSharesShamirWithCommitments failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name        string
+		shares      []*Share
+		commitments []*PartyPublicShares
+	}{
+		{"additive", shares, commitments},
+		{"shamir", shamirShares, shamirCommitments},
+	} {
+		if len(tc.commitments) != len(tc.shares) {
+			t.Fatalf("%s: got %d commitments for %d shares", tc.name, len(tc.commitments), len(tc.shares))
+		}
+		for j, share := range tc.shares {
+			c := tc.commitments[j]
+			if c.Index != share.Index {
+				t.Errorf("%s: commitment %d has index %d, want %d", tc.name, j, c.Index, share.Index)
+			}
+			for i := 0; i < primitives.KeyBits; i++ {
+				for bit := 0; bit < 2; bit++ {
+					if c.Hashes.Hashes[i][bit] != primitives.Keccak256(share.PreimageShares[i][bit][:]) {
+						t.Fatalf("%s: party %d commitment mismatch at [%d][%d]", tc.name, j, i, bit)
+					}
+				}
+			}
+
+			partial := CreatePartialSignature(share, message)
+			if !c.VerifyPartial(partial) {
+				t.Errorf("%s: party %d partial should verify against its commitment", tc.name, j)
+			}
+			other := tc.commitments[(j+1)%len(tc.commitments)]
+			if other.VerifyPartial(partial) {
+				t.Errorf("%s: party %d partial should not verify against another party", tc.name, j)
+			}
+		}
+	}
+
+	// The reconstructed preimages still hash to the group public key
+	for i := 0; i < primitives.KeyBits; i++ {
+		for bit := 0; bit < 2; bit++ {
+			preimage := ReconstructPreimage(shares, i, bit)
+			if primitives.Keccak256(preimage[:]) != pub.Hashes[i][bit] {
+				t.Fatalf("Reconstructed preimage [%d][%d] does not match public key", i, bit)
+			}
+		}
+	}
+	sig, err := AggregateShamir([]*PartialSignature{
+		CreatePartialSignature(shamirShares[0], message),
+		CreatePartialSignature(shamirShares[2], message),
+	})
+	if err != nil {
+		t.Fatalf("AggregateShamir failed: %v", err)
+	}
+	if !primitives.Verify(shamirPub, message, sig) {
+		t.Error("Shamir aggregate should verify against the group public key")
+	}
+}
+
+func TestAggregateNilPartials(t *testing.T) {
+	if CreatePartialSignature(nil, [32]byte{}) != nil {
+		t.Error("CreatePartialSignature(nil) should return nil")
+	}
+
+	shares, _, err := GenerateShares(2)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	message := primitives.Keccak256([]byte("nil"))
+	for _, partials := range [][]*PartialSignature{
+		{nil},
+		{CreatePartialSignature(shares[0], message), nil},
+		{CreatePartialSignature(shares[0], message), CreatePartialSignature(nil, message)},
+	} {
+		if _, err := Aggregate(partials); err != ErrInvalidPartial {
+			t.Errorf("Aggregate: expected ErrInvalidPartial, got %v", err)
+		}
+		if _, err := AggregateShamir(partials); err != ErrInvalidPartial {
+			t.Errorf("AggregateShamir: expected ErrInvalidPartial, got %v", err)
+		}
+	}
+}
+
+func TestAggregateBestEffort(t *testing.T) {
+	message := primitives.Keccak256([]byte("best effort"))
+
+	// Shamir 3-of-5: four good partials and one corrupted
+	shares, pub, err := GenerateSharesShamir(3, 5)
+	if err != nil {
+		t.Fatalf("GenerateSharesShamir failed: %v", err)
+	}
+	for j, share := range shares {
+		share.PartyID = fmt.Sprintf("party-%d", j)
+	}
+	pubShares := PublicSharesByParty(PublicShares(shares))
+	partials := make([]*PartialSignature, len(shares))
+	for j, share := range shares {
+		partials[j] = CreatePartialSignature(share, message)
+	}
+	partials[1].PreimagePartials[0][0] ^= 1
+
+	sig, dropped, err := AggregateBestEffort(partials, pubShares, pub, message, 3)
+	if err != nil {
+		t.Fatalf("AggregateBestEffort failed: %v", err)
+	}
+	if !primitives.Verify(pub, message, sig) {
+		t.Error("Best-effort Shamir aggregate should verify")
+	}
+	if len(dropped) != 1 || dropped[0] != "party-1" {
+		t.Errorf("Dropped = %v, want [party-1]", dropped)
+	}
+
+	// Too many bad partials
+	partials[2].PreimagePartials[0][0] ^= 1
+	partials[3].PreimagePartials[0][0] ^= 1
+	if _, dropped, err := AggregateBestEffort(partials, pubShares, pub, message, 3); err != ErrNotEnoughParties || len(dropped) != 3 {
+		t.Errorf("Expected ErrNotEnoughParties with 3 dropped, got %v, %v", err, dropped)
+	}
+
+	// Additive 3-of-3 with a stray partial from an unknown party
+	addShares, addPub, addCommitments, err := GenerateSharesWithCommitments(3)
+	if err != nil {
+		t.Fatalf("GenerateSharesWithCommitments failed: %v", err)
+	}
+	addPubShares := make(map[string]*PartyPublicShares, len(addShares))
+	addPartials := make([]*PartialSignature, 0, len(addShares)+1)
+	for j, share := range addShares {
+		share.PartyID = fmt.Sprintf("party-%d", j)
+		addCommitments[j].PartyID = share.PartyID
+		addPubShares[share.PartyID] = addCommitments[j]
+		addPartials = append(addPartials, CreatePartialSignature(share, message))
+	}
+	stray := CreatePartialSignature(addShares[0], message)
+	stray.PartyID = "mallory"
+	addPartials = append(addPartials, stray)
+
+	sig, dropped, err = AggregateBestEffort(addPartials, addPubShares, addPub, message, 3)
+	if err != nil {
+		t.Fatalf("AggregateBestEffort (additive) failed: %v", err)
+	}
+	if !primitives.Verify(addPub, message, sig) {
+		t.Error("Best-effort additive aggregate should verify")
+	}
+	if len(dropped) != 1 || dropped[0] != "mallory" {
+		t.Errorf("Dropped = %v, want [mallory]", dropped)
+	}
+}
+
+func TestCoordinatorOnThresholdReached(t *testing.T) {
+	shares, pub, err := GenerateShares(3)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	config, _ := NewConfig(3, 3, "coordinator", 96369, testModuleAddress())
+
+	var safeTxHash, nextPKH [32]byte
+	safeTxHash[0] = 7
+	c := NewCoordinator(config, pub, safeTxHash, nextPKH)
+
+	fired := make(chan *primitives.Signature, 2)
+	c.OnThresholdReached(func(sig *primitives.Signature) {
+		fired <- sig
+	})
+
+	for i, share := range shares {
+		share.PartyID = fmt.Sprintf("party-%d", i)
+		if _, err := c.AddCommitment(DigestCommitment{PartyID: share.PartyID, Commitment: digestCommitment(safeTxHash, share.PartyID)}, safeTxHash); err != nil {
+			t.Fatalf("AddCommitment failed: %v", err)
+		}
+	}
+
+	var sig *primitives.Signature
+	for i, share := range shares {
+		sig, err = c.AddPartial(CreatePartialSignature(share, c.Message()))
+		if err != nil {
+			t.Fatalf("AddPartial failed: %v", err)
+		}
+		if i < len(shares)-1 {
+			select {
+			case <-fired:
+				t.Fatalf("Callback fired after %d of 3 partials", i+1)
+			default:
+			}
+		}
+	}
+	if sig == nil {
+		t.Fatal("Expected a completed signature")
+	}
+
+	select {
+	case got := <-fired:
+		if *got != *sig || !primitives.Verify(pub, c.Message(), got) {
+			t.Error("Callback should receive the valid completed signature")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Callback did not fire")
+	}
+
+	// Redelivery after completion does not fire again
+	_, _ = c.AddPartial(CreatePartialSignature(shares[0], c.Message()))
+	select {
+	case <-fired:
+		t.Error("Callback fired more than once")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestReconstructPreimageValidation(t *testing.T) {
+	shares, pub, err := GenerateShares(3)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	preimage, err := ReconstructPreimageAdditive(shares, 3, 10, 1)
+	if err != nil {
+		t.Fatalf("ReconstructPreimageAdditive failed: %v", err)
+	}
+	if primitives.Keccak256(preimage[:]) != pub.Hashes[10][1] {
+		t.Error("Full additive set should reconstruct the preimage")
+	}
+
+	if _, err := ReconstructPreimageAdditive([]*Share{shares[0], shares[1], shares[1]}, 3, 10, 1); err != ErrInvalidShareIndex {
+		t.Errorf("Duplicate index: expected ErrInvalidShareIndex, got %v", err)
+	}
+	if _, err := ReconstructPreimageAdditive(shares[:2], 3, 10, 1); err != ErrNotEnoughParties {
+		t.Errorf("Incomplete set: expected ErrNotEnoughParties, got %v", err)
+	}
+	if _, err := ReconstructPreimageAdditive(shares, 3, primitives.KeyBits, 0); err != ErrInvalidShare {
+		t.Errorf("Out-of-range position: expected ErrInvalidShare, got %v", err)
+	}
+
+	shamirShares, shamirPub, err := GenerateSharesShamir(2, 4)
+	if err != nil {
+		t.Fatalf("GenerateSharesShamir failed: %v", err)
+	}
+	for _, subset := range [][]*Share{shamirShares[:2], shamirShares[1:], {shamirShares[3], shamirShares[0]}} {
+		preimage, err := ReconstructPreimageShamir(subset, 2, 200, 0)
+		if err != nil {
+			t.Fatalf("ReconstructPreimageShamir failed: %v", err)
+		}
+		if primitives.Keccak256(preimage[:]) != shamirPub.Hashes[200][0] {
+			t.Error("Any t Shamir shares should reconstruct the preimage")
+		}
+	}
+	if _, err := ReconstructPreimageShamir(shamirShares[:1], 2, 200, 0); err != ErrNotEnoughParties {
+		t.Errorf("Below threshold: expected ErrNotEnoughParties, got %v", err)
+	}
+	if _, err := ReconstructPreimageShamir([]*Share{shamirShares[2], shamirShares[2]}, 2, 200, 0); err != ErrInvalidShareIndex {
+		t.Errorf("Duplicate index: expected ErrInvalidShareIndex, got %v", err)
+	}
+}
+
+func TestAggregateOrderIndependent(t *testing.T) {
+	shares, pub, err := GenerateShares(4)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	message := primitives.Keccak256([]byte("order"))
+	partials := make([]*PartialSignature, len(shares))
+	for j, share := range shares {
+		partials[j] = CreatePartialSignature(share, message)
+	}
+
+	want, err := Aggregate(partials)
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if !primitives.Verify(pub, message, want) {
+		t.Fatal("Aggregate should verify")
+	}
+	for _, order := range [][]int{{3, 2, 1, 0}, {1, 3, 0, 2}, {2, 0, 3, 1}} {
+		shuffled := make([]*PartialSignature, len(order))
+		for j, k := range order {
+			shuffled[j] = partials[k]
+		}
+		got, err := Aggregate(shuffled)
+		if err != nil {
+			t.Fatalf("Aggregate(%v) failed: %v", order, err)
+		}
+		if *got != *want {
+			t.Errorf("Aggregate(%v) differs from in-order result", order)
+		}
+		if shuffled[0] != partials[order[0]] {
+			t.Error("Aggregate should not reorder the caller's slice")
+		}
+	}
+
+	// A divergent mask is caught wherever it sits
+	other := primitives.Keccak256([]byte("other"))
+	for k := range shares {
+		divergent := slices.Clone(partials)
+		reused := &Share{PartyID: shares[k].PartyID, Index: shares[k].Index, PreimageShares: shares[k].PreimageShares}
+		divergent[k] = CreatePartialSignature(reused, other)
+		if _, err := Aggregate(divergent); err != ErrDigestMismatch {
+			t.Errorf("Divergent mask at %d: expected ErrDigestMismatch, got %v", k, err)
+		}
+	}
+
+	// Gaps and duplicates are rejected
+	if _, err := Aggregate([]*PartialSignature{partials[0], partials[2], partials[3]}); err != ErrInvalidShareIndex {
+		t.Errorf("Index gap: expected ErrInvalidShareIndex, got %v", err)
+	}
+	if _, err := Aggregate([]*PartialSignature{partials[0], partials[1], partials[1], partials[2]}); err != ErrInvalidShareIndex {
+		t.Errorf("Duplicate index: expected ErrInvalidShareIndex, got %v", err)
+	}
+}
+
+func TestZeroSharesAndPartials(t *testing.T) {
+	shares, pub, err := GenerateShares(2)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	config, _ := NewConfig(2, 2, "coordinator", 96369, testModuleAddress())
+	var safeTxHash, nextPKH [32]byte
+	safeTxHash[0] = 9
+	c := NewCoordinator(config, pub, safeTxHash, nextPKH)
+
+	partials := make([]*PartialSignature, len(shares))
+	for j, share := range shares {
+		share.PartyID = fmt.Sprintf("party-%d", j)
+		if _, err := c.AddCommitment(DigestCommitment{PartyID: share.PartyID, Commitment: digestCommitment(safeTxHash, share.PartyID)}, safeTxHash); err != nil {
+			t.Fatalf("AddCommitment failed: %v", err)
+		}
+	}
+	var sig *primitives.Signature
+	for j, share := range shares {
+		partials[j] = CreatePartialSignature(share, c.Message())
+		if sig, err = c.AddPartial(partials[j]); err != nil {
+			t.Fatalf("AddPartial failed: %v", err)
+		}
+	}
+	if sig == nil {
+		t.Fatal("Expected a completed signature")
+	}
+
+	c.ZeroPartials()
+	var zeroPartials [primitives.KeyBits][primitives.PreimageSize]byte
+	for j, partial := range partials {
+		if partial.PreimagePartials != zeroPartials {
+			t.Errorf("Partial %d not zeroed by ZeroPartials", j)
+		}
+	}
+	if c.Status().Partials != 0 {
+		t.Error("ZeroPartials should discard collected partials")
+	}
+	if !primitives.Verify(pub, c.Message(), sig) {
+		t.Error("Final signature should survive ZeroPartials")
+	}
+
+	var zeroShares [primitives.KeyBits][2][primitives.PreimageSize]byte
+	for j, share := range shares {
+		share.Zero()
+		if share.PreimageShares != zeroShares {
+			t.Errorf("Share %d not zeroed", j)
+		}
+	}
+
+	partial := CreatePartialSignature(&Share{PreimageShares: [primitives.KeyBits][2][primitives.PreimageSize]byte{{{1}, {2}}}}, [32]byte{})
+	partial.Zero()
+	if partial.PreimagePartials != zeroPartials {
+		t.Error("PartialSignature.Zero should zero the preimage partials")
+	}
+}
+
+func TestAggregateAndLocate(t *testing.T) {
+	shares, pub, err := GenerateShares(3)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	message := primitives.Keccak256([]byte("locate"))
+	partials := make([]*PartialSignature, len(shares))
+	for j, share := range shares {
+		partials[j] = CreatePartialSignature(share, message)
+	}
+
+	sig, failed, err := AggregateAndLocate(partials, pub, message)
+	if err != nil || failed != nil {
+		t.Fatalf("Valid partials: got %v, %v", failed, err)
+	}
+	if !primitives.Verify(pub, message, sig) {
+		t.Error("Located aggregate should verify")
+	}
+
+	// Corrupt party 2's revealed preimages at a few positions
+	corrupted := []int{0, 17, 128, 255}
+	bad := *partials[1]
+	for _, i := range corrupted {
+		bad.PreimagePartials[i][5] ^= 0x80
+	}
+	tampered := []*PartialSignature{partials[0], &bad, partials[2]}
+
+	sig, failed, err = AggregateAndLocate(tampered, pub, message)
+	if err != ErrInvalidPartial {
+		t.Fatalf("Expected ErrInvalidPartial, got %v", err)
+	}
+	if sig != nil {
+		t.Error("Failed aggregate should not be returned")
+	}
+	if !slices.Equal(failed, corrupted) {
+		t.Errorf("Failing positions = %v, want %v", failed, corrupted)
+	}
+
+	if _, _, err := AggregateAndLocate(partials, nil, message); err != ErrInvalidPartial {
+		t.Errorf("Nil public key: expected ErrInvalidPartial, got %v", err)
+	}
+}
+
+func TestSessionManager(t *testing.T) {
+	const n = 3
+	config, _ := NewConfig(n, n, "coordinator", 96369, testModuleAddress())
+	m := NewSessionManager(config, time.Minute, 2)
+	clock := time.Unix(1_700_000_000, 0)
+	m.now = func() time.Time { return clock }
+
+	type round struct {
+		id         string
+		shares     []*Share
+		pub        *primitives.PublicKey
+		safeTxHash [32]byte
+		message    [32]byte
+	}
+	rounds := make([]*round, 2)
+	for k := range rounds {
+		shares, pub, err := GenerateShares(n)
+		if err != nil {
+			t.Fatalf("GenerateShares failed: %v", err)
+		}
+		r := &round{id: fmt.Sprintf("session-%d", k), shares: shares, pub: pub}
+		r.safeTxHash[0] = byte(k + 1)
+		coord, err := m.NewSession(r.id, pub, r.safeTxHash, [32]byte{})
+		if err != nil {
+			t.Fatalf("NewSession(%s) failed: %v", r.id, err)
+		}
+		r.message = coord.Message()
+		for j, share := range shares {
+			share.PartyID = fmt.Sprintf("party-%d", j)
+		}
+		rounds[k] = r
+	}
+
+	if _, err := m.NewSession("session-0", rounds[0].pub, [32]byte{9}, [32]byte{}); err != ErrSessionExists {
+		t.Errorf("Duplicate ID: expected ErrSessionExists, got %v", err)
+	}
+	if _, err := m.NewSession("other", rounds[0].pub, rounds[1].safeTxHash, [32]byte{}); err != ErrSessionExists {
+		t.Errorf("Duplicate safeTxHash: expected ErrSessionExists, got %v", err)
+	}
+	if _, err := m.NewSession("third", rounds[0].pub, [32]byte{9}, [32]byte{}); err != ErrTooManySessions {
+		t.Errorf("Full manager: expected ErrTooManySessions, got %v", err)
+	}
+
+	// Interleave both rounds' commitments and partials concurrently
+	var wg sync.WaitGroup
+	for _, r := range rounds {
+		for _, share := range r.shares {
+			wg.Add(1)
+			go func(r *round, share *Share) {
+				defer wg.Done()
+				partyConfig, _ := NewConfig(n, n, share.PartyID, 96369, testModuleAddress())
+				if _, err := m.AddCommitment(partyConfig.CreateDigestCommitment(r.safeTxHash), r.safeTxHash); err != nil {
+					t.Errorf("%s: AddCommitment failed: %v", r.id, err)
+				}
+			}(r, share)
+		}
+	}
+	wg.Wait()
+	for _, r := range rounds {
+		for _, share := range r.shares {
+			wg.Add(1)
+			go func(r *round, share *Share) {
+				defer wg.Done()
+				if _, err := m.AddPartial(CreatePartialSignature(share, r.message)); err != nil {
+					t.Errorf("%s: AddPartial failed: %v", r.id, err)
+				}
+			}(r, share)
+		}
+	}
+	wg.Wait()
+
+	byID := make(map[string]*round)
+	for _, r := range rounds {
+		byID[r.id] = r
+	}
+	for range rounds {
+		res := <-m.Results()
+		r, ok := byID[res.SessionID]
+		if !ok {
+			t.Fatalf("Unexpected or repeated session %q", res.SessionID)
+		}
+		delete(byID, res.SessionID)
+		if res.Message != r.message {
+			t.Errorf("%s: result for the wrong message", r.id)
+		}
+		if !primitives.Verify(r.pub, r.message, res.Signature) {
+			t.Errorf("%s: signature should verify against its own key", r.id)
+		}
+	}
+	if m.Len() != 0 {
+		t.Errorf("Completed sessions should be removed, %d remain", m.Len())
+	}
+
+	// Unknown routes and expiry
+	if _, err := m.AddPartial(CreatePartialSignature(rounds[0].shares[0], rounds[0].message)); err != ErrUnknownSession {
+		t.Errorf("Completed session: expected ErrUnknownSession, got %v", err)
+	}
+	if _, err := m.NewSession("stale", rounds[0].pub, [32]byte{7}, [32]byte{}); err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	clock = clock.Add(2 * time.Minute)
+	if _, err := m.AddCommitment(DigestCommitment{PartyID: "party-0"}, [32]byte{7}); err != ErrUnknownSession {
+		t.Errorf("Expired session: expected ErrUnknownSession, got %v", err)
+	}
+	if m.Len() != 0 {
+		t.Errorf("Expired session should be removed on access, %d remain", m.Len())
+	}
+
+	// Both slots can be refilled once their sessions expire, without Expire
+	for _, id := range []string{"a", "b"} {
+		if _, err := m.NewSession(id, rounds[0].pub, [32]byte{id[0]}, [32]byte{}); err != nil {
+			t.Fatalf("NewSession(%s) failed: %v", id, err)
+		}
+	}
+	clock = clock.Add(2 * time.Minute)
+	if _, err := m.NewSession("c", rounds[0].pub, [32]byte{'c'}, [32]byte{}); err != nil {
+		t.Errorf("NewSession over expired sessions failed: %v", err)
+	}
+	clock = clock.Add(2 * time.Minute)
+	if expired := m.Expire(); len(expired) != 1 || expired[0] != "c" {
+		t.Errorf("Expire = %v, want [c]", expired)
+	}
+}
+
+func TestSessionManagerFullResults(t *testing.T) {
+	const n = 2
+	config, _ := NewConfig(n, n, "coordinator", 96369, testModuleAddress())
+	m := NewSessionManager(config, time.Minute, 1)
+
+	// Nobody drains Results: the second completion must not block, and
+	// reports the undelivered result
+	for k := 0; k < 2; k++ {
+		shares, pub, err := GenerateShares(n)
+		if err != nil {
+			t.Fatalf("GenerateShares failed: %v", err)
+		}
+		safeTxHash := [32]byte{byte(k + 1)}
+		coord, err := m.NewSession(fmt.Sprintf("session-%d", k), pub, safeTxHash, [32]byte{})
+		if err != nil {
+			t.Fatalf("NewSession failed: %v", err)
+		}
+		message := coord.Message()
+		var sig *primitives.Signature
+		for j, share := range shares {
+			share.PartyID = fmt.Sprintf("party-%d", j)
+			partyConfig, _ := NewConfig(n, n, share.PartyID, 96369, testModuleAddress())
+			if _, err := m.AddCommitment(partyConfig.CreateDigestCommitment(safeTxHash), safeTxHash); err != nil {
+				t.Fatalf("AddCommitment failed: %v", err)
+			}
+		}
+		for j, share := range shares {
+			sig, err = m.AddPartial(CreatePartialSignature(share, message))
+			if j < n-1 || k == 0 {
+				if err != nil {
+					t.Fatalf("AddPartial failed: %v", err)
+				}
+			} else if err != ErrResultsFull {
+				t.Errorf("Full results: expected ErrResultsFull, got %v", err)
+			}
+		}
+		if sig == nil || !primitives.Verify(pub, message, sig) {
+			t.Errorf("session-%d: AddPartial should return the signature", k)
+		}
+	}
+
+	if res := <-m.Results(); res.SessionID != "session-0" {
+		t.Errorf("Buffered result = %q, want session-0", res.SessionID)
+	}
+}
+
+func TestShareSigner(t *testing.T) {
+	shares, _, err := GenerateShares(2)
+	if err != nil {
+		t.Fatalf("GenerateShares failed: %v", err)
+	}
+	share := shares[0]
+	message := primitives.Keccak256([]byte("share signer"))
+
+	signer := share.Signer()
+	partial, err := CreatePartialWithSigner(signer, share.PartyID, share.Index, message)
+	if err != nil {
+		t.Fatalf("CreatePartialWithSigner failed: %v", err)
+	}
+	if *partial != *CreatePartialSignature(share, message) {
+		t.Error("Signer partial should match CreatePartialSignature")
+	}
+	if signer.PublicKey().Hash() != share.PublicShare().Hash() {
+		t.Error("Signer public key should equal PublicShare")
+	}
+
+	// The share refuses a second message through any signer or helper
+	other := primitives.Keccak256([]byte("other"))
+	if _, err := signer.Sign(other); err != primitives.ErrKeyAlreadyUsed {
+		t.Errorf("Expected ErrKeyAlreadyUsed from a reused signer, got %v", err)
+	}
+	if _, err := share.Signer().Sign(other); err != primitives.ErrKeyAlreadyUsed {
+		t.Errorf("Expected ErrKeyAlreadyUsed from a fresh signer, got %v", err)
+	}
+	if CreatePartialSignature(share, other) != nil {
+		t.Error("CreatePartialSignature should refuse a used share")
+	}
+	if CreatePartialSignature(share, message) == nil {
+		t.Error("CreatePartialSignature should repeat the signed message")
+	}
+}
+
+func TestGenerateSharesLocksHashFunc(t *testing.T) {
+	if _, _, err := GenerateSharesFromSeed(2, [32]byte{1}); err != nil {
+		t.Fatalf("GenerateSharesFromSeed failed: %v", err)
+	}
+	if err := primitives.SetHashFunc(nil); err != primitives.ErrHashFuncLocked {
+		t.Errorf("Expected ErrHashFuncLocked after generating shares, got %v", err)
+	}
+}
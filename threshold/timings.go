@@ -0,0 +1,51 @@
+package threshold
+
+import "time"
+
+// PhaseTimings records when a Coordinator's round reached each phase.
+// A zero time means the phase has not been reached yet.
+type PhaseTimings struct {
+	// Started is when the coordinator was created
+	Started time.Time
+
+	// CommitmentsDone is when the threshold-th digest commitment arrived
+	CommitmentsDone time.Time
+
+	// PartialsDone is when the threshold-th partial arrived and aggregation began
+	PartialsDone time.Time
+
+	// Completed is when the aggregated signature verified
+	Completed time.Time
+}
+
+// Commitment returns the commitment-phase duration, or 0 if it has not ended.
+func (t PhaseTimings) Commitment() time.Duration {
+	return since(t.Started, t.CommitmentsDone)
+}
+
+// Partial returns the partial-collection duration, or 0 if it has not ended.
+func (t PhaseTimings) Partial() time.Duration {
+	return since(t.CommitmentsDone, t.PartialsDone)
+}
+
+// Aggregation returns the aggregation-and-verification duration, or 0 if the
+// round has not completed.
+func (t PhaseTimings) Aggregation() time.Duration {
+	return since(t.PartialsDone, t.Completed)
+}
+
+// since returns end - start, or 0 if either is unset.
+func since(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// Timings returns the phase timestamps recorded so far for this round.
+func (c *Coordinator) Timings() PhaseTimings {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.timings
+}
@@ -0,0 +1,99 @@
+// Package wire provides length-prefixed framing for streaming Lamport
+// objects over sockets and other byte streams.
+//
+// Each frame is a 4-byte big-endian length followed by that many bytes.
+// Typed helpers frame the existing Bytes() layouts of the primitives types.
+package wire
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/luxfi/lamport/primitives"
+)
+
+// DefaultMaxFrameSize bounds frames read by ReadFramed (1 MiB), comfortably
+// above the largest Lamport object (a 16 KB public key or private key).
+const DefaultMaxFrameSize = 1 << 20
+
+// ErrFrameTooLarge indicates a frame length prefix exceeds the allowed maximum
+var ErrFrameTooLarge = errors.New("wire: frame too large")
+
+// WriteFramed writes b to w prefixed with its 4-byte big-endian length.
+func WriteFramed(w io.Writer, b []byte) error {
+	if uint64(len(b)) > 0xFFFFFFFF {
+		return ErrFrameTooLarge
+	}
+	var prefix [4]byte
+	binary.BigEndian.PutUint32(prefix[:], uint32(len(b)))
+	if _, err := w.Write(prefix[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// ReadFramed reads one frame of at most DefaultMaxFrameSize bytes.
+func ReadFramed(r io.Reader) ([]byte, error) {
+	return ReadFramedMax(r, DefaultMaxFrameSize)
+}
+
+// ReadFramedMax reads one frame, rejecting length prefixes above max before
+// allocating. A clean end of stream before the prefix returns io.EOF; a
+// stream that ends mid-frame returns io.ErrUnexpectedEOF.
+func ReadFramedMax(r io.Reader, max int) ([]byte, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(prefix[:])
+	if uint64(n) > uint64(max) {
+		return nil, ErrFrameTooLarge
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+// WriteSignature writes a framed signature.
+func WriteSignature(w io.Writer, sig *primitives.Signature) error {
+	return WriteFramed(w, sig.Bytes())
+}
+
+// ReadSignature reads a framed signature.
+func ReadSignature(r io.Reader) (*primitives.Signature, error) {
+	b, err := ReadFramedMax(r, primitives.SignatureSize)
+	if err != nil {
+		return nil, err
+	}
+	sig := &primitives.Signature{}
+	if err := sig.FromBytes(b); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// WritePublicKey writes a framed public key.
+func WritePublicKey(w io.Writer, pub *primitives.PublicKey) error {
+	return WriteFramed(w, pub.Bytes())
+}
+
+// ReadPublicKey reads a framed public key.
+func ReadPublicKey(r io.Reader) (*primitives.PublicKey, error) {
+	b, err := ReadFramedMax(r, primitives.PublicKeySize)
+	if err != nil {
+		return nil, err
+	}
+	pub := &primitives.PublicKey{}
+	if err := pub.FromBytes(b); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
@@ -0,0 +1,74 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/luxfi/lamport/primitives"
+)
+
+func TestStreamSignatures(t *testing.T) {
+	const count = 5
+	kp, err := primitives.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+
+	var messages [count][32]byte
+	var sigs [count]*primitives.Signature
+	for i := range sigs {
+		// Same key reused only to produce distinct test data
+		kp.Private.Used = false
+		messages[i] = primitives.Keccak256([]byte{byte(i)})
+		sigs[i], _ = primitives.Sign(kp.Private, messages[i])
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		if err := WritePublicKey(w, kp.Public); err != nil {
+			w.CloseWithError(err)
+			return
+		}
+		for _, sig := range sigs {
+			if err := WriteSignature(w, sig); err != nil {
+				w.CloseWithError(err)
+				return
+			}
+		}
+		w.Close()
+	}()
+
+	pub, err := ReadPublicKey(r)
+	if err != nil {
+		t.Fatalf("ReadPublicKey failed: %v", err)
+	}
+	for i := 0; i < count; i++ {
+		sig, err := ReadSignature(r)
+		if err != nil {
+			t.Fatalf("ReadSignature %d failed: %v", i, err)
+		}
+		if !primitives.Verify(pub, messages[i], sig) {
+			t.Errorf("Streamed signature %d should verify", i)
+		}
+	}
+	if _, err := ReadFramed(r); err != io.EOF {
+		t.Errorf("Expected io.EOF at end of stream, got %v", err)
+	}
+}
+
+func TestReadFramedLimits(t *testing.T) {
+	// Absurd length prefix is rejected before allocation
+	if _, err := ReadFramed(bytes.NewReader([]byte{0xFF, 0xFF, 0xFF, 0xFF})); err != ErrFrameTooLarge {
+		t.Errorf("Expected ErrFrameTooLarge, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	_ = WriteFramed(&buf, make([]byte, 100))
+	if _, err := ReadFramedMax(bytes.NewReader(buf.Bytes()), 99); err != ErrFrameTooLarge {
+		t.Errorf("Expected ErrFrameTooLarge for configured max, got %v", err)
+	}
+	if _, err := ReadFramed(bytes.NewReader(buf.Bytes()[:50])); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected io.ErrUnexpectedEOF for truncated frame, got %v", err)
+	}
+}